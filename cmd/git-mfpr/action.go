@@ -0,0 +1,165 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/user/git-mfpr/internal/migrate"
+	"github.com/user/git-mfpr/internal/report"
+	"github.com/user/git-mfpr/internal/ui"
+)
+
+func newActionCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "action",
+		Short: "Run as a GitHub Action, reading inputs from the environment",
+		Long: `Run a non-interactive migration configured by the GitHub Action inputs
+(INPUT_* environment variables), then write the results to $GITHUB_OUTPUT
+and a Markdown summary to $GITHUB_STEP_SUMMARY. This is the entrypoint of the
+Docker action in .github/action.yml.`,
+		Args: cobra.NoArgs,
+		Run:  runAction,
+	}
+}
+
+func runAction(cmd *cobra.Command, _ []string) {
+	refs, opts, err := actionInputs(os.Getenv)
+	if err != nil {
+		ui.New().Error(err)
+		os.Exit(exitCode(err))
+	}
+	// gh reads its token from GH_TOKEN; the workflow passes it as an input.
+	if token := os.Getenv("INPUT_GITHUB-TOKEN"); token != "" && os.Getenv("GH_TOKEN") == "" {
+		os.Setenv("GH_TOKEN", token)
+	}
+
+	// Nobody is there to answer a prompt, so every confirmation is a yes.
+	out := ui.NewWithOptions(opts.DryRun, ui.WithAssumeYes(true), ui.WithVerbose(verbose || trace))
+
+	migrator, err := newMigrator(cmd)
+	if err != nil {
+		out.Error(err)
+		os.Exit(exitCode(err))
+	}
+
+	batch, err := migrateAll(cmd.Context(), refs, out, migrator, opts)
+	if batch != nil {
+		if werr := writeActionResults(os.Getenv("GITHUB_OUTPUT"), os.Getenv("GITHUB_STEP_SUMMARY"), batch); werr != nil {
+			out.Error(werr)
+			if err == nil {
+				err = werr
+			}
+		}
+	}
+	if err != nil {
+		os.Exit(exitCode(err))
+	}
+}
+
+// actionInputs reads the PRs and options from the action's inputs, which
+// GitHub passes as INPUT_<NAME> with the name upper-cased.
+func actionInputs(getenv func(string) string) ([]string, migrate.Options, error) {
+	input := func(name string) string {
+		return strings.TrimSpace(getenv("INPUT_" + strings.ToUpper(name)))
+	}
+	var opts migrate.Options
+	var err error
+	boolInput := func(name string) bool {
+		value := input(name)
+		if value == "" || err != nil {
+			return false
+		}
+		b, perr := strconv.ParseBool(value)
+		if perr != nil {
+			err = usageErrorf("invalid %s input %q: want true or false", name, value)
+		}
+		return b
+	}
+
+	opts.DryRun = boolInput("dry-run")
+	opts.NoPush = boolInput("no-push")
+	opts.NoCreate = boolInput("no-create")
+	opts.CloseOriginal = boolInput("close-original")
+	opts.WaitChecks = boolInput("wait-checks")
+	opts.BranchName = input("branch-name")
+	opts.Target = input("target")
+	opts.ChecksTimeout = migrate.DefaultChecksTimeout
+	if err != nil {
+		return nil, opts, err
+	}
+
+	refs, err := migrate.ExpandPRRefs(strings.Fields(input("pr-ref")))
+	if err != nil {
+		return nil, opts, err
+	}
+	if len(refs) == 0 {
+		return nil, opts, usageErrorf("the pr-ref input is required")
+	}
+	if opts.BranchName != "" && len(refs) > 1 {
+		return nil, opts, usageErrorf("branch-name can only be used with a single PR")
+	}
+	return refs, opts, nil
+}
+
+// writeActionResults appends the step outputs to outputPath and the
+// Markdown report to summaryPath. Either path may be empty when run outside
+// GitHub Actions.
+func writeActionResults(outputPath, summaryPath string, batch *migrate.BatchResult) error {
+	if outputPath != "" {
+		if err := appendFile(outputPath, func(w io.Writer) error {
+			return writeActionOutputs(w, batch)
+		}); err != nil {
+			return fmt.Errorf("writing step outputs: %w", err)
+		}
+	}
+	if summaryPath != "" {
+		if err := appendFile(summaryPath, func(w io.Writer) error {
+			return report.WriteFormat(w, report.Markdown, batch.Results)
+		}); err != nil {
+			return fmt.Errorf("writing step summary: %w", err)
+		}
+	}
+	return nil
+}
+
+// writeActionOutputs writes the step outputs in the name=value format of
+// $GITHUB_OUTPUT. Every value fits on one line.
+func writeActionOutputs(w io.Writer, batch *migrate.BatchResult) error {
+	results, err := json.Marshal(batch)
+	if err != nil {
+		return err
+	}
+
+	var branches, newPRs []string
+	for _, r := range batch.Results {
+		if r.Err == nil && r.Branch != "" {
+			branches = append(branches, r.Branch)
+		}
+		if r.Created != nil {
+			newPRs = append(newPRs, r.Created.URL)
+		}
+	}
+	failed := len(batch.Failed())
+
+	_, err = fmt.Fprintf(w, "results=%s\nmigrated=%d\nfailed=%d\nbranches=%s\nnew-prs=%s\n",
+		results, len(batch.Results)-failed, failed, strings.Join(branches, " "), strings.Join(newPRs, " "))
+	return err
+}
+
+func appendFile(path string, write func(io.Writer) error) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600) // #nosec G304
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
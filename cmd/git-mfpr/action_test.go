@@ -0,0 +1,123 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/user/git-mfpr/internal/migrate"
+)
+
+func TestActionInputs(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		wantRefs []string
+		wantOpts migrate.Options
+		wantErr  bool
+	}{
+		{
+			name:     "single PR",
+			env:      map[string]string{"INPUT_PR-REF": "123", "INPUT_DRY-RUN": "false"},
+			wantRefs: []string{"123"},
+			wantOpts: migrate.Options{ChecksTimeout: migrate.DefaultChecksTimeout},
+		},
+		{
+			name: "every input",
+			env: map[string]string{
+				"INPUT_PR-REF":         " owner/repo#7 ",
+				"INPUT_DRY-RUN":        "true",
+				"INPUT_NO-PUSH":        "true",
+				"INPUT_NO-CREATE":      "TRUE",
+				"INPUT_CLOSE-ORIGINAL": "1",
+				"INPUT_WAIT-CHECKS":    "true",
+				"INPUT_BRANCH-NAME":    "fix/typo",
+				"INPUT_TARGET":         "org/new",
+			},
+			wantRefs: []string{"owner/repo#7"},
+			wantOpts: migrate.Options{
+				DryRun: true, NoPush: true, NoCreate: true, CloseOriginal: true, WaitChecks: true,
+				BranchName: "fix/typo", Target: "org/new", ChecksTimeout: migrate.DefaultChecksTimeout,
+			},
+		},
+		{
+			name:     "ranges and lists",
+			env:      map[string]string{"INPUT_PR-REF": "1-3,7\n9"},
+			wantRefs: []string{"1", "2", "3", "7", "9"},
+			wantOpts: migrate.Options{ChecksTimeout: migrate.DefaultChecksTimeout},
+		},
+		{name: "no PR", env: map[string]string{}, wantErr: true},
+		{name: "bad boolean", env: map[string]string{"INPUT_PR-REF": "1", "INPUT_DRY-RUN": "yes please"}, wantErr: true},
+		{name: "branch name for several PRs", env: map[string]string{"INPUT_PR-REF": "1 2", "INPUT_BRANCH-NAME": "b"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			refs, opts, err := actionInputs(func(key string) string { return tt.env[key] })
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("actionInputs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if !errors.Is(err, migrate.ErrUsage) {
+					t.Errorf("actionInputs() error = %v, want a usage error", err)
+				}
+				return
+			}
+			if !reflect.DeepEqual(refs, tt.wantRefs) {
+				t.Errorf("refs = %v, want %v", refs, tt.wantRefs)
+			}
+			if !reflect.DeepEqual(opts, tt.wantOpts) {
+				t.Errorf("opts = %+v, want %+v", opts, tt.wantOpts)
+			}
+		})
+	}
+}
+
+func TestWriteActionResults(t *testing.T) {
+	dir := t.TempDir()
+	output := filepath.Join(dir, "output")
+	summary := filepath.Join(dir, "summary")
+	if err := os.WriteFile(output, []byte("earlier=step\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	batch := &migrate.BatchResult{Results: []migrate.MigrationResult{
+		{Ref: "7", Branch: "migrated-7", Pushed: true, Created: &migrate.PRInfo{Number: 8, URL: "https://github.com/o/r/pull/8"}},
+		{Ref: "9", Branch: "migrated-9", Pushed: true},
+		{Ref: "10", Err: errors.New("boom")},
+	}}
+	if err := writeActionResults(output, summary, batch); err != nil {
+		t.Fatalf("writeActionResults() error = %v", err)
+	}
+
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) != 6 || lines[0] != "earlier=step" {
+		t.Fatalf("$GITHUB_OUTPUT = %q, want the earlier output and five more lines", data)
+	}
+	if !strings.HasPrefix(lines[1], `results={"results":[{"ref":"7"`) {
+		t.Errorf("results = %q, want the JSON batch", lines[1])
+	}
+	want := []string{"migrated=2", "failed=1", "branches=migrated-7 migrated-9", "new-prs=https://github.com/o/r/pull/8"}
+	if !reflect.DeepEqual(lines[2:], want) {
+		t.Errorf("outputs = %q, want %q", lines[2:], want)
+	}
+
+	data, err = os.ReadFile(summary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "Migrated 2 of 3 PRs.") || !strings.Contains(string(data), "## Failures") {
+		t.Errorf("step summary = %q, want the Markdown report", data)
+	}
+
+	if err := writeActionResults("", "", batch); err != nil {
+		t.Errorf("writeActionResults() outside Actions error = %v", err)
+	}
+}
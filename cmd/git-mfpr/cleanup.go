@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/user/git-mfpr/internal/migrate"
+	"github.com/user/git-mfpr/internal/ui"
+)
+
+var (
+	cleanupDryRun     bool
+	cleanupYes        bool
+	cleanupOlderThan  string
+	cleanupMergedOnly bool
+)
+
+func newCleanupCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cleanup",
+		Short: "Delete migrated branches whose replacement PR was merged or closed",
+		Long: `Find branches created by git-mfpr whose replacement PR has been merged or
+closed, and delete them locally and where they were pushed (origin, or the
+--target repository) along with their migration records. A branch that is
+checked out is left for the replacement PR's base branch first.
+
+Examples:
+  git mfpr cleanup --dry-run                 # Preview what would be deleted
+  git mfpr cleanup --merged-only             # Only branches whose PR was merged
+  git mfpr cleanup --older-than 30d          # Only migrations older than 30 days`,
+		Args: cobra.NoArgs,
+		Run:  runCleanup,
+	}
+
+	cmd.Flags().BoolVar(&cleanupDryRun, "dry-run", false, "Show what would be deleted without deleting")
+	cmd.Flags().StringVar(&cleanupOlderThan, "older-than", "", "Only clean up migrations older than this (e.g. 72h, 30d, 2w)")
+	cmd.Flags().BoolVar(&cleanupMergedOnly, "merged-only", false, "Only clean up branches whose replacement PR was merged")
+	cmd.Flags().BoolVarP(&cleanupYes, "yes", "y", false, "Delete without asking for confirmation")
+
+	return cmd
+}
+
+func runCleanup(cmd *cobra.Command, _ []string) {
+	out := ui.NewWithOptions(cleanupDryRun, ui.WithAssumeYes(cleanupYes), ui.WithVerbose(verbose || trace))
+	if err := requireConfirmable(ui.IsTerminal(os.Stdin), !cleanupDryRun, cleanupYes); err != nil {
+		out.Error(err)
+		os.Exit(exitCode(err))
+	}
+	migrator, err := newMigrator(cmd)
+	if err != nil {
+		out.Error(err)
+		os.Exit(exitCode(err))
+	}
+
+	if err := cleanupBranches(cmd.Context(), out, migrator); err != nil {
+		os.Exit(exitCode(err))
+	}
+}
+
+func cleanupBranches(ctx context.Context, out ui.UI, migrator migrate.Migrator) error {
+	opts := migrate.CleanupOptions{
+		DryRun:     cleanupDryRun,
+		MergedOnly: cleanupMergedOnly,
+	}
+
+	if cleanupOlderThan != "" {
+		age, err := parseAge(cleanupOlderThan)
+		if err != nil {
+			out.Error(err)
+			return err
+		}
+		opts.OlderThan = age
+	}
+
+	migrator.SetEventHandler(func(event migrate.Event) {
+		out.HandleEvent(event)
+	})
+	migrator.SetConfirmHandler(out.Confirm)
+
+	if _, err := migrator.Cleanup(ctx, opts); err != nil {
+		out.Error(err)
+		return err
+	}
+	return nil
+}
+
+// parseAge accepts anything time.ParseDuration does, plus whole days ("30d")
+// and weeks ("2w").
+func parseAge(s string) (time.Duration, error) {
+	units := map[string]time.Duration{
+		"d": 24 * time.Hour,
+		"w": 7 * 24 * time.Hour,
+	}
+	for suffix, unit := range units {
+		if n, ok := strings.CutSuffix(s, suffix); ok {
+			count, err := strconv.Atoi(n)
+			if err != nil || count < 0 {
+				return 0, usageErrorf("invalid --older-than value %q", s)
+			}
+			return time.Duration(count) * unit, nil
+		}
+	}
+
+	age, err := time.ParseDuration(s)
+	if err != nil || age < 0 {
+		return 0, usageErrorf("invalid --older-than value %q", s)
+	}
+	return age, nil
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/user/git-mfpr/internal/migrate"
+)
+
+func TestParseAge(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{input: "30d", want: 30 * 24 * time.Hour},
+		{input: "2w", want: 14 * 24 * time.Hour},
+		{input: "72h", want: 72 * time.Hour},
+		{input: "90m", want: 90 * time.Minute},
+		{input: "xd", wantErr: true},
+		{input: "-1d", wantErr: true},
+		{input: "soon", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseAge(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseAge(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseAge(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCleanupBranches(t *testing.T) {
+	origDryRun := cleanupDryRun
+	origOlderThan := cleanupOlderThan
+	origMergedOnly := cleanupMergedOnly
+	defer func() {
+		cleanupDryRun = origDryRun
+		cleanupOlderThan = origOlderThan
+		cleanupMergedOnly = origMergedOnly
+	}()
+
+	tests := []struct {
+		name        string
+		dryRun      bool
+		olderThan   string
+		mergedOnly  bool
+		cleanupErr  error
+		expectError bool
+		wantOpts    migrate.CleanupOptions
+	}{
+		{
+			name:     "defaults",
+			wantOpts: migrate.CleanupOptions{},
+		},
+		{
+			name:       "all filters",
+			dryRun:     true,
+			olderThan:  "7d",
+			mergedOnly: true,
+			wantOpts:   migrate.CleanupOptions{DryRun: true, OlderThan: 7 * 24 * time.Hour, MergedOnly: true},
+		},
+		{
+			name:        "invalid age",
+			olderThan:   "yesterday",
+			expectError: true,
+		},
+		{
+			name:        "cleanup failure",
+			cleanupErr:  errors.New("push rejected"),
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cleanupDryRun = tt.dryRun
+			cleanupOlderThan = tt.olderThan
+			cleanupMergedOnly = tt.mergedOnly
+
+			called := false
+			mockUI := &mockUI{}
+			mockMigrator := &mockMigrator{
+				cleanupFunc: func(_ context.Context, opts migrate.CleanupOptions) ([]migrate.MigrationStatus, error) {
+					called = true
+					if opts != tt.wantOpts && tt.cleanupErr == nil {
+						t.Errorf("Cleanup() opts = %+v, want %+v", opts, tt.wantOpts)
+					}
+					return nil, tt.cleanupErr
+				},
+			}
+
+			err := cleanupBranches(context.Background(), mockUI, mockMigrator)
+			if (err != nil) != tt.expectError {
+				t.Errorf("cleanupBranches() error = %v, expectError %v", err, tt.expectError)
+			}
+			if tt.olderThan == "yesterday" && called {
+				t.Error("Cleanup() should not run with an invalid --older-than")
+			}
+			if tt.expectError && len(mockUI.errors) != 1 {
+				t.Errorf("Expected 1 error reported to UI, got %d", len(mockUI.errors))
+			}
+		})
+	}
+}
+
+func TestCleanupCmd_OwnFlags(t *testing.T) {
+	origDryRun, origYes, origCleanupDryRun, origCleanupYes := dryRun, assumeYes, cleanupDryRun, cleanupYes
+	defer func() {
+		dryRun, assumeYes, cleanupDryRun, cleanupYes = origDryRun, origYes, origCleanupDryRun, origCleanupYes
+	}()
+	dryRun, assumeYes = false, false
+
+	cmd := newCleanupCmd()
+	if err := cmd.ParseFlags([]string{"--dry-run", "--yes"}); err != nil {
+		t.Fatalf("ParseFlags() error = %v", err)
+	}
+	if !cleanupDryRun || !cleanupYes {
+		t.Errorf("cleanup --dry-run --yes = %v, %v; want both set", cleanupDryRun, cleanupYes)
+	}
+	if dryRun || assumeYes {
+		t.Error("cleanup flags changed the root command's --dry-run or --yes")
+	}
+}
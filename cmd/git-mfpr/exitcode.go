@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/user/git-mfpr/internal/migrate"
+)
+
+// Exit codes, documented in the README. Scripts rely on them; don't
+// renumber.
+const (
+	exitError        = 1
+	exitUsage        = 2
+	exitNotFound     = 3
+	exitPrecondition = 4
+	exitGit          = 5
+	exitGitHub       = 6
+	exitPartial      = 7
+	exitCancelled    = 130
+)
+
+// usageError is a mistake in how git-mfpr was invoked, such as conflicting
+// flags.
+type usageError struct {
+	err error
+}
+
+func (e *usageError) Error() string        { return e.err.Error() }
+func (e *usageError) Unwrap() error        { return e.err }
+func (e *usageError) Is(target error) bool { return target == migrate.ErrUsage }
+
+func usageErrorf(format string, args ...any) error {
+	return &usageError{err: fmt.Errorf(format, args...)}
+}
+
+// partialError is a batch in which some PRs migrated and others failed.
+type partialError struct {
+	err error
+}
+
+func (e *partialError) Error() string { return e.err.Error() }
+func (e *partialError) Unwrap() error { return e.err }
+
+// exitCode picks the exit code for err from its error category. errors.Is
+// looks through the whole chain, so a cause wrapped in a git or GitHub
+// failure, such as the unknown revision behind a failed checkout, decides the
+// code: not found and precondition are checked before git and GitHub. When a
+// batch fails for several reasons, the first category that matches wins.
+func exitCode(err error) int {
+	var partial *partialError
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, context.Canceled):
+		return exitCancelled
+	case errors.As(err, &partial):
+		return exitPartial
+	case errors.Is(err, migrate.ErrUsage):
+		return exitUsage
+	case errors.Is(err, migrate.ErrNotFound):
+		return exitNotFound
+	case errors.Is(err, migrate.ErrPrecondition):
+		return exitPrecondition
+	case errors.Is(err, migrate.ErrGit):
+		return exitGit
+	case errors.Is(err, migrate.ErrGitHub):
+		return exitGitHub
+	default:
+		return exitError
+	}
+}
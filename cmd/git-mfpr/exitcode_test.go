@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/user/git-mfpr/internal/git"
+	"github.com/user/git-mfpr/internal/github"
+	"github.com/user/git-mfpr/internal/migrate"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "success", want: 0},
+		{name: "plain error", err: errors.New("boom"), want: exitError},
+		{name: "hook failed", err: &migrate.ErrHookFailed{Stage: migrate.StagePrePush}, want: exitPrecondition},
+		{name: "checks failed", err: &migrate.ErrChecksFailed{Branch: "b", Failed: []string{"ci"}}, want: exitPrecondition},
+		{name: "checks timed out", err: &migrate.ErrChecksTimedOut{Branch: "b"}, want: exitPrecondition},
+		{name: "invalid path map", err: fmt.Errorf("--path-map: %w", &migrate.ErrInvalidPathMap{Line: 1}), want: exitUsage},
+		{name: "invalid migration record", err: &migrate.ErrInvalidProvenance{Ref: "refs/mfpr/o/r/1"}, want: exitGit},
+		{name: "usage", err: usageErrorf("--branch-name can only be used with a single PR"), want: exitUsage},
+		{name: "invalid PR ref", err: &migrate.ErrInvalidPRRef{Ref: "abc"}, want: exitUsage},
+		{name: "PR not found", err: &github.ErrPRNotFound{Number: 1}, want: exitNotFound},
+		{name: "not a fork", err: &migrate.ErrPRNotFork{Number: 1}, want: exitPrecondition},
+		{name: "closed", err: &migrate.ErrPRClosed{Number: 1, State: "closed"}, want: exitPrecondition},
+		{name: "branch exists", err: &migrate.ErrBranchExists{BranchName: "b"}, want: exitPrecondition},
+		{name: "local changes", err: &git.ErrLocalChanges{}, want: exitPrecondition},
+		{name: "push rejected", err: &git.ErrPushFailed{Branch: "b", Err: &git.ErrProtectedBranch{Branch: "b"}}, want: exitGit},
+		{
+			name: "checkout of an unknown revision",
+			err: &git.ErrCheckoutFailed{Branch: "b", Err: &git.ErrCommand{
+				Args: []string{"checkout", "b"}, Err: &git.ErrUnknownRevision{Revision: "b"},
+			}},
+			want: exitNotFound,
+		},
+		{name: "git command", err: fmt.Errorf("rollback: %w", &git.ErrCommand{}), want: exitGit},
+		{name: "create PR failed", err: &github.ErrPRCreateFailed{}, want: exitGitHub},
+		{name: "API request failed", err: &github.ErrAPIRequestFailed{Path: "/x"}, want: exitGitHub},
+		{name: "interrupted", err: fmt.Errorf("PR 1: %w", context.Canceled), want: exitCancelled},
+		{name: "partial batch", err: &partialError{err: errors.Join(fmt.Errorf("PR 2: %w", &migrate.ErrPRNotFork{Number: 2}))}, want: exitPartial},
+		{
+			name: "interrupted partial batch",
+			err:  &partialError{err: errors.Join(fmt.Errorf("PR 2: %w", context.Canceled))},
+			want: exitCancelled,
+		},
+		{
+			name: "whole batch failed",
+			err: errors.Join(
+				fmt.Errorf("PR 1: %w", &git.ErrCheckoutFailed{Branch: "b"}),
+				fmt.Errorf("PR 2: %w", &github.ErrPRNotFound{Number: 2}),
+			),
+			want: exitNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exitCode(tt.err); got != tt.want {
+				t.Errorf("exitCode(%v) = %d, want %d", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMigrateAll_ExitCode(t *testing.T) {
+	tests := []struct {
+		name   string
+		failed map[string]error
+		want   int
+	}{
+		{name: "all migrated", want: 0},
+		{name: "some failed", failed: map[string]error{"2": &migrate.ErrPRClosed{Number: 2}}, want: exitPartial},
+		{
+			name:   "all failed",
+			failed: map[string]error{"1": &migrate.ErrPRClosed{Number: 1}, "2": &migrate.ErrPRClosed{Number: 2}},
+			want:   exitPrecondition,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockMigrator := &mockMigrator{
+				migratePRFunc: func(_ context.Context, prRef string, _ migrate.Options) error {
+					return tt.failed[prRef]
+				},
+			}
+
+			_, err := migrateAll(context.Background(), []string{"1", "2"}, &mockUI{}, mockMigrator, migrate.Options{})
+			if got := exitCode(err); got != tt.want {
+				t.Errorf("exitCode(%v) = %d, want %d", err, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/user/git-mfpr/internal/migrate"
+	"github.com/user/git-mfpr/internal/ui"
+)
+
+func newListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List PRs migrated in this repository",
+		Long: `List the migration records written to refs/mfpr/ by previous runs,
+showing which fork PR each migrated branch came from.`,
+		Args: cobra.NoArgs,
+		Run:  runList,
+	}
+}
+
+func runList(cmd *cobra.Command, _ []string) {
+	out := ui.NewWithOptions(false, ui.WithVerbose(verbose || trace))
+	migrator, err := newMigrator(cmd)
+	if err != nil {
+		out.Error(err)
+		os.Exit(exitCode(err))
+	}
+
+	if err := listMigrations(cmd.Context(), out, migrator); err != nil {
+		os.Exit(exitCode(err))
+	}
+}
+
+func listMigrations(ctx context.Context, out ui.UI, migrator migrate.Migrator) error {
+	migrator.SetEventHandler(out.HandleEvent)
+
+	records, err := migrator.ListMigrations(ctx)
+	if err != nil {
+		out.Error(err)
+		return err
+	}
+
+	if len(records) == 0 {
+		out.Info("No migrated PRs recorded in this repository")
+		return nil
+	}
+
+	for i := range records {
+		fmt.Println(ui.FormatProvenance(&records[i]))
+	}
+	return nil
+}
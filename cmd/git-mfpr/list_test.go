@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/user/git-mfpr/internal/migrate"
+)
+
+func TestListMigrations(t *testing.T) {
+	tests := []struct {
+		name        string
+		records     []migrate.Provenance
+		listErr     error
+		expectError bool
+	}{
+		{
+			name: "records found",
+			records: []migrate.Provenance{
+				{Owner: "owner", Repo: "repo", Number: 123, Branch: "migrated-123"},
+			},
+		},
+		{
+			name: "no records",
+		},
+		{
+			name:        "read failure",
+			listErr:     errors.New("not a git repository"),
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUI := &mockUI{}
+			mockMigrator := &mockMigrator{
+				listMigrationsFunc: func(_ context.Context) ([]migrate.Provenance, error) {
+					return tt.records, tt.listErr
+				},
+			}
+
+			err := listMigrations(context.Background(), mockUI, mockMigrator)
+			if (err != nil) != tt.expectError {
+				t.Errorf("listMigrations() error = %v, expectError %v", err, tt.expectError)
+			}
+			if tt.expectError && len(mockUI.errors) != 1 {
+				t.Errorf("Expected 1 error reported to UI, got %d", len(mockUI.errors))
+			}
+		})
+	}
+}
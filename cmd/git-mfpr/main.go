@@ -3,11 +3,18 @@ package main
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/user/git-mfpr/internal/git"
 	"github.com/user/git-mfpr/internal/migrate"
+	"github.com/user/git-mfpr/internal/report"
 	"github.com/user/git-mfpr/internal/ui"
 )
 
@@ -18,10 +25,26 @@ var (
 )
 
 var (
-	dryRun     bool
-	noPush     bool
-	noCreate   bool
-	branchName string
+	dryRun      bool
+	noPush      bool
+	noCreate    bool
+	branchName  string
+	interactive bool
+	assumeYes   bool
+	force       bool
+	closeOrig   bool
+	targetRepo  string
+	pathMap     string
+	fromFile    string
+	noHooks     bool
+	waitChecks  bool
+	checksWait  time.Duration
+	reportPath  string
+	hooks       migrate.Hooks
+	timeout     time.Duration
+	verbose     bool
+	trace       bool
+	repoDir     string
 )
 
 func main() {
@@ -34,65 +57,381 @@ preserving commit history and authorship.
 Examples:
   git mfpr 123                      # Migrate PR #123 from current repo
   git mfpr 123 124 125             # Migrate multiple PRs
+  git mfpr 100-110,115             # Ranges and comma-separated lists
+  git mfpr --from-file prs.txt     # Read PR references from a file
+  gh pr list --json url -q '.[].url' | git mfpr --yes -   # ...or from stdin
   git mfpr owner/repo#123          # Migrate from specific repo
-  git mfpr https://github.com/...  # Full URL support`,
-		Args: cobra.MinimumNArgs(1),
-		Run:  run,
+  git mfpr https://github.com/...  # Full URL support
+  git mfpr 123 --target org/new    # Migrate into another repository
+  git mfpr 123 --wait-checks       # Push, then wait for CI to pass
+  git mfpr 100-120 --report out.md # Write a Markdown report of the run
+  git mfpr -i                      # Pick open fork PRs by number from a list
+  git mfpr -C ../other-clone 123   # Migrate in another local clone
+  git mfpr list                    # Show previously migrated PRs
+  git mfpr status                  # Show migrated PRs and their upstream state
+  git mfpr cleanup --dry-run       # Preview deleting merged/closed migrated branches
+  git mfpr retarget --from master --to main   # Move fork PRs off a retired branch
+  git mfpr serve                   # Migrate PRs on request from a webhook`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if interactive || fromFile != "" {
+				return nil
+			}
+			return cobra.MinimumNArgs(1)(cmd, args)
+		},
+		Run: run,
 	}
 
 	rootCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would happen without executing")
 	rootCmd.Flags().BoolVar(&noPush, "no-push", false, "Create branch but don't push")
 	rootCmd.Flags().BoolVar(&noCreate, "no-create", false, "Don't offer to create new PR")
 	rootCmd.Flags().StringVar(&branchName, "branch-name", "", "Custom branch name (for single PR only)")
+	rootCmd.Flags().BoolVarP(&interactive, "interactive", "i", false, "Pick open fork PRs to migrate by typing their numbers in a list")
+	rootCmd.Flags().BoolVarP(&assumeYes, "yes", "y", false, "Answer yes to every confirmation (for unattended runs)")
+	rootCmd.Flags().BoolVar(&force, "force", false, "Overwrite the branch if it already exists")
+	rootCmd.Flags().BoolVar(&closeOrig, "close-original", false, "Close the original PR after creating its replacement")
+	rootCmd.Flags().StringVar(&fromFile, "from-file", "", "Read PR references from this file, one or more per line")
+	rootCmd.Flags().StringVar(&pathMap, "path-map", "", "Rewrite paths in the PR's commits using OLD:NEW lines from this file")
+	rootCmd.Flags().BoolVar(&noHooks, "no-hooks", false, "Don't run the hooks set in git config mfpr.hook.<stage>")
+	rootCmd.Flags().BoolVar(&waitChecks, "wait-checks", false, "Wait for CI on the pushed branch and fail if a check fails")
+	rootCmd.Flags().DurationVar(&checksWait, "checks-timeout", migrate.DefaultChecksTimeout, "How long --wait-checks waits for the checks to finish")
+	rootCmd.Flags().StringVar(&reportPath, "report", "", "Write a report of the run to this .md, .csv or .html file")
+	rootCmd.Flags().StringVar(&targetRepo, "target", "", "Migrate into this owner/repo instead of the PR's own repository")
+
+	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Show every git and gh command run, with exit code and timing")
+	rootCmd.PersistentFlags().BoolVar(&trace, "trace", false, "Like --verbose, and also show everything each command printed")
+	rootCmd.PersistentFlags().StringVarP(&repoDir, "directory", "C", "", "Run in the repository at this path instead of the current directory")
+	rootCmd.PersistentFlags().DurationVar(&timeout, "timeout", 0, "Timeout for each git and gh command (default 5m, or git config mfpr.timeout; 0 disables)")
+
+	rootCmd.AddCommand(newListCmd())
+	rootCmd.AddCommand(newStatusCmd())
+	rootCmd.AddCommand(newCleanupCmd())
+	rootCmd.AddCommand(newRetargetCmd())
+	rootCmd.AddCommand(newServeCmd())
+	rootCmd.AddCommand(newActionCmd())
 
 	rootCmd.Version = fmt.Sprintf("%s (commit: %s, built: %s)", version, commit, date)
 
-	if err := rootCmd.Execute(); err != nil {
-		os.Exit(1)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		// After the first signal, let a second one kill the process as usual.
+		<-ctx.Done()
+		stop()
+	}()
+
+	err := rootCmd.ExecuteContext(ctx)
+	stop()
+	if err != nil {
+		// Commands exit themselves, so cobra only fails on bad arguments.
+		os.Exit(exitUsage)
+	}
+}
+
+func run(cmd *cobra.Command, args []string) {
+	args, err := collectPRRefs(args, fromFile, os.Stdin)
+	if err == nil {
+		err = requireConfirmable(ui.IsTerminal(os.Stdin), migrationPrompts(), assumeYes)
+	}
+	if err != nil {
+		ui.New().Error(err)
+		os.Exit(exitCode(err))
+	}
+
+	migrator, err := newMigrator(cmd)
+	if err != nil {
+		ui.New().Error(err)
+		os.Exit(exitCode(err))
+	}
+	if !noHooks {
+		if hooks, err = loadHooks(cmd.Context(), git.NewWithOptions(git.WithDir(repoDir))); err != nil {
+			ui.New().Error(err)
+			os.Exit(exitCode(err))
+		}
+	}
+
+	if interactive {
+		picker := ui.NewInteractive(os.Stdin, os.Stdout, dryRun)
+		picker.SetAssumeYes(assumeYes)
+		picker.SetVerbose(verbose || trace)
+		if err := runInteractive(cmd.Context(), args, picker, migrator); err != nil {
+			os.Exit(exitCode(err))
+		}
+		return
+	}
+
+	uiInstance := ui.NewWithOptions(dryRun, ui.WithAssumeYes(assumeYes), ui.WithVerbose(verbose || trace))
+	if err := runMigration(cmd.Context(), args, uiInstance, migrator); err != nil {
+		os.Exit(exitCode(err))
+	}
+}
+
+// requireConfirmable refuses to run without a terminal to answer
+// confirmations on, rather than quietly declining them all, unless --yes
+// answers them or the run would ask none. Runs that ask nothing, such as dry
+// runs, go ahead.
+func requireConfirmable(terminal, prompts, assumeYes bool) error {
+	if terminal || !prompts || assumeYes {
+		return nil
+	}
+	return usageErrorf("stdin is not a terminal, so confirmations cannot be answered; pass --yes to proceed unattended or --dry-run to preview")
+}
+
+// migrationPrompts reports whether migrating with the current flags can ask
+// for confirmation: before pushing, before creating (and closing) PRs, and
+// before overwriting a branch with --force.
+func migrationPrompts() bool {
+	return !dryRun && (!noPush || !noCreate || force)
+}
+
+// collectPRRefs gathers PR references from args, the --from-file file and,
+// for a "-" argument, stdin, and expands their ranges and lists.
+func collectPRRefs(args []string, file string, stdin io.Reader) ([]string, error) {
+	var refs []string
+	for _, arg := range args {
+		if arg != "-" {
+			refs = append(refs, arg)
+			continue
+		}
+		if interactive {
+			return nil, usageErrorf("cannot read PR references from stdin with --interactive")
+		}
+		read, err := migrate.ReadPRRefs(stdin)
+		if err != nil {
+			return nil, fmt.Errorf("reading PR references from stdin: %w", err)
+		}
+		refs = append(refs, read...)
+	}
+
+	if file != "" {
+		f, err := os.Open(file) // #nosec G304
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		read, err := migrate.ReadPRRefs(f)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", file, err)
+		}
+		refs = append(refs, read...)
+	}
+
+	refs, err := migrate.ExpandPRRefs(refs)
+	if err != nil {
+		return nil, err
+	}
+	if len(refs) == 0 && !interactive {
+		return nil, usageErrorf("no PR references given")
 	}
+	return refs, nil
 }
 
-func run(_ *cobra.Command, args []string) {
-	uiInstance := ui.NewWithOptions(dryRun)
-	migrator := migrate.New()
+// newMigrator builds a migrator for the repository selected with -C, using
+// the per-command timeout from --timeout or git config.
+func newMigrator(cmd *cobra.Command) (migrate.Migrator, error) {
+	repo := git.NewWithOptions(git.WithDir(repoDir))
+	if err := checkRepoDir(cmd.Context(), repoDir, repo); err != nil {
+		return nil, err
+	}
+
+	configured, err := repo.Config(cmd.Context(), "mfpr.timeout")
+	if err != nil {
+		return nil, err
+	}
 
-	if err := runMigration(args, uiInstance, migrator); err != nil {
-		os.Exit(1)
+	d, err := operationTimeout(cmd.Flags().Changed("timeout"), configured)
+	if err != nil {
+		return nil, err
 	}
+	return migrate.NewWithOptions(
+		migrate.WithDir(repoDir),
+		migrate.WithTimeout(d),
+		migrate.WithTrace(traceLevel()),
+	), nil
 }
 
-func runMigration(args []string, ui ui.UI, migrator migrate.Migrator) error {
+// checkRepoDir rejects a -C path that is not a directory inside a git
+// repository. An empty dir means the current directory and is not checked.
+func checkRepoDir(ctx context.Context, dir string, repo git.Git) error {
+	if dir == "" {
+		return nil
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return usageErrorf("cannot use -C %s: not a directory", dir)
+	}
+	if !repo.IsInRepo(ctx) {
+		return usageErrorf("cannot use -C %s: not a git repository", dir)
+	}
+	return nil
+}
+
+// loadHooks reads the hook commands for each stage from git config
+// mfpr.hook.<stage>, which may be set more than once.
+func loadHooks(ctx context.Context, repo git.Git) (migrate.Hooks, error) {
+	configured := migrate.Hooks{}
+	for _, stage := range migrate.Stages {
+		commands, err := repo.ConfigAll(ctx, "mfpr.hook."+string(stage))
+		if err != nil {
+			return nil, err
+		}
+		if len(commands) > 0 {
+			configured[stage] = commands
+		}
+	}
+	return configured, nil
+}
+
+func traceLevel() migrate.TraceLevel {
+	switch {
+	case trace:
+		return migrate.TraceOutput
+	case verbose:
+		return migrate.TraceCommands
+	default:
+		return migrate.TraceOff
+	}
+}
+
+// operationTimeout prefers --timeout, then git config mfpr.timeout, then the
+// default.
+func operationTimeout(flagSet bool, configured string) (time.Duration, error) {
+	if flagSet {
+		return timeout, nil
+	}
+	if configured == "" {
+		return migrate.DefaultTimeout, nil
+	}
+
+	d, err := time.ParseDuration(configured)
+	if err != nil || d < 0 {
+		return 0, usageErrorf("invalid mfpr.timeout %q in git config", configured)
+	}
+	return d, nil
+}
+
+type picker interface {
+	ui.UI
+	Pick(prs []migrate.PRInfo) ([]migrate.PRInfo, error)
+	SetTotal(total int)
+	Finish()
+}
+
+// runInteractive lets the user choose from the open fork PRs, adds any PRs
+// given on the command line, and migrates the lot.
+func runInteractive(ctx context.Context, args []string, p picker, migrator migrate.Migrator) error {
+	migrator.SetEventHandler(p.HandleEvent)
+
+	prs, err := migrator.ListOpenForkPRs(ctx, "")
+	if err != nil {
+		p.Error(err)
+		return err
+	}
+	if len(prs) == 0 && len(args) == 0 {
+		p.Info("No open fork PRs to migrate")
+		return nil
+	}
+
+	selected, err := p.Pick(prs)
+	if err != nil {
+		p.Error(err)
+		return err
+	}
+
+	refs := append([]string{}, args...)
+	for _, pr := range selected {
+		refs = append(refs, strconv.Itoa(pr.Number))
+	}
+	if len(refs) == 0 {
+		p.Info("No PRs selected")
+		return nil
+	}
+
+	p.SetTotal(len(refs))
+	defer p.Finish()
+	return runMigration(ctx, refs, p, migrator)
+}
+
+func runMigration(ctx context.Context, args []string, ui ui.UI, migrator migrate.Migrator) error {
 	opts := migrate.Options{
-		DryRun:     dryRun,
-		NoPush:     noPush,
-		NoCreate:   noCreate,
-		BranchName: branchName,
+		DryRun:        dryRun,
+		NoPush:        noPush,
+		NoCreate:      noCreate,
+		BranchName:    branchName,
+		Force:         force,
+		CloseOriginal: closeOrig,
+		Target:        targetRepo,
+		Hooks:         hooks,
+		WaitChecks:    waitChecks,
+		ChecksTimeout: checksWait,
 	}
 
 	if branchName != "" && len(args) > 1 {
-		ui.Error(fmt.Errorf("--branch-name can only be used with a single PR"))
-		return fmt.Errorf("--branch-name can only be used with a single PR")
+		err := usageErrorf("--branch-name can only be used with a single PR")
+		ui.Error(err)
+		return err
 	}
 
-	migrator.SetEventHandler(func(event migrate.Event) {
-		ui.HandleEvent(event)
-	})
-
-	failed := false
-	ctx := context.Background()
-	for _, prRef := range args {
-		ui.StartPR(prRef)
+	if reportPath != "" {
+		if _, err := report.FormatFor(reportPath); err != nil {
+			err = usageErrorf("--report: %v", err)
+			ui.Error(err)
+			return err
+		}
+	}
 
-		if err := migrator.MigratePR(ctx, prRef, opts); err != nil {
+	if pathMap != "" {
+		renames, err := migrate.LoadPathMap(pathMap)
+		if err != nil {
 			ui.Error(err)
-			failed = true
-			continue
+			return err
 		}
+		opts.PathRenames = renames
 	}
 
-	if failed {
-		return fmt.Errorf("one or more migrations failed")
+	_, err := migrateAll(ctx, args, ui, migrator, opts)
+	return err
+}
+
+// migrateAll migrates refs with MigratePRs, showing each PR's progress and
+// failure on ui, and writes the --report file.
+func migrateAll(ctx context.Context, refs []string, ui ui.UI, migrator migrate.Migrator, opts migrate.Options) (*migrate.BatchResult, error) {
+	migrator.SetEventHandler(func(event migrate.Event) {
+		switch event.Type {
+		case migrate.EventStart:
+			ui.StartPR(event.Message)
+		case migrate.EventResult:
+			if event.Result.Err != nil {
+				ui.Error(event.Result.Err)
+			}
+		}
+		ui.HandleEvent(event)
+	})
+	migrator.SetConfirmHandler(ui.Confirm)
+
+	batch, err := migrator.MigratePRs(ctx, refs, opts)
+	if batch == nil {
+		return nil, err
 	}
+	reportErr := writeReport(ui, batch.Results)
+	if err != nil && len(batch.Failed()) < len(batch.Results) {
+		err = &partialError{err: err}
+	}
+	if err == nil {
+		err = reportErr
+	}
+	return batch, err
+}
 
+// writeReport writes the --report file, if one was asked for. A report that
+// cannot be written is shown as an error, and fails an otherwise successful
+// run.
+func writeReport(ui ui.UI, results []migrate.MigrationResult) error {
+	if reportPath == "" {
+		return nil
+	}
+	if err := report.Write(reportPath, results); err != nil {
+		err = fmt.Errorf("writing report: %w", err)
+		ui.Error(err)
+		return err
+	}
+	ui.Info(fmt.Sprintf("Wrote report for %d PRs to %s", len(results), reportPath))
 	return nil
 }
@@ -3,19 +3,30 @@ package main
 import (
 	"context"
 	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/user/git-mfpr/internal/git"
 	"github.com/user/git-mfpr/internal/migrate"
 )
 
 // Mock migrator for testing
 type mockMigrator struct {
 	migratePRFunc       func(ctx context.Context, prRef string, opts migrate.Options) error
-	migratePRsFunc      func(ctx context.Context, prRefs []string, opts migrate.Options) error
+	migratePRsFunc      func(ctx context.Context, prRefs []string, opts migrate.Options) (*migrate.BatchResult, error)
 	getPRInfoFunc       func(ctx context.Context, prRef string) (*migrate.PRInfo, error)
 	generateBranchFunc  func(pr *migrate.PRInfo) string
+	listForkPRsFunc     func(ctx context.Context, base string) ([]migrate.PRInfo, error)
+	listMigrationsFunc  func(ctx context.Context) ([]migrate.Provenance, error)
+	statusFunc          func(ctx context.Context) ([]migrate.MigrationStatus, error)
+	cleanupFunc         func(ctx context.Context, opts migrate.CleanupOptions) ([]migrate.MigrationStatus, error)
 	eventHandler        migrate.EventHandler
+	confirmHandler      migrate.ConfirmHandler
 	setEventHandlerFunc func(handler migrate.EventHandler)
 }
 
@@ -26,11 +37,27 @@ func (m *mockMigrator) MigratePR(ctx context.Context, prRef string, opts migrate
 	return nil
 }
 
-func (m *mockMigrator) MigratePRs(ctx context.Context, prRefs []string, opts migrate.Options) error {
+// MigratePRs runs MigratePR for each ref and reports it the way the real
+// client does, unless migratePRsFunc replaces it.
+func (m *mockMigrator) MigratePRs(ctx context.Context, prRefs []string, opts migrate.Options) (*migrate.BatchResult, error) {
 	if m.migratePRsFunc != nil {
 		return m.migratePRsFunc(ctx, prRefs, opts)
 	}
-	return nil
+
+	batch := &migrate.BatchResult{}
+	for i, prRef := range prRefs {
+		if ctx.Err() != nil {
+			for _, skipped := range prRefs[i:] {
+				batch.Results = append(batch.Results, migrate.MigrationResult{Ref: skipped, Err: ctx.Err()})
+			}
+			break
+		}
+		m.eventHandler(migrate.Event{Type: migrate.EventStart, Message: prRef})
+		result := migrate.MigrationResult{Ref: prRef, Pushed: true, Err: m.MigratePR(ctx, prRef, opts)}
+		m.eventHandler(migrate.Event{Type: migrate.EventResult, Message: prRef, Result: &result})
+		batch.Results = append(batch.Results, result)
+	}
+	return batch, batch.Err()
 }
 
 func (m *mockMigrator) GetPRInfo(ctx context.Context, prRef string) (*migrate.PRInfo, error) {
@@ -40,6 +67,13 @@ func (m *mockMigrator) GetPRInfo(ctx context.Context, prRef string) (*migrate.PR
 	return &migrate.PRInfo{Number: 123}, nil
 }
 
+func (m *mockMigrator) ListOpenForkPRs(ctx context.Context, base string) ([]migrate.PRInfo, error) {
+	if m.listForkPRsFunc != nil {
+		return m.listForkPRsFunc(ctx, base)
+	}
+	return nil, nil
+}
+
 func (m *mockMigrator) GenerateBranchName(pr *migrate.PRInfo) string {
 	if m.generateBranchFunc != nil {
 		return m.generateBranchFunc(pr)
@@ -47,6 +81,27 @@ func (m *mockMigrator) GenerateBranchName(pr *migrate.PRInfo) string {
 	return "migrated-123"
 }
 
+func (m *mockMigrator) ListMigrations(ctx context.Context) ([]migrate.Provenance, error) {
+	if m.listMigrationsFunc != nil {
+		return m.listMigrationsFunc(ctx)
+	}
+	return nil, nil
+}
+
+func (m *mockMigrator) Status(ctx context.Context) ([]migrate.MigrationStatus, error) {
+	if m.statusFunc != nil {
+		return m.statusFunc(ctx)
+	}
+	return nil, nil
+}
+
+func (m *mockMigrator) Cleanup(ctx context.Context, opts migrate.CleanupOptions) ([]migrate.MigrationStatus, error) {
+	if m.cleanupFunc != nil {
+		return m.cleanupFunc(ctx, opts)
+	}
+	return nil, nil
+}
+
 func (m *mockMigrator) SetEventHandler(handler migrate.EventHandler) {
 	m.eventHandler = handler
 	if m.setEventHandlerFunc != nil {
@@ -54,11 +109,18 @@ func (m *mockMigrator) SetEventHandler(handler migrate.EventHandler) {
 	}
 }
 
+func (m *mockMigrator) SetConfirmHandler(handler migrate.ConfirmHandler) {
+	m.confirmHandler = handler
+}
+
 // Mock UI for testing
 type mockUI struct {
 	startPRCalls []string
 	errors       []error
 	events       []migrate.Event
+	prompts      []string
+	infos        []string
+	decline      bool
 }
 
 func (m *mockUI) StartPR(prRef string) {
@@ -70,9 +132,14 @@ func (m *mockUI) Error(err error) {
 }
 
 func (m *mockUI) Success(_ string) {}
-func (m *mockUI) Info(_ string)    {}
+func (m *mockUI) Info(msg string)  { m.infos = append(m.infos, msg) }
 func (m *mockUI) Command(_ string) {}
 
+func (m *mockUI) Confirm(prompt string) bool {
+	m.prompts = append(m.prompts, prompt)
+	return !m.decline
+}
+
 func (m *mockUI) HandleEvent(event migrate.Event) {
 	m.events = append(m.events, event)
 }
@@ -151,7 +218,7 @@ func TestRunMigration(t *testing.T) {
 				return errors.New("migration failed")
 			},
 			expectError:  true,
-			expectErrMsg: "one or more migrations failed",
+			expectErrMsg: "PR 123: migration failed",
 		},
 		{
 			name:       "dry run mode",
@@ -200,7 +267,7 @@ func TestRunMigration(t *testing.T) {
 				return nil
 			},
 			expectError:  true,
-			expectErrMsg: "one or more migrations failed",
+			expectErrMsg: "PR 124: PR 124 failed",
 		},
 	}
 
@@ -219,7 +286,7 @@ func TestRunMigration(t *testing.T) {
 			}
 
 			// Run migration
-			err := runMigration(tt.args, mockUI, mockMigrator)
+			err := runMigration(context.Background(), tt.args, mockUI, mockMigrator)
 
 			// Check error expectations
 			if tt.expectError {
@@ -261,6 +328,110 @@ func TestRunMigration(t *testing.T) {
 	}
 }
 
+func TestRunMigration_Confirmations(t *testing.T) {
+	origForce, origCloseOrig := force, closeOrig
+	defer func() {
+		force, closeOrig = origForce, origCloseOrig
+	}()
+	force, closeOrig = true, true
+
+	mockUI := &mockUI{decline: true}
+	mockMigrator := &mockMigrator{}
+	mockMigrator.migratePRFunc = func(_ context.Context, _ string, opts migrate.Options) error {
+		if !opts.Force || !opts.CloseOriginal {
+			t.Errorf("opts = %+v, want Force and CloseOriginal set", opts)
+		}
+		if mockMigrator.confirmHandler("Push migrated-123 to origin?") {
+			t.Error("confirm handler answered yes after the UI declined")
+		}
+		return nil
+	}
+
+	if err := runMigration(context.Background(), []string{"123"}, mockUI, mockMigrator); err != nil {
+		t.Fatalf("runMigration() error = %v", err)
+	}
+	if len(mockUI.prompts) != 1 {
+		t.Errorf("prompts = %v, want the prompt forwarded to the UI", mockUI.prompts)
+	}
+}
+
+func TestOperationTimeout(t *testing.T) {
+	origTimeout := timeout
+	defer func() { timeout = origTimeout }()
+
+	tests := []struct {
+		name       string
+		flag       time.Duration
+		flagSet    bool
+		configured string
+		want       time.Duration
+		wantErr    bool
+	}{
+		{name: "default", want: migrate.DefaultTimeout},
+		{name: "git config", configured: "90s", want: 90 * time.Second},
+		{name: "flag beats config", flag: time.Minute, flagSet: true, configured: "90s", want: time.Minute},
+		{name: "flag disables timeout", flag: 0, flagSet: true, want: 0},
+		{name: "invalid config", configured: "soon", wantErr: true},
+		{name: "negative config", configured: "-1s", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			timeout = tt.flag
+			got, err := operationTimeout(tt.flagSet, tt.configured)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("operationTimeout() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("operationTimeout() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunMigration_Interrupted(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mockUI := &mockUI{}
+	mockMigrator := &mockMigrator{
+		migratePRFunc: func(_ context.Context, _ string, _ migrate.Options) error {
+			cancel()
+			return context.Canceled
+		},
+	}
+
+	err := runMigration(ctx, []string{"1", "2", "3"}, mockUI, mockMigrator)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("runMigration() error = %v, want context.Canceled", err)
+	}
+	if len(mockUI.startPRCalls) != 1 {
+		t.Errorf("started %d PRs after interruption, want 1", len(mockUI.startPRCalls))
+	}
+}
+
+func TestTraceLevel(t *testing.T) {
+	origVerbose, origTrace := verbose, trace
+	defer func() { verbose, trace = origVerbose, origTrace }()
+
+	tests := []struct {
+		verbose, trace bool
+		want           migrate.TraceLevel
+	}{
+		{false, false, migrate.TraceOff},
+		{true, false, migrate.TraceCommands},
+		{false, true, migrate.TraceOutput},
+		{true, true, migrate.TraceOutput},
+	}
+
+	for _, tt := range tests {
+		verbose, trace = tt.verbose, tt.trace
+		if got := traceLevel(); got != tt.want {
+			t.Errorf("traceLevel() with verbose=%v trace=%v = %v, want %v", tt.verbose, tt.trace, got, tt.want)
+		}
+	}
+}
+
 func TestVersionInfo(t *testing.T) {
 	// Test that version information is set correctly
 	if version != "dev" {
@@ -301,17 +472,408 @@ func TestEventHandlerIntegration(t *testing.T) {
 	noCreate = false
 	branchName = ""
 
-	err := runMigration([]string{"123"}, mockUI, mockMigrator)
+	err := runMigration(context.Background(), []string{"123"}, mockUI, mockMigrator)
 	if err != nil {
 		t.Errorf("Unexpected error: %v", err)
 	}
 
-	// Verify events were handled
-	if len(mockUI.events) != 2 {
-		t.Errorf("Expected 2 events, got %d", len(mockUI.events))
+	// Verify events were handled, between the start and result of the PR
+	var types []migrate.EventType
+	for _, event := range mockUI.events {
+		types = append(types, event.Type)
+	}
+	wantTypes := []migrate.EventType{migrate.EventStart, migrate.EventInfo, migrate.EventSuccess, migrate.EventResult}
+	if !reflect.DeepEqual(types, wantTypes) {
+		t.Errorf("Expected events %v, got %v", wantTypes, types)
+	}
+
+	if len(mockUI.events) > 1 && mockUI.events[1].Message != "Test event" {
+		t.Errorf("Expected second event message to be 'Test event', got %s", mockUI.events[1].Message)
+	}
+}
+
+type mockPicker struct {
+	mockUI
+	pick     []migrate.PRInfo
+	pickErr  error
+	offered  []migrate.PRInfo
+	total    int
+	finished bool
+}
+
+func (m *mockPicker) Pick(prs []migrate.PRInfo) ([]migrate.PRInfo, error) {
+	m.offered = prs
+	return m.pick, m.pickErr
+}
+
+func (m *mockPicker) SetTotal(total int) { m.total = total }
+func (m *mockPicker) Finish()            { m.finished = true }
+
+func TestRunInteractive(t *testing.T) {
+	branchName = ""
+	forkPRs := []migrate.PRInfo{{Number: 101}, {Number: 102}, {Number: 103}}
+
+	tests := []struct {
+		name         string
+		args         []string
+		listErr      error
+		pick         []migrate.PRInfo
+		pickErr      error
+		wantMigrated []string
+		expectError  bool
+	}{
+		{
+			name:         "selected PRs are migrated",
+			pick:         []migrate.PRInfo{{Number: 101}, {Number: 103}},
+			wantMigrated: []string{"101", "103"},
+		},
+		{
+			name:         "command line refs are kept",
+			args:         []string{"owner/repo#7"},
+			pick:         []migrate.PRInfo{{Number: 102}},
+			wantMigrated: []string{"owner/repo#7", "102"},
+		},
+		{
+			name: "nothing selected",
+		},
+		{
+			name:        "listing fails",
+			listErr:     errors.New("gh not authenticated"),
+			expectError: true,
+		},
+		{
+			name:        "picker fails",
+			pickErr:     errors.New("read error"),
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var migrated []string
+			p := &mockPicker{pick: tt.pick, pickErr: tt.pickErr}
+			mockMigrator := &mockMigrator{
+				listForkPRsFunc: func(_ context.Context, base string) ([]migrate.PRInfo, error) {
+					if base != "" {
+						t.Errorf("ListOpenForkPRs() base = %q, want all bases", base)
+					}
+					return forkPRs, tt.listErr
+				},
+				migratePRFunc: func(_ context.Context, prRef string, _ migrate.Options) error {
+					migrated = append(migrated, prRef)
+					return nil
+				},
+			}
+
+			err := runInteractive(context.Background(), tt.args, p, mockMigrator)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("runInteractive() error = %v, expectError %v", err, tt.expectError)
+			}
+			if strings.Join(migrated, ",") != strings.Join(tt.wantMigrated, ",") {
+				t.Errorf("migrated = %v, want %v", migrated, tt.wantMigrated)
+			}
+			if len(tt.wantMigrated) > 0 {
+				if p.total != len(tt.wantMigrated) {
+					t.Errorf("SetTotal(%d), want %d", p.total, len(tt.wantMigrated))
+				}
+				if !p.finished {
+					t.Error("Finish() was not called")
+				}
+			}
+		})
+	}
+}
+
+func TestCheckRepoDir(t *testing.T) {
+	ctx := context.Background()
+	repoPath := t.TempDir()
+	if err := exec.Command("git", "init", repoPath).Run(); err != nil {
+		t.Fatalf("git init: %v", err)
+	}
+	plainDir := t.TempDir()
+	file := filepath.Join(plainDir, "file.txt")
+	if err := os.WriteFile(file, []byte("x"), 0o600); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		dir     string
+		wantErr string
+	}{
+		{name: "current directory", dir: ""},
+		{name: "repository", dir: repoPath},
+		{name: "missing", dir: filepath.Join(plainDir, "missing"), wantErr: "not a directory"},
+		{name: "file", dir: file, wantErr: "not a directory"},
+		{name: "not a repository", dir: plainDir, wantErr: "not a git repository"},
 	}
 
-	if len(mockUI.events) > 0 && mockUI.events[0].Message != "Test event" {
-		t.Errorf("Expected first event message to be 'Test event', got %s", mockUI.events[0].Message)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkRepoDir(ctx, tt.dir, git.NewWithOptions(git.WithDir(tt.dir)))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("checkRepoDir() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("checkRepoDir() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRunMigration_Report(t *testing.T) {
+	origReport := reportPath
+	defer func() { reportPath = origReport }()
+	reportPath = filepath.Join(t.TempDir(), "report.csv")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mockUI := &mockUI{}
+	mockMigrator := &mockMigrator{
+		migratePRFunc: func(_ context.Context, prRef string, _ migrate.Options) error {
+			if prRef == "2" {
+				cancel()
+				return &migrate.ErrPRNotFound{Number: 2}
+			}
+			return nil
+		},
+	}
+
+	err := runMigration(ctx, []string{"1", "2", "3"}, mockUI, mockMigrator)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("runMigration() error = %v, want context.Canceled", err)
+	}
+
+	data, err := os.ReadFile(reportPath)
+	if err != nil {
+		t.Fatalf("report not written: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("report has %d lines, want a header and 3 PRs:\n%s", len(lines), data)
+	}
+	for i, want := range []string{"1,1,,,,,,0,migrated,", "2,2,,,,,,0,failed,ErrPRNotFound,", "3,3,,,,,,0,failed,interrupted,"} {
+		if !strings.HasPrefix(lines[i+1], want) {
+			t.Errorf("report line %d = %q, want prefix %q", i+1, lines[i+1], want)
+		}
+	}
+}
+
+func TestRunMigration_ReportErrors(t *testing.T) {
+	origReport := reportPath
+	defer func() { reportPath = origReport }()
+
+	tests := []struct {
+		name         string
+		path         string
+		wantMigrated bool
+		wantCode     int
+	}{
+		{name: "unknown format", path: filepath.Join(t.TempDir(), "report.txt"), wantCode: exitUsage},
+		{name: "cannot be written", path: filepath.Join(t.TempDir(), "missing", "report.md"), wantMigrated: true, wantCode: exitError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reportPath = tt.path
+			migrated := false
+			mockMigrator := &mockMigrator{
+				migratePRFunc: func(context.Context, string, migrate.Options) error {
+					migrated = true
+					return nil
+				},
+			}
+
+			err := runMigration(context.Background(), []string{"1"}, &mockUI{}, mockMigrator)
+			if migrated != tt.wantMigrated {
+				t.Errorf("migrated = %v, want %v", migrated, tt.wantMigrated)
+			}
+			if got := exitCode(err); got != tt.wantCode {
+				t.Errorf("exitCode(%v) = %d, want %d", err, got, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestRunMigration_PathMap(t *testing.T) {
+	origPathMap := pathMap
+	defer func() { pathMap = origPathMap }()
+
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "valid.map")
+	invalid := filepath.Join(dir, "invalid.map")
+	if err := os.WriteFile(valid, []byte("pkg/:internal/\n"), 0o600); err != nil {
+		t.Fatalf("write map: %v", err)
+	}
+	if err := os.WriteFile(invalid, []byte("pkg/\n"), 0o600); err != nil {
+		t.Fatalf("write map: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		file    string
+		want    []migrate.PathRename
+		wantErr bool
+	}{
+		{name: "no map"},
+		{name: "valid map", file: valid, want: []migrate.PathRename{{Old: "pkg/", New: "internal/"}}},
+		{name: "invalid map", file: invalid, wantErr: true},
+		{name: "missing map", file: filepath.Join(dir, "missing.map"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pathMap = tt.file
+			var got []migrate.PathRename
+			mockMigrator := &mockMigrator{
+				migratePRFunc: func(_ context.Context, _ string, opts migrate.Options) error {
+					got = opts.PathRenames
+					return nil
+				},
+			}
+			mockUI := &mockUI{}
+
+			err := runMigration(context.Background(), []string{"123"}, mockUI, mockMigrator)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("runMigration() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if len(mockUI.errors) != 1 {
+					t.Errorf("errors shown = %v, want the map error", mockUI.errors)
+				}
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("PathRenames = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadHooks(t *testing.T) {
+	repoPath := t.TempDir()
+	for _, args := range [][]string{
+		{"init", repoPath},
+		{"-C", repoPath, "config", "--add", "mfpr.hook.post-checkout", "go mod tidy"},
+		{"-C", repoPath, "config", "--add", "mfpr.hook.pre-push", "make test"},
+		{"-C", repoPath, "config", "--add", "mfpr.hook.pre-push", "make lint"},
+		{"-C", repoPath, "config", "mfpr.hook.unknown-stage", "ignored"},
+	} {
+		if output, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, output)
+		}
+	}
+
+	got, err := loadHooks(context.Background(), git.NewWithOptions(git.WithDir(repoPath)))
+	if err != nil {
+		t.Fatalf("loadHooks() error = %v", err)
+	}
+	want := migrate.Hooks{
+		migrate.StagePostCheckout: {"go mod tidy"},
+		migrate.StagePrePush:      {"make test", "make lint"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("loadHooks() = %q, want %q", got, want)
+	}
+}
+
+func TestCollectPRRefs(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "prs.txt")
+	if err := os.WriteFile(file, []byte("# release blockers\n200\nowner/repo#201-202\n"), 0o600); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		file    string
+		stdin   string
+		want    []string
+		wantErr string
+	}{
+		{name: "arguments", args: []string{"100-102", "105"}, want: []string{"100", "101", "102", "105"}},
+		{name: "stdin", args: []string{"1", "-"}, stdin: "2\nhttps://github.com/o/r/pull/3\n", want: []string{"1", "2", "https://github.com/o/r/pull/3"}},
+		{name: "file", args: []string{"199"}, file: file, want: []string{"199", "200", "owner/repo#201", "owner/repo#202"}},
+		{name: "missing file", file: filepath.Join(dir, "missing.txt"), wantErr: "no such file"},
+		{name: "empty stdin", args: []string{"-"}, wantErr: "no PR references given"},
+		{name: "bad range", args: []string{"9-1"}, wantErr: "invalid PR range"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := collectPRRefs(tt.args, tt.file, strings.NewReader(tt.stdin))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("collectPRRefs() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("collectPRRefs() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("collectPRRefs() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequireConfirmable(t *testing.T) {
+	tests := []struct {
+		name     string
+		terminal bool
+		prompts  bool
+		yes      bool
+		wantErr  bool
+	}{
+		{name: "terminal", terminal: true, prompts: true},
+		{name: "piped with --yes", prompts: true, yes: true},
+		{name: "piped without prompts", prompts: false},
+		{name: "piped", prompts: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := requireConfirmable(tt.terminal, tt.prompts, tt.yes)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("requireConfirmable() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && exitCode(err) != exitUsage {
+				t.Errorf("exitCode() = %d, want the usage exit code", exitCode(err))
+			}
+		})
+	}
+}
+
+func TestMigrationPrompts(t *testing.T) {
+	origDryRun, origNoPush, origNoCreate, origForce := dryRun, noPush, noCreate, force
+	defer func() {
+		dryRun, noPush, noCreate, force = origDryRun, origNoPush, origNoCreate, origForce
+	}()
+
+	tests := []struct {
+		name                            string
+		dryRun, noPush, noCreate, force bool
+		want                            bool
+	}{
+		{name: "push and create", want: true},
+		{name: "dry run", dryRun: true},
+		{name: "no push, no create", noPush: true, noCreate: true},
+		{name: "no push, create", noPush: true, want: true},
+		{name: "no push, no create, force", noPush: true, noCreate: true, force: true, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dryRun, noPush, noCreate, force = tt.dryRun, tt.noPush, tt.noCreate, tt.force
+			if got := migrationPrompts(); got != tt.want {
+				t.Errorf("migrationPrompts() = %v, want %v", got, tt.want)
+			}
+		})
 	}
 }
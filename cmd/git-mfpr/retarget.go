@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/user/git-mfpr/internal/git"
+	"github.com/user/git-mfpr/internal/migrate"
+	"github.com/user/git-mfpr/internal/ui"
+)
+
+var (
+	retargetFrom string
+	retargetTo   string
+)
+
+func newRetargetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "retarget",
+		Short: "Migrate every open fork PR on a renamed or retired branch onto another",
+		Long: `Find the open fork PRs that target a branch being renamed or retired, replay
+each one's commits onto the new branch, and offer to open the replacement PRs
+against it.
+
+Examples:
+  git mfpr retarget --from master --to main --dry-run   # Preview
+  git mfpr retarget --from release-1.x --to main -y     # Migrate them all
+  git mfpr retarget --from master --to main --close-original`,
+		Args: cobra.NoArgs,
+		Run:  runRetargetCmd,
+	}
+
+	cmd.Flags().StringVar(&retargetFrom, "from", "", "Branch the PRs target now")
+	cmd.Flags().StringVar(&retargetTo, "to", "", "Branch to migrate the PRs onto")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would happen without executing")
+	cmd.Flags().BoolVarP(&assumeYes, "yes", "y", false, "Answer yes to every confirmation (for unattended runs)")
+	cmd.Flags().BoolVar(&force, "force", false, "Overwrite migrated branches that already exist")
+	cmd.Flags().BoolVar(&closeOrig, "close-original", false, "Close each original PR after creating its replacement")
+	cmd.Flags().BoolVar(&noHooks, "no-hooks", false, "Don't run the hooks set in git config mfpr.hook.<stage>")
+	_ = cmd.MarkFlagRequired("from")
+	_ = cmd.MarkFlagRequired("to")
+
+	return cmd
+}
+
+func runRetargetCmd(cmd *cobra.Command, _ []string) {
+	out := ui.NewWithOptions(dryRun, ui.WithAssumeYes(assumeYes), ui.WithVerbose(verbose || trace))
+	if err := requireConfirmable(ui.IsTerminal(os.Stdin), !dryRun, assumeYes); err != nil {
+		out.Error(err)
+		os.Exit(exitCode(err))
+	}
+	migrator, err := newMigrator(cmd)
+	if err != nil {
+		out.Error(err)
+		os.Exit(exitCode(err))
+	}
+	if !noHooks {
+		if hooks, err = loadHooks(cmd.Context(), git.NewWithOptions(git.WithDir(repoDir))); err != nil {
+			out.Error(err)
+			os.Exit(exitCode(err))
+		}
+	}
+
+	if err := retargetPRs(cmd.Context(), out, migrator); err != nil {
+		os.Exit(exitCode(err))
+	}
+}
+
+// retargetPRs migrates the open fork PRs on --from onto --to, through the
+// same loop as migrating several PRs by number.
+func retargetPRs(ctx context.Context, out ui.UI, migrator migrate.Migrator) error {
+	if retargetFrom == retargetTo {
+		err := usageErrorf("--from and --to are both %s", retargetFrom)
+		out.Error(err)
+		return err
+	}
+
+	prs, err := migrator.ListOpenForkPRs(ctx, retargetFrom)
+	if err != nil {
+		out.Error(err)
+		return err
+	}
+	if len(prs) == 0 {
+		out.Info(fmt.Sprintf("No open fork PRs target %s", retargetFrom))
+		return nil
+	}
+
+	out.Info(fmt.Sprintf("Found %d open fork PRs targeting %s:", len(prs), retargetFrom))
+	refs := make([]string, 0, len(prs))
+	for _, pr := range prs {
+		out.Info(fmt.Sprintf("  #%d %s (@%s)", pr.Number, pr.Title, pr.Author))
+		refs = append(refs, strconv.Itoa(pr.Number))
+	}
+
+	_, err = migrateAll(ctx, refs, out, migrator, migrate.Options{
+		DryRun:        dryRun,
+		Base:          retargetTo,
+		Force:         force,
+		CloseOriginal: closeOrig,
+		Hooks:         hooks,
+	})
+	return err
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/user/git-mfpr/internal/migrate"
+)
+
+func TestRetargetPRs(t *testing.T) {
+	origFrom, origTo, origDryRun := retargetFrom, retargetTo, dryRun
+	defer func() {
+		retargetFrom, retargetTo, dryRun = origFrom, origTo, origDryRun
+	}()
+
+	tests := []struct {
+		name      string
+		from, to  string
+		dryRun    bool
+		prs       []migrate.PRInfo
+		listErr   error
+		migrate   error
+		wantErr   bool
+		wantRefs  []string
+		wantInfos []string
+	}{
+		{
+			name: "migrates every PR on the old base",
+			from: "master", to: "main",
+			prs: []migrate.PRInfo{
+				{Number: 7, Title: "Fix typo", Author: "alice"},
+				{Number: 9, Title: "Add flag", Author: "bob"},
+			},
+			wantRefs: []string{"7", "9"},
+			wantInfos: []string{
+				"Found 2 open fork PRs targeting master:",
+				"  #7 Fix typo (@alice)",
+				"  #9 Add flag (@bob)",
+			},
+		},
+		{
+			name: "dry run",
+			from: "release-1.x", to: "main", dryRun: true,
+			prs:      []migrate.PRInfo{{Number: 3, Title: "Backport", Author: "carol"}},
+			wantRefs: []string{"3"},
+			wantInfos: []string{
+				"Found 1 open fork PRs targeting release-1.x:",
+				"  #3 Backport (@carol)",
+			},
+		},
+		{
+			name: "nothing to do",
+			from: "master", to: "main",
+			wantInfos: []string{"No open fork PRs target master"},
+		},
+		{
+			name: "some migrations fail",
+			from: "master", to: "main",
+			prs:      []migrate.PRInfo{{Number: 7}},
+			migrate:  errors.New("failed to migrate some PRs"),
+			wantErr:  true,
+			wantRefs: []string{"7"},
+		},
+		{name: "listing fails", from: "master", to: "main", listErr: errors.New("gh failed"), wantErr: true},
+		{name: "same branch", from: "main", to: "main", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			retargetFrom, retargetTo, dryRun = tt.from, tt.to, tt.dryRun
+
+			var listedBase string
+			var gotRefs []string
+			var gotOpts migrate.Options
+			mockMigrator := &mockMigrator{
+				listForkPRsFunc: func(_ context.Context, base string) ([]migrate.PRInfo, error) {
+					listedBase = base
+					return tt.prs, tt.listErr
+				},
+				migratePRsFunc: func(_ context.Context, refs []string, opts migrate.Options) (*migrate.BatchResult, error) {
+					gotRefs, gotOpts = refs, opts
+					return &migrate.BatchResult{}, tt.migrate
+				},
+			}
+			mockUI := &mockUI{}
+
+			err := retargetPRs(context.Background(), mockUI, mockMigrator)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("retargetPRs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(gotRefs, tt.wantRefs) {
+				t.Errorf("migrated %q, want %q", gotRefs, tt.wantRefs)
+			}
+			if tt.wantInfos != nil && !reflect.DeepEqual(mockUI.infos, tt.wantInfos) {
+				t.Errorf("infos = %q, want %q", mockUI.infos, tt.wantInfos)
+			}
+			if tt.wantRefs == nil {
+				return
+			}
+			if listedBase != tt.from {
+				t.Errorf("listed PRs on %q, want %q", listedBase, tt.from)
+			}
+			if gotOpts.Base != tt.to || gotOpts.DryRun != tt.dryRun {
+				t.Errorf("options = %+v, want Base %s and DryRun %v", gotOpts, tt.to, tt.dryRun)
+			}
+		})
+	}
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/user/git-mfpr/internal/github"
+	"github.com/user/git-mfpr/internal/migrate"
+	"github.com/user/git-mfpr/internal/serve"
+	"github.com/user/git-mfpr/internal/ui"
+)
+
+// secretEnv holds the webhook secret, kept out of flags so it doesn't show up
+// in process listings.
+const secretEnv = "MFPR_WEBHOOK_SECRET"
+
+var (
+	serveAddr    string
+	serveCommand string
+	serveLabel   string
+	serveWorkers int
+	serveWorkDir string
+	replayFile   string
+	replayEvent  string
+)
+
+func newServeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Migrate PRs when asked to by a GitHub webhook",
+		Long: `Listen for GitHub webhook deliveries and migrate a fork PR when a maintainer
+comments /migrate on it or adds the migrate label. Each PR is migrated in a
+fresh clone and the result is posted back as a comment.
+
+The webhook secret is read from $` + secretEnv + `. Subscribe the webhook
+to "Issue comments" and "Pull requests" events.
+
+Examples:
+  git mfpr serve --addr :8080                       # Listen for webhooks
+  git mfpr serve --workers 4 --close-original       # Migrate four PRs at a time
+  git mfpr serve --replay payload.json --event issue_comment --dry-run
+                                                    # Replay a recorded delivery`,
+		Args: cobra.NoArgs,
+		Run:  runServe,
+	}
+
+	cmd.Flags().StringVar(&serveAddr, "addr", ":8080", "Address to listen on")
+	cmd.Flags().StringVar(&serveCommand, "command", serve.DefaultCommand, "Comment that asks for a migration")
+	cmd.Flags().StringVar(&serveLabel, "label", serve.DefaultLabel, "Label that asks for a migration")
+	cmd.Flags().IntVar(&serveWorkers, "workers", 1, "How many PRs to migrate at once")
+	cmd.Flags().StringVar(&serveWorkDir, "work-dir", "", "Directory for the per-PR clones (default a temporary directory)")
+	cmd.Flags().BoolVar(&closeOrig, "close-original", false, "Close the original PR after creating its replacement")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Don't push or create PRs, and print comments instead of posting them")
+	cmd.Flags().StringVar(&replayFile, "replay", "", "Handle this recorded webhook payload instead of listening")
+	cmd.Flags().StringVar(&replayEvent, "event", "", "Event type of the --replay payload (issue_comment or pull_request)")
+
+	return cmd
+}
+
+func runServe(cmd *cobra.Command, _ []string) {
+	out := ui.New()
+	config, err := serveConfig(cmd, os.Getenv(secretEnv))
+	if err != nil {
+		out.Error(err)
+		os.Exit(exitCode(err))
+	}
+
+	if replayFile != "" {
+		err = replay(cmd.Context(), os.Stdout, serve.New(config), config, replayFile, replayEvent)
+	} else {
+		err = listen(cmd.Context(), serve.New(config), config.Logger)
+	}
+	if err != nil {
+		out.Error(err)
+		os.Exit(exitCode(err))
+	}
+}
+
+func serveConfig(cmd *cobra.Command, secret string) (serve.Config, error) {
+	config := serve.Config{
+		Secret:  []byte(secret),
+		Command: serveCommand,
+		Label:   serveLabel,
+		Workers: serveWorkers,
+		WorkDir: serveWorkDir,
+		Options: migrate.Options{
+			DryRun:        dryRun,
+			CloseOriginal: closeOrig,
+		},
+		Logger: log.New(os.Stderr, "", log.LstdFlags),
+	}
+
+	if replayFile == "" && secret == "" {
+		return config, usageErrorf("set %s to the webhook secret", secretEnv)
+	}
+	if replayFile != "" && replayEvent == "" {
+		return config, usageErrorf("--replay needs --event")
+	}
+	if serveWorkers < 1 {
+		return config, usageErrorf("--workers must be at least 1")
+	}
+
+	d := migrate.DefaultTimeout
+	if cmd.Flags().Changed("timeout") {
+		d = timeout
+	}
+	config.NewMigrator = func(dir string) migrate.Migrator {
+		return migrate.NewWithOptions(
+			migrate.WithDir(dir),
+			migrate.WithTimeout(d),
+			migrate.WithTrace(traceLevel()),
+		)
+	}
+	if dryRun {
+		config.GitHub = printComments{GitHub: github.New(), w: os.Stdout}
+	}
+	return config, nil
+}
+
+// printComments prints PR comments instead of posting them.
+type printComments struct {
+	github.GitHub
+	w io.Writer
+}
+
+func (p printComments) CommentPR(_ context.Context, owner, repo string, number int, body string) error {
+	fmt.Fprintf(p.w, "Comment on %s/%s#%d:\n\n%s\n", owner, repo, number, body)
+	return nil
+}
+
+// replay handles one recorded webhook payload, without checking its
+// signature, and migrates its PR straight away.
+func replay(ctx context.Context, w io.Writer, s *serve.Server, config serve.Config, file, event string) error {
+	body, err := os.ReadFile(file) // #nosec G304
+	if err != nil {
+		return err
+	}
+
+	job, err := serve.ParseEvent(event, body, config.Command, config.Label)
+	if err != nil {
+		return err
+	}
+	if job == nil {
+		fmt.Fprintf(w, "%s does not ask for a migration\n", file)
+		return nil
+	}
+	return s.Process(ctx, *job).Err
+}
+
+func listen(ctx context.Context, s *serve.Server, logger *log.Logger) error {
+	mux := http.NewServeMux()
+	mux.Handle("/", s)
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprintln(w, "ok")
+	})
+
+	server := &http.Server{
+		Addr:              serveAddr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx)
+		close(done)
+	}()
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdown)
+	}()
+
+	logger.Printf("listening on %s", serveAddr)
+	err := server.ListenAndServe()
+	if errors.Is(err, http.ErrServerClosed) {
+		<-done
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/user/git-mfpr/internal/migrate"
+	"github.com/user/git-mfpr/internal/runner"
+	"github.com/user/git-mfpr/internal/serve"
+)
+
+var payloads = filepath.Join("..", "..", "internal", "serve", "testdata")
+
+func TestServeConfig(t *testing.T) {
+	origReplay, origEvent, origWorkers := replayFile, replayEvent, serveWorkers
+	defer func() {
+		replayFile, replayEvent, serveWorkers = origReplay, origEvent, origWorkers
+	}()
+
+	tests := []struct {
+		name    string
+		secret  string
+		replay  string
+		event   string
+		workers int
+		wantErr bool
+	}{
+		{name: "listen", secret: "s3cret", workers: 1},
+		{name: "listen without secret", workers: 1, wantErr: true},
+		{name: "replay without secret", replay: "payload.json", event: "issue_comment", workers: 1},
+		{name: "replay without event", replay: "payload.json", workers: 1, wantErr: true},
+		{name: "no workers", secret: "s3cret", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := newServeCmd()
+			replayFile, replayEvent, serveWorkers = tt.replay, tt.event, tt.workers
+
+			config, err := serveConfig(cmd, tt.secret)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("serveConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if !errors.Is(err, migrate.ErrUsage) {
+					t.Errorf("serveConfig() error = %v, want a usage error", err)
+				}
+				return
+			}
+			if string(config.Secret) != tt.secret || config.NewMigrator == nil {
+				t.Errorf("serveConfig() = %+v", config)
+			}
+		})
+	}
+}
+
+func TestReplay(t *testing.T) {
+	var out bytes.Buffer
+	config := serve.Config{
+		Command: serve.DefaultCommand,
+		Label:   serve.DefaultLabel,
+		WorkDir: t.TempDir(),
+		Logger:  log.New(io.Discard, "", 0),
+		// Cloning fails, so nothing is migrated but the failure is reported.
+		Runner: runner.NewFake(),
+		GitHub: printComments{w: &out},
+	}
+	s := serve.New(config)
+
+	err := replay(context.Background(), &out, s, config, filepath.Join(payloads, "issue_comment.json"), "issue_comment")
+	if err == nil || !strings.Contains(err.Error(), "cloning acme/widgets") {
+		t.Errorf("replay() error = %v, want the clone failure", err)
+	}
+	if !strings.Contains(out.String(), "Comment on acme/widgets#42:") {
+		t.Errorf("output = %q, want the comment on #42", out.String())
+	}
+
+	out.Reset()
+	if err := replay(context.Background(), &out, s, config, filepath.Join(payloads, "issue_comment.json"), "pull_request"); err != nil {
+		t.Fatalf("replay() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "does not ask for a migration") {
+		t.Errorf("output = %q, want the payload to be ignored", out.String())
+	}
+}
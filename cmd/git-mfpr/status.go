@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/user/git-mfpr/internal/migrate"
+	"github.com/user/git-mfpr/internal/ui"
+)
+
+func newStatusCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "Show migrated PRs and the state of their originals",
+		Long: `Show every branch created by git-mfpr in this repository, whether the
+original PR is still open, whether the fork has moved on since migration,
+whether the branch has been pushed and whether a replacement PR exists.`,
+		Args: cobra.NoArgs,
+		Run:  runStatus,
+	}
+}
+
+func runStatus(cmd *cobra.Command, _ []string) {
+	out := ui.NewWithOptions(false, ui.WithVerbose(verbose || trace))
+	migrator, err := newMigrator(cmd)
+	if err != nil {
+		out.Error(err)
+		os.Exit(exitCode(err))
+	}
+
+	if err := showStatus(cmd.Context(), out, migrator); err != nil {
+		os.Exit(exitCode(err))
+	}
+}
+
+func showStatus(ctx context.Context, out ui.UI, migrator migrate.Migrator) error {
+	migrator.SetEventHandler(out.HandleEvent)
+
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		out.Error(err)
+		return err
+	}
+
+	if len(statuses) == 0 {
+		out.Info("No migrated PRs recorded in this repository")
+		return nil
+	}
+
+	for i := range statuses {
+		fmt.Println(ui.FormatMigrationStatus(&statuses[i]))
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/user/git-mfpr/internal/migrate"
+)
+
+func TestShowStatus(t *testing.T) {
+	tests := []struct {
+		name        string
+		statuses    []migrate.MigrationStatus
+		statusErr   error
+		expectError bool
+	}{
+		{
+			name: "migrations found",
+			statuses: []migrate.MigrationStatus{
+				{Provenance: migrate.Provenance{Number: 123, Branch: "migrated-123"}, LocalBranch: true},
+			},
+		},
+		{
+			name: "no migrations",
+		},
+		{
+			name:        "status failure",
+			statusErr:   errors.New("no origin remote"),
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUI := &mockUI{}
+			mockMigrator := &mockMigrator{
+				statusFunc: func(_ context.Context) ([]migrate.MigrationStatus, error) {
+					return tt.statuses, tt.statusErr
+				},
+			}
+
+			err := showStatus(context.Background(), mockUI, mockMigrator)
+			if (err != nil) != tt.expectError {
+				t.Errorf("showStatus() error = %v, expectError %v", err, tt.expectError)
+			}
+			if tt.expectError && len(mockUI.errors) != 1 {
+				t.Errorf("Expected 1 error reported to UI, got %d", len(mockUI.errors))
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+// Command bot shows how to embed git-mfpr with package mfpr. It migrates the
+// PRs given as arguments in the clone named by -C, accepting every
+// confirmation, and prints one JSON line per PR.
+//
+//	go run ./examples/bot -C /srv/clones/app 120-125
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+
+	"github.com/user/git-mfpr/pkg/mfpr"
+)
+
+type line struct {
+	PR       string `json:"pr"`
+	Status   string `json:"status"`
+	Branch   string `json:"branch,omitempty"`
+	SHA      string `json:"sha,omitempty"`
+	NewPR    string `json:"new_pr,omitempty"`
+	Error    string `json:"error,omitempty"`
+	NotFound bool   `json:"not_found,omitempty"`
+}
+
+func main() {
+	dir := flag.String("C", "", "repository to migrate in")
+	dryRun := flag.Bool("dry-run", false, "only show what would happen")
+	flag.Parse()
+
+	refs, err := mfpr.ExpandPRRefs(flag.Args())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	m := mfpr.New(mfpr.WithDir(*dir))
+	m.SetConfirmHandler(func(string) bool { return true })
+	m.SetEventHandler(func(e mfpr.Event) {
+		if e.Type == mfpr.EventInfo && e.Message != "" {
+			log.Print(e.Message)
+		}
+	})
+
+	batch, err := m.MigratePRs(ctx, refs, mfpr.Options{DryRun: *dryRun})
+	out := json.NewEncoder(os.Stdout)
+	for _, r := range batch.Results {
+		l := line{PR: r.URL(), Status: string(r.Status()), Branch: r.Branch, SHA: r.SHA}
+		if r.Created != nil {
+			l.NewPR = r.Created.URL
+		}
+		if r.Err != nil {
+			l.Error = r.Err.Error()
+			l.NotFound = errors.Is(r.Err, mfpr.ErrNotFound)
+		}
+		if err := out.Encode(l); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if err != nil {
+		os.Exit(1)
+	}
+}
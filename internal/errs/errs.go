@@ -0,0 +1,23 @@
+// Package errs defines the categories that every git-mfpr error falls into.
+// The typed errors in git, github and migrate report their category through
+// an Is method, so callers can use errors.Is for the category and errors.As
+// for the details.
+package errs
+
+import "errors"
+
+var (
+	// ErrUsage is a mistake in how git-mfpr was asked to run, such as a
+	// malformed PR reference.
+	ErrUsage = errors.New("usage error")
+	// ErrNotFound is a PR, branch or revision that does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrPrecondition is a state that stops a migration before it starts,
+	// such as a closed PR or an existing branch.
+	ErrPrecondition = errors.New("precondition failed")
+	// ErrGit is a git command that failed.
+	ErrGit = errors.New("git failed")
+	// ErrGitHub is a gh command or forge API request that failed, on GitHub,
+	// GitLab or Gitea.
+	ErrGitHub = errors.New("GitHub request failed")
+)
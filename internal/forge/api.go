@@ -0,0 +1,193 @@
+package forge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/user/git-mfpr/internal/runner"
+)
+
+// API makes requests to a forge's REST API. GitLab and Gitea use it; GitHub
+// goes through gh.
+type API struct {
+	Forge Kind
+	// BaseURL is the API root, such as https://gitlab.com/api/v4.
+	BaseURL string
+	// Header is sent with every request, typically for authentication.
+	Header  http.Header
+	Client  *http.Client
+	Timeout time.Duration
+}
+
+// Do sends in, if not nil, as JSON to path under BaseURL (or to path itself
+// if it is a full URL) and decodes the JSON response into out, if not nil.
+func (a *API) Do(ctx context.Context, method, path string, in, out interface{}) error {
+	body, err := a.Raw(ctx, method, path, in)
+	if err != nil || out == nil {
+		return err
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return &ErrRequestFailed{Forge: a.Forge, Path: path, Detail: "invalid response: " + err.Error(), Err: err}
+	}
+	return nil
+}
+
+// Describe is the request Do would send for method, path and in's fields,
+// written on one line for dry runs.
+func (a *API) Describe(method, path string, in map[string]string) string {
+	keys := make([]string, 0, len(in))
+	for key := range in {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := []string{method, a.url(path)}
+	for _, key := range keys {
+		parts = append(parts, fmt.Sprintf("%s=%q", key, in[key]))
+	}
+	return strings.Join(parts, " ")
+}
+
+// url is path under BaseURL, or path itself if it is a full URL.
+func (a *API) url(path string) string {
+	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		return path
+	}
+	return strings.TrimRight(a.BaseURL, "/") + "/" + strings.TrimLeft(path, "/")
+}
+
+// Raw is like Do but returns the response body as is.
+func (a *API) Raw(ctx context.Context, method, path string, in interface{}) ([]byte, error) {
+	if a.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, a.Timeout)
+		defer cancel()
+	}
+
+	var reqBody io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return nil, err
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, a.url(path), reqBody)
+	if err != nil {
+		return nil, &ErrRequestFailed{Forge: a.Forge, Path: path, Detail: err.Error(), Err: err}
+	}
+	for key, values := range a.Header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	client := a.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		switch ctx.Err() {
+		case context.DeadlineExceeded:
+			return nil, &ErrRequestFailed{Forge: a.Forge, Path: path, Detail: fmt.Sprintf("timed out after %s", a.Timeout), Err: ctx.Err()}
+		case context.Canceled:
+			return nil, &ErrRequestFailed{Forge: a.Forge, Path: path, Detail: "cancelled", Err: ctx.Err()}
+		}
+		return nil, &ErrRequestFailed{Forge: a.Forge, Path: path, Detail: err.Error(), Err: err}
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &ErrRequestFailed{Forge: a.Forge, Path: path, Status: resp.StatusCode, Detail: err.Error(), Err: err}
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, &ErrRequestFailed{Forge: a.Forge, Path: path, Status: resp.StatusCode, Detail: errorDetail(resp.Status, body)}
+	}
+	return body, nil
+}
+
+// errorDetail adds the message GitLab and Gitea put in error responses to
+// the HTTP status.
+func errorDetail(status string, body []byte) string {
+	var apiErr struct {
+		Message interface{} `json:"message"`
+		Error   string      `json:"error"`
+	}
+	if json.Unmarshal(body, &apiErr) != nil {
+		return status
+	}
+	switch message := apiErr.Message.(type) {
+	case string:
+		if message != "" {
+			return fmt.Sprintf("%s: %s", status, message)
+		}
+	case nil:
+	default:
+		// GitLab reports validation errors as a list or map.
+		data, _ := json.Marshal(message)
+		return fmt.Sprintf("%s: %s", status, data)
+	}
+	if apiErr.Error != "" {
+		return fmt.Sprintf("%s: %s", status, apiErr.Error)
+	}
+	return status
+}
+
+// ObserveHTTP returns a copy of client that reports each request to observer
+// once its response headers arrive, as a command named after the method with
+// the URL as its argument. A status outside 2xx is reported as the exit code,
+// and a request that got no response as exit code -1.
+func ObserveHTTP(client *http.Client, observer runner.Observer) *http.Client {
+	observed := *client
+	transport := observed.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	observed.Transport = &observedTransport{transport: transport, observer: observer}
+	return &observed
+}
+
+type observedTransport struct {
+	transport http.RoundTripper
+	observer  runner.Observer
+}
+
+func (t *observedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.transport.RoundTrip(req)
+	result := runner.Result{
+		Cmd:      runner.Cmd{Name: req.Method, Args: []string{req.URL.Redacted()}},
+		Duration: time.Since(start),
+		Err:      err,
+	}
+	switch {
+	case err != nil:
+		result.ExitCode = -1
+		result.Stderr = []byte(err.Error())
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		result.ExitCode = resp.StatusCode
+		result.Stderr = []byte(resp.Status)
+		result.Err = errors.New(resp.Status)
+	}
+	t.observer(result)
+	return resp, err
+}
+
+// IsNotFound reports whether err is a request that failed with 404.
+func IsNotFound(err error) bool {
+	var reqErr *ErrRequestFailed
+	return errors.As(err, &reqErr) && reqErr.Status == http.StatusNotFound
+}
@@ -0,0 +1,140 @@
+package forge
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/user/git-mfpr/internal/errs"
+	"github.com/user/git-mfpr/internal/runner"
+)
+
+func TestAPI_Do(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v4/things" || r.Method != http.MethodPost {
+			t.Errorf("request = %s %s, want POST /api/v4/things", r.Method, r.URL.Path)
+		}
+		if got := r.Header.Get("PRIVATE-TOKEN"); got != "secret" {
+			t.Errorf("PRIVATE-TOKEN = %q, want the configured header", got)
+		}
+		if got := r.Header.Get("Content-Type"); got != "application/json" {
+			t.Errorf("Content-Type = %q, want JSON", got)
+		}
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != `{"name":"x"}` {
+			t.Errorf("body = %s, want the encoded input", body)
+		}
+		w.Write([]byte(`{"id":5}`))
+	}))
+	defer server.Close()
+
+	api := API{Forge: GitLab, BaseURL: server.URL + "/api/v4/", Header: http.Header{"Private-Token": {"secret"}}}
+	var out struct {
+		ID int `json:"id"`
+	}
+	if err := api.Do(context.Background(), http.MethodPost, "/things", map[string]string{"name": "x"}, &out); err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	if out.ID != 5 {
+		t.Errorf("Do() decoded %+v, want id 5", out)
+	}
+}
+
+func TestAPI_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		body       string
+		wantStatus int
+		wantDetail string
+	}{
+		{name: "GitLab message", status: 404, body: `{"message":"404 Project Not Found"}`, wantStatus: 404, wantDetail: "404 Not Found: 404 Project Not Found"},
+		{name: "validation errors", status: 409, body: `{"message":["Another open merge request already exists"]}`, wantStatus: 409, wantDetail: `409 Conflict: ["Another open merge request already exists"]`},
+		{name: "error field", status: 401, body: `{"error":"invalid_token"}`, wantStatus: 401, wantDetail: "401 Unauthorized: invalid_token"},
+		{name: "plain body", status: 500, body: "oops", wantStatus: 500, wantDetail: "500 Internal Server Error"},
+		{name: "invalid JSON", status: 200, body: "<html>", wantDetail: "invalid response"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			api := API{Forge: Gitea, BaseURL: server.URL}
+			var out map[string]interface{}
+			err := api.Do(context.Background(), http.MethodGet, "repos/o/r", nil, &out)
+
+			var reqErr *ErrRequestFailed
+			if !errors.As(err, &reqErr) {
+				t.Fatalf("Do() error = %v, want *ErrRequestFailed", err)
+			}
+			if reqErr.Status != tt.wantStatus || !strings.HasPrefix(reqErr.Detail, tt.wantDetail) {
+				t.Errorf("Do() error = %d %q, want %d %q", reqErr.Status, reqErr.Detail, tt.wantStatus, tt.wantDetail)
+			}
+			if !errors.Is(err, errs.ErrGitHub) {
+				t.Errorf("Do() error = %v, want a forge error", err)
+			}
+			if IsNotFound(err) != (tt.status == 404) {
+				t.Errorf("IsNotFound() = %v for status %d", IsNotFound(err), tt.status)
+			}
+		})
+	}
+}
+
+func TestAPI_Timeout(t *testing.T) {
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) { <-done }))
+	defer server.Close()
+	defer close(done)
+
+	api := API{Forge: GitLab, BaseURL: server.URL, Timeout: 10 * time.Millisecond}
+	_, err := api.Raw(context.Background(), http.MethodGet, "slow", nil)
+	if err == nil || !strings.Contains(err.Error(), "timed out after 10ms") {
+		t.Errorf("Raw() error = %v, want a timeout", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Raw() error = %v, want it to wrap context.DeadlineExceeded", err)
+	}
+}
+
+func TestObserveHTTP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	var observed []runner.Result
+	api := API{Forge: Gitea, BaseURL: server.URL, Client: ObserveHTTP(http.DefaultClient, func(result runner.Result) {
+		observed = append(observed, result)
+	})}
+	if err := api.Do(context.Background(), http.MethodGet, "/things?page=2", nil, nil); err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	if err := api.Do(context.Background(), http.MethodDelete, "/missing", nil, nil); !IsNotFound(err) {
+		t.Fatalf("Do() error = %v, want not found", err)
+	}
+
+	if len(observed) != 2 {
+		t.Fatalf("observed %d requests, want 2", len(observed))
+	}
+	if got, want := observed[0].Cmd.String(), "GET '"+server.URL+"/things?page=2'"; got != want || observed[0].ExitCode != 0 || observed[0].Err != nil {
+		t.Errorf("observed %q exit %d (%v), want %q exit 0", got, observed[0].ExitCode, observed[0].Err, want)
+	}
+	if got, want := observed[1].Cmd.String(), "DELETE "+server.URL+"/missing"; got != want || observed[1].ExitCode != http.StatusNotFound {
+		t.Errorf("observed %q exit %d, want %q exit 404", got, observed[1].ExitCode, want)
+	}
+	if http.DefaultClient.Transport != nil {
+		t.Error("ObserveHTTP() changed the client it was given")
+	}
+}
@@ -0,0 +1,99 @@
+package forge
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/user/git-mfpr/internal/runner"
+)
+
+// CheckoutRef fetches ref, such as refs/pull/7/head, from remote into a new
+// branch and checks it out, running git in dir. It fetches from origin when
+// origin is remote, so that git uses origin's URL and credentials, and
+// otherwise from remote's HTTPS URL. Each git command is bounded by timeout;
+// a zero timeout leaves it unbounded.
+func CheckoutRef(ctx context.Context, r runner.Runner, dir string, timeout time.Duration, remote *Remote, number int, ref, branch string) error {
+	git := gitCommand(ctx, r, dir, timeout, func(detail string, err error) error {
+		return &ErrPRCheckoutFailed{Number: number, Detail: detail, Err: err}
+	})
+
+	if _, err := git("fetch", fetchSource(ctx, r, dir, remote), ref+":refs/heads/"+branch); err != nil {
+		return err
+	}
+	_, err := git("checkout", branch)
+	return err
+}
+
+// FetchPatches fetches ref from remote as CheckoutRef does, without creating
+// a branch, and returns the commits from base to head as an mbox patch series.
+// It is for forges whose API has no patch series, so git's credentials are
+// used instead.
+func FetchPatches(ctx context.Context, r runner.Runner, dir string, timeout time.Duration, remote *Remote, number int, ref, base, head string) ([]byte, error) {
+	git := gitCommand(ctx, r, dir, timeout, func(detail string, err error) error {
+		return &ErrPRPatchFailed{Number: number, Detail: detail, Err: err}
+	})
+
+	if _, err := git("fetch", fetchSource(ctx, r, dir, remote), ref); err != nil {
+		return nil, err
+	}
+	return git("format-patch", "--stdout", base+".."+head)
+}
+
+// DescribeCheckoutRef is the git commands CheckoutRef runs, fetching from
+// remote's HTTPS URL.
+func DescribeCheckoutRef(remote *Remote, ref, branch string) string {
+	return fmt.Sprintf("git fetch %s %s:refs/heads/%s && git checkout %s", cloneURL(remote), ref, branch, branch)
+}
+
+// DescribeFetchPatches is the git commands FetchPatches runs, piped to git am.
+func DescribeFetchPatches(remote *Remote, ref string) string {
+	return fmt.Sprintf("git fetch %s %s && git format-patch --stdout <base>..FETCH_HEAD | git am", cloneURL(remote), ref)
+}
+
+// gitCommand returns a function that runs git in dir, each command bounded by
+// timeout, and reports failures through fail, with git's error output as the
+// detail.
+func gitCommand(ctx context.Context, r runner.Runner, dir string, timeout time.Duration, fail func(detail string, err error) error) func(args ...string) ([]byte, error) {
+	return func(args ...string) ([]byte, error) {
+		cmdCtx := ctx
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			cmdCtx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		result := r.Run(cmdCtx, runner.Cmd{Name: "git", Args: args, Dir: dir})
+		if result.Err == nil {
+			return result.Stdout, nil
+		}
+		if ctx.Err() != nil {
+			return nil, fail("cancelled", ctx.Err())
+		}
+		if cmdCtx.Err() != nil {
+			return nil, fail(fmt.Sprintf("timed out after %s", timeout), cmdCtx.Err())
+		}
+		detail := strings.TrimSpace(string(result.Stderr))
+		if detail == "" {
+			detail = result.Err.Error()
+		}
+		return nil, fail(detail, result.Err)
+	}
+}
+
+// fetchSource is origin when origin is remote, and otherwise remote's HTTPS
+// URL.
+func fetchSource(ctx context.Context, r runner.Runner, dir string, remote *Remote) string {
+	origin := r.Run(ctx, runner.Cmd{Name: "git", Args: []string{"remote", "get-url", "origin"}, Dir: dir})
+	if origin.Err == nil {
+		if parsed, err := ParseRemoteURL(strings.TrimSpace(string(origin.Stdout))); err == nil &&
+			strings.EqualFold(parsed.Host, remote.Host) && strings.EqualFold(parsed.String(), remote.String()) {
+			return "origin"
+		}
+	}
+	return cloneURL(remote)
+}
+
+func cloneURL(remote *Remote) string {
+	return remote.BaseURL + "/" + remote.Owner + "/" + remote.Repo + ".git"
+}
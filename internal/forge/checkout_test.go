@@ -0,0 +1,112 @@
+package forge
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/user/git-mfpr/internal/errs"
+	"github.com/user/git-mfpr/internal/runner"
+)
+
+func TestCheckoutRef(t *testing.T) {
+	remote := &Remote{Host: "gitlab.com", BaseURL: "https://gitlab.com", Owner: "group/sub", Repo: "repo"}
+
+	tests := []struct {
+		name    string
+		origin  string
+		fetch   string
+		fetchOK bool
+		wantErr string
+	}{
+		{
+			name:    "from origin",
+			origin:  "git@gitlab.com:group/sub/repo.git",
+			fetch:   "git fetch origin refs/merge-requests/7/head:refs/heads/pr-7",
+			fetchOK: true,
+		},
+		{
+			name:    "from another repository",
+			origin:  "git@gitlab.com:me/repo.git",
+			fetch:   "git fetch https://gitlab.com/group/sub/repo.git refs/merge-requests/7/head:refs/heads/pr-7",
+			fetchOK: true,
+		},
+		{
+			name:    "fetch fails",
+			fetch:   "git fetch https://gitlab.com/group/sub/repo.git refs/merge-requests/7/head:refs/heads/pr-7",
+			wantErr: "failed to checkout PR #7: fatal: couldn't find remote ref",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			origin := runner.Entry{Command: "git remote get-url origin", Dir: "/work", Stdout: tt.origin + "\n"}
+			if tt.origin == "" {
+				origin = runner.Entry{Command: "git remote get-url origin", Dir: "/work", Stderr: "error: No such remote 'origin'", ExitCode: 2}
+			}
+			fetch := runner.Entry{Command: tt.fetch, Dir: "/work"}
+			if !tt.fetchOK {
+				fetch.Stderr, fetch.ExitCode = "fatal: couldn't find remote ref\n", 128
+			}
+			fake := runner.NewFake(origin, fetch, runner.Entry{Command: "git checkout pr-7", Dir: "/work"})
+
+			err := CheckoutRef(context.Background(), fake, "/work", 0, remote, 7, "refs/merge-requests/7/head", "pr-7")
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("CheckoutRef() error = %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Fatalf("CheckoutRef() error = %v, want %q", err, tt.wantErr)
+			}
+			if !errors.Is(err, errs.ErrGitHub) {
+				t.Errorf("CheckoutRef() error = %v, want a forge error", err)
+			}
+		})
+	}
+}
+
+func TestFetchPatches(t *testing.T) {
+	remote := &Remote{Host: "gitlab.com", BaseURL: "https://gitlab.com", Owner: "group/sub", Repo: "repo"}
+	fake := runner.NewFake(
+		runner.Entry{Command: "git remote get-url origin", Dir: "/work", Stdout: "git@gitlab.com:group/sub/repo.git\n"},
+		runner.Entry{Command: "git fetch origin refs/merge-requests/7/head", Dir: "/work"},
+		runner.Entry{Command: "git format-patch --stdout base..head", Dir: "/work", Stderr: "fatal: bad revision 'base..head'\n", ExitCode: 128},
+	)
+
+	_, err := FetchPatches(context.Background(), fake, "/work", 0, remote, 7, "refs/merge-requests/7/head", "base", "head")
+	var patchErr *ErrPRPatchFailed
+	if !errors.As(err, &patchErr) || patchErr.Detail != "fatal: bad revision 'base..head'" {
+		t.Fatalf("FetchPatches() error = %v, want the format-patch failure", err)
+	}
+	if !errors.Is(err, errs.ErrGitHub) {
+		t.Errorf("FetchPatches() error = %v, want a forge error", err)
+	}
+}
+
+// hangingRunner answers git remote get-url and blocks every other command
+// until its context is done.
+type hangingRunner struct{}
+
+func (hangingRunner) Run(ctx context.Context, cmd runner.Cmd) *runner.Result {
+	if len(cmd.Args) > 0 && cmd.Args[0] == "remote" {
+		return &runner.Result{Cmd: cmd, Err: errors.New("no origin")}
+	}
+	<-ctx.Done()
+	return &runner.Result{Cmd: cmd, Err: ctx.Err()}
+}
+
+func TestCheckoutRef_Timeout(t *testing.T) {
+	remote := &Remote{Host: "gitlab.com", BaseURL: "https://gitlab.com", Owner: "group", Repo: "repo"}
+
+	err := CheckoutRef(context.Background(), hangingRunner{}, "/work", 10*time.Millisecond, remote, 7, "refs/merge-requests/7/head", "pr-7")
+	var checkoutErr *ErrPRCheckoutFailed
+	if !errors.As(err, &checkoutErr) || checkoutErr.Detail != "timed out after 10ms" {
+		t.Fatalf("CheckoutRef() error = %v, want a timeout", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("CheckoutRef() error = %v, want it to wrap context.DeadlineExceeded", err)
+	}
+}
@@ -0,0 +1,150 @@
+package forge
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Remote is a repository on a forge, as named by a git remote or a PR URL.
+type Remote struct {
+	// Host is the forge's host name, with the port for HTTP(S) URLs.
+	Host string
+	// BaseURL is the forge's web address, such as https://gitlab.com.
+	BaseURL string
+	Owner   string
+	Repo    string
+}
+
+func (r *Remote) String() string {
+	return r.Owner + "/" + r.Repo
+}
+
+// ParseRemoteURL reads a remote URL in any form git accepts:
+// git@host:owner/repo.git, ssh://git@host/owner/repo.git or
+// https://host/owner/repo.git. The owner may be a nested GitLab group.
+func ParseRemoteURL(rawURL string) (*Remote, error) {
+	remote := &Remote{}
+	var path string
+
+	if i := strings.Index(rawURL, ":"); i > 0 && !strings.Contains(rawURL, "://") && !strings.Contains(rawURL[:i], "/") {
+		// scp-like syntax: [user@]host:path
+		remote.Host = rawURL[strings.LastIndex(rawURL[:i], "@")+1 : i]
+		remote.BaseURL = "https://" + remote.Host
+		path = rawURL[i+1:]
+	} else {
+		u, err := url.Parse(rawURL)
+		if err != nil || u.Host == "" {
+			return nil, &ErrInvalidURL{URL: rawURL, Detail: "not a remote URL"}
+		}
+		switch u.Scheme {
+		case "http", "https":
+			remote.Host = u.Host
+			remote.BaseURL = u.Scheme + "://" + u.Host
+		case "ssh", "git", "git+ssh":
+			remote.Host = u.Hostname()
+			remote.BaseURL = "https://" + remote.Host
+		default:
+			return nil, &ErrInvalidURL{URL: rawURL, Detail: "not a remote URL"}
+		}
+		path = u.Path
+	}
+
+	parts := strings.Split(strings.TrimSuffix(strings.Trim(path, "/"), ".git"), "/")
+	if len(parts) < 2 || remote.Host == "" {
+		return nil, &ErrInvalidURL{URL: rawURL, Detail: "not a remote URL"}
+	}
+	for _, part := range parts {
+		if part == "" {
+			return nil, &ErrInvalidURL{URL: rawURL, Detail: "not a remote URL"}
+		}
+	}
+	remote.Owner = strings.Join(parts[:len(parts)-1], "/")
+	remote.Repo = parts[len(parts)-1]
+
+	// Only GitLab has nested namespaces.
+	if len(parts) > 2 && DetectHost(remote.Host) == GitHub {
+		return nil, &ErrInvalidURL{URL: rawURL, Detail: "not a remote URL"}
+	}
+	return remote, nil
+}
+
+// ParsePRURL reads a pull or merge request URL of any supported forge:
+// https://github.com/owner/repo/pull/N,
+// https://gitlab.com/group/project/-/merge_requests/N or
+// https://codeberg.org/owner/repo/pulls/N. Anything after the number, such as
+// /files or /diffs, is ignored. The kind comes from the host if DetectHost
+// knows it, and otherwise from the shape of the path.
+func ParsePRURL(rawURL string) (*Remote, int, Kind, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return nil, 0, "", &ErrInvalidURL{URL: rawURL, Detail: "not a URL"}
+	}
+
+	owner, repo, number, kind, err := ParsePRPath(u.Path)
+	if err != nil {
+		return nil, 0, "", &ErrInvalidURL{URL: rawURL, Detail: err.(*ErrInvalidURL).Detail}
+	}
+	if host := DetectHost(u.Host); host != "" {
+		kind = host
+	}
+	remote := &Remote{Host: u.Host, BaseURL: u.Scheme + "://" + u.Host, Owner: owner, Repo: repo}
+	return remote, number, kind, nil
+}
+
+// ParsePRPath reads the path of a PR URL, as ParsePRURL does, for references
+// without a host such as owner/repo/pull/N.
+func ParsePRPath(path string) (owner, repo string, number int, kind Kind, err error) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+
+	var n string
+	dash := indexOf(parts, "-")
+	switch {
+	case dash >= 2 && len(parts) > dash+2 && parts[dash+1] == "merge_requests":
+		kind, n = GitLab, parts[dash+2]
+		owner, repo = strings.Join(parts[:dash-1], "/"), parts[dash-1]
+	case len(parts) >= 4 && parts[2] == "pull":
+		kind, n = GitHub, parts[3]
+		owner, repo = parts[0], parts[1]
+	case len(parts) >= 4 && parts[2] == "pulls":
+		kind, n = Gitea, parts[3]
+		owner, repo = parts[0], parts[1]
+	default:
+		return "", "", 0, "", &ErrInvalidURL{URL: path, Detail: "not a pull or merge request URL"}
+	}
+
+	number, err = strconv.Atoi(n)
+	if err != nil || number <= 0 {
+		return "", "", 0, "", &ErrInvalidURL{URL: path, Detail: fmt.Sprintf("invalid PR number %q", n)}
+	}
+	return owner, repo, number, kind, nil
+}
+
+func indexOf(parts []string, s string) int {
+	for i, part := range parts {
+		if part == s {
+			return i
+		}
+	}
+	return -1
+}
+
+// DetectHost guesses the forge at host from its name: github.com and hosts
+// containing "github", "gitlab", "gitea", "forgejo" or "codeberg". It returns
+// "" for other hosts, which need git config mfpr.forge.
+func DetectHost(host string) Kind {
+	host = strings.ToLower(host)
+	if i := strings.LastIndex(host, ":"); i >= 0 {
+		host = host[:i]
+	}
+	switch {
+	case strings.Contains(host, "github"):
+		return GitHub
+	case strings.Contains(host, "gitlab"):
+		return GitLab
+	case strings.Contains(host, "gitea"), strings.Contains(host, "forgejo"), strings.Contains(host, "codeberg"):
+		return Gitea
+	}
+	return ""
+}
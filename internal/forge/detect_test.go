@@ -0,0 +1,134 @@
+package forge
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/user/git-mfpr/internal/errs"
+)
+
+func TestParseRemoteURL(t *testing.T) {
+	tests := []struct {
+		url     string
+		want    Remote
+		wantErr bool
+	}{
+		{url: "git@github.com:owner/repo.git", want: Remote{Host: "github.com", BaseURL: "https://github.com", Owner: "owner", Repo: "repo"}},
+		{url: "https://github.com/owner/repo", want: Remote{Host: "github.com", BaseURL: "https://github.com", Owner: "owner", Repo: "repo"}},
+		{url: "ssh://git@gitlab.example.com:2222/group/sub/repo.git", want: Remote{Host: "gitlab.example.com", BaseURL: "https://gitlab.example.com", Owner: "group/sub", Repo: "repo"}},
+		{url: "http://localhost:3000/owner/repo.git", want: Remote{Host: "localhost:3000", BaseURL: "http://localhost:3000", Owner: "owner", Repo: "repo"}},
+		{url: "codeberg.org:owner/repo", want: Remote{Host: "codeberg.org", BaseURL: "https://codeberg.org", Owner: "owner", Repo: "repo"}},
+		{url: "git@github.com:a/b/c.git", wantErr: true},
+		{url: "git@github.com:repo.git", wantErr: true},
+		{url: "https://github.com//repo", wantErr: true},
+		{url: "file:///srv/git/repo.git", wantErr: true},
+		{url: "/srv/git/repo.git", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			got, err := ParseRemoteURL(tt.url)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseRemoteURL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if !errors.Is(err, errs.ErrUsage) {
+					t.Errorf("ParseRemoteURL() error = %v, want a usage error", err)
+				}
+				return
+			}
+			if *got != tt.want {
+				t.Errorf("ParseRemoteURL() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePRURL(t *testing.T) {
+	tests := []struct {
+		url        string
+		wantRemote Remote
+		wantNumber int
+		wantKind   Kind
+		wantErr    string
+	}{
+		{
+			url:        "https://github.com/owner/repo/pull/7/files",
+			wantRemote: Remote{Host: "github.com", BaseURL: "https://github.com", Owner: "owner", Repo: "repo"},
+			wantNumber: 7,
+			wantKind:   GitHub,
+		},
+		{
+			url:        "https://gitlab.com/group/sub/repo/-/merge_requests/12/diffs",
+			wantRemote: Remote{Host: "gitlab.com", BaseURL: "https://gitlab.com", Owner: "group/sub", Repo: "repo"},
+			wantNumber: 12,
+			wantKind:   GitLab,
+		},
+		{
+			url:        "https://codeberg.org/owner/repo/pulls/34",
+			wantRemote: Remote{Host: "codeberg.org", BaseURL: "https://codeberg.org", Owner: "owner", Repo: "repo"},
+			wantNumber: 34,
+			wantKind:   Gitea,
+		},
+		{
+			// An unknown host is recognised by the path.
+			url:        "http://git.internal:8080/team/repo/-/merge_requests/5",
+			wantRemote: Remote{Host: "git.internal:8080", BaseURL: "http://git.internal:8080", Owner: "team", Repo: "repo"},
+			wantNumber: 5,
+			wantKind:   GitLab,
+		},
+		{
+			// A known host wins over the path.
+			url:        "https://gitea.example.com/owner/repo/pull/3",
+			wantRemote: Remote{Host: "gitea.example.com", BaseURL: "https://gitea.example.com", Owner: "owner", Repo: "repo"},
+			wantNumber: 3,
+			wantKind:   Gitea,
+		},
+		{url: "https://github.com/owner/repo/issues/1", wantErr: "not a pull or merge request URL"},
+		{url: "https://gitlab.com/group/repo/-/merge_requests/new", wantErr: `invalid PR number "new"`},
+		{url: "https://github.com/owner/repo/pull/0", wantErr: `invalid PR number "0"`},
+		{url: "owner/repo/pull/1", wantErr: "not a URL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			remote, number, kind, err := ParsePRURL(tt.url)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != "invalid URL "+tt.url+": "+tt.wantErr {
+					t.Errorf("ParsePRURL() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParsePRURL() error = %v", err)
+			}
+			if *remote != tt.wantRemote || number != tt.wantNumber || kind != tt.wantKind {
+				t.Errorf("ParsePRURL() = %+v, %d, %s; want %+v, %d, %s",
+					*remote, number, kind, tt.wantRemote, tt.wantNumber, tt.wantKind)
+			}
+		})
+	}
+}
+
+func TestDetectHost(t *testing.T) {
+	tests := []struct {
+		host string
+		want Kind
+	}{
+		{"github.com", GitHub},
+		{"github.example.com", GitHub},
+		{"gitlab.com", GitLab},
+		{"GitLab.Example.com:8443", GitLab},
+		{"codeberg.org", Gitea},
+		{"gitea.example.com", Gitea},
+		{"forgejo.example.com", Gitea},
+		{"git.example.com", ""},
+		{"localhost:3000", ""},
+	}
+
+	for _, tt := range tests {
+		if got := DetectHost(tt.host); got != tt.want {
+			t.Errorf("DetectHost(%q) = %q, want %q", tt.host, got, tt.want)
+		}
+	}
+}
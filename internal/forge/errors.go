@@ -0,0 +1,106 @@
+package forge
+
+import (
+	"fmt"
+
+	"github.com/user/git-mfpr/internal/errs"
+)
+
+type (
+	ErrPRNotFound struct {
+		Number int
+		Owner  string
+		Repo   string
+	}
+
+	ErrPRCheckoutFailed struct {
+		Number int
+		Detail string
+		Err    error
+	}
+
+	ErrPRPatchFailed struct {
+		Number int
+		Detail string
+		Err    error
+	}
+
+	ErrInvalidURL struct {
+		URL    string
+		Detail string
+	}
+
+	ErrUnknownForge struct {
+		Value string
+	}
+
+	// ErrRequestFailed is a forge API request that failed. Status is the
+	// HTTP status, or 0 if there was no response.
+	ErrRequestFailed struct {
+		Forge  Kind
+		Path   string
+		Status int
+		Detail string
+		Err    error
+	}
+)
+
+func (e *ErrPRNotFound) Error() string {
+	return fmt.Sprintf("PR #%d not found in %s/%s", e.Number, e.Owner, e.Repo)
+}
+
+func (e *ErrPRCheckoutFailed) Error() string {
+	return fmt.Sprintf("failed to checkout PR #%d: %s", e.Number, e.Detail)
+}
+
+func (e *ErrPRCheckoutFailed) Unwrap() error {
+	return e.Err
+}
+
+func (e *ErrPRPatchFailed) Error() string {
+	return fmt.Sprintf("failed to download the patches for PR #%d: %s", e.Number, e.Detail)
+}
+
+func (e *ErrPRPatchFailed) Unwrap() error {
+	return e.Err
+}
+
+func (e *ErrInvalidURL) Error() string {
+	return fmt.Sprintf("invalid URL %s: %s", e.URL, e.Detail)
+}
+
+func (e *ErrUnknownForge) Error() string {
+	return fmt.Sprintf("unknown forge %q (want github, gitlab, gitea or forgejo)", e.Value)
+}
+
+func (e *ErrRequestFailed) Error() string {
+	return fmt.Sprintf("%s API request %s failed: %s", e.Forge, e.Path, e.Detail)
+}
+
+func (e *ErrRequestFailed) Unwrap() error {
+	return e.Err
+}
+
+func (e *ErrPRNotFound) Is(target error) bool {
+	return target == errs.ErrNotFound
+}
+
+func (e *ErrPRCheckoutFailed) Is(target error) bool {
+	return target == errs.ErrGitHub
+}
+
+func (e *ErrPRPatchFailed) Is(target error) bool {
+	return target == errs.ErrGitHub
+}
+
+func (e *ErrInvalidURL) Is(target error) bool {
+	return target == errs.ErrUsage
+}
+
+func (e *ErrUnknownForge) Is(target error) bool {
+	return target == errs.ErrUsage
+}
+
+func (e *ErrRequestFailed) Is(target error) bool {
+	return target == errs.ErrGitHub
+}
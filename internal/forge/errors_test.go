@@ -0,0 +1,36 @@
+package forge
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/user/git-mfpr/internal/errs"
+)
+
+func TestErrors(t *testing.T) {
+	cause := errors.New("connection refused")
+	tests := []struct {
+		err      error
+		want     string
+		category error
+	}{
+		{&ErrPRNotFound{Number: 1, Owner: "group/sub", Repo: "repo"}, "PR #1 not found in group/sub/repo", errs.ErrNotFound},
+		{&ErrPRCheckoutFailed{Number: 2, Detail: "no ref", Err: cause}, "failed to checkout PR #2: no ref", errs.ErrGitHub},
+		{&ErrInvalidURL{URL: "x", Detail: "not a URL"}, "invalid URL x: not a URL", errs.ErrUsage},
+		{&ErrUnknownForge{Value: "svn"}, `unknown forge "svn" (want github, gitlab, gitea or forgejo)`, errs.ErrUsage},
+		{&ErrRequestFailed{Forge: GitLab, Path: "projects/1", Detail: "connection refused", Err: cause}, "gitlab API request projects/1 failed: connection refused", errs.ErrGitHub},
+	}
+
+	for _, tt := range tests {
+		if got := tt.err.Error(); got != tt.want {
+			t.Errorf("Error() = %q, want %q", got, tt.want)
+		}
+		if !errors.Is(tt.err, tt.category) {
+			t.Errorf("%T is not %v", tt.err, tt.category)
+		}
+	}
+
+	if !errors.Is(&ErrRequestFailed{Err: cause}, cause) || !errors.Is(&ErrPRCheckoutFailed{Err: cause}, cause) {
+		t.Error("errors with a cause should unwrap to it")
+	}
+}
@@ -0,0 +1,169 @@
+// Package forge defines what git-mfpr needs from a code hosting service:
+// reading, creating and closing pull requests (merge requests on GitLab) and
+// their CI checks. Packages github, gitlab and gitea implement it.
+package forge
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Kind names a family of forges that share an API.
+type Kind string
+
+const (
+	GitHub Kind = "github"
+	GitLab Kind = "gitlab"
+	// Gitea covers Forgejo and Codeberg, which kept Gitea's API.
+	Gitea Kind = "gitea"
+)
+
+// ParseKind reads a kind as written in git config mfpr.forge.
+func ParseKind(s string) (Kind, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "github":
+		return GitHub, nil
+	case "gitlab":
+		return GitLab, nil
+	case "gitea", "forgejo":
+		return Gitea, nil
+	}
+	return "", &ErrUnknownForge{Value: s}
+}
+
+// PRInfo describes a pull request, or a GitLab merge request. Owner is the
+// namespace, which on GitLab may be a nested group like group/subgroup.
+type PRInfo struct {
+	Number     int
+	Title      string
+	Author     string
+	HeadBranch string
+	BaseBranch string
+	State      string
+	URL        string
+	HeadRefOID string
+	IsFork     bool
+
+	// Populated by ListPRs only.
+	CreatedAt   time.Time
+	Labels      []string
+	CheckStatus string
+}
+
+// PR states, normalised to GitHub's names.
+const (
+	StateOpen   = "OPEN"
+	StateClosed = "CLOSED"
+	StateMerged = "MERGED"
+)
+
+const (
+	CheckSuccess = "SUCCESS"
+	CheckFailure = "FAILURE"
+	CheckPending = "PENDING"
+)
+
+type CreatePROptions struct {
+	Title string
+	Body  string
+	Base  string
+	Head  string
+}
+
+type ListOptions struct {
+	Base  string
+	State string
+	Limit int
+}
+
+// Forge is a code hosting service that PRs are migrated on.
+type Forge interface {
+	GetPR(ctx context.Context, owner, repo string, number int) (*PRInfo, error)
+	// CheckoutPR creates branch from the PR's head and checks it out.
+	CheckoutPR(ctx context.Context, owner, repo string, number int, branch string) error
+	// GetPRPatch downloads the PR's commits as an mbox patch series.
+	GetPRPatch(ctx context.Context, owner, repo string, number int) ([]byte, error)
+	CreatePR(ctx context.Context, owner, repo string, opts CreatePROptions) (*PRInfo, error)
+	ClosePR(ctx context.Context, owner, repo string, number int, comment string) error
+	CommentPR(ctx context.Context, owner, repo string, number int, body string) error
+	FindPRForBranch(ctx context.Context, owner, repo, branch string) (*PRInfo, error)
+	ListPRs(ctx context.Context, owner, repo string, opts ListOptions) ([]PRInfo, error)
+	GetChecks(ctx context.Context, owner, repo, sha string) (*Checks, error)
+}
+
+// Describer is implemented by forges that can say what their calls would do,
+// as a command to run by hand or the API request they make. Dry runs, and
+// PRs left for the user to create, are described with it.
+type Describer interface {
+	DescribeCheckoutPR(owner, repo string, number int, branch string) string
+	DescribeGetPRPatch(owner, repo string, number int) string
+	DescribeCreatePR(owner, repo string, opts CreatePROptions) string
+	DescribeClosePR(owner, repo string, number int) string
+}
+
+// Check is one CI check on a commit, such as a check run, commit status or
+// pipeline job. State is CheckSuccess, CheckFailure or CheckPending.
+type Check struct {
+	Name  string
+	State string
+	URL   string
+}
+
+// Checks is the CI state of a commit, combining everything reported on it. A
+// commit with no checks yet is pending.
+type Checks struct {
+	SHA    string
+	State  string
+	Checks []Check
+}
+
+// NewChecks combines checks into the state of sha: failed if any failed,
+// pending if any (or none) are still running, and otherwise passed.
+func NewChecks(sha string, checks []Check) *Checks {
+	c := &Checks{SHA: sha, State: CheckSuccess, Checks: checks}
+	switch {
+	case c.Count(CheckFailure) > 0:
+		c.State = CheckFailure
+	case len(checks) == 0 || c.Count(CheckPending) > 0:
+		c.State = CheckPending
+	}
+	return c
+}
+
+// Count returns how many checks are in state.
+func (c *Checks) Count(state string) int {
+	n := 0
+	for _, check := range c.Checks {
+		if check.State == state {
+			n++
+		}
+	}
+	return n
+}
+
+// Failed lists the names of the checks that failed.
+func (c *Checks) Failed() []string {
+	var names []string
+	for _, check := range c.Checks {
+		if check.State == CheckFailure {
+			names = append(names, check.Name)
+		}
+	}
+	return names
+}
+
+func (c *Checks) String() string {
+	if len(c.Checks) == 0 {
+		return "no checks reported yet"
+	}
+	parts := []string{fmt.Sprintf("%d passed", c.Count(CheckSuccess))}
+	if n := c.Count(CheckPending); n > 0 {
+		parts = append(parts, fmt.Sprintf("%d pending", n))
+	}
+	if n := c.Count(CheckFailure); n > 0 {
+		parts = append(parts, fmt.Sprintf("%d failed", n))
+	}
+	return strings.Join(parts, ", ")
+}
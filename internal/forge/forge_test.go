@@ -0,0 +1,89 @@
+package forge
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/user/git-mfpr/internal/errs"
+)
+
+func TestParseKind(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Kind
+		wantErr bool
+	}{
+		{input: "github", want: GitHub},
+		{input: "GitLab", want: GitLab},
+		{input: " gitea\n", want: Gitea},
+		{input: "forgejo", want: Gitea},
+		{input: "bitbucket", wantErr: true},
+		{input: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseKind(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseKind() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, errs.ErrUsage) {
+				t.Errorf("ParseKind() error = %v, want a usage error", err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseKind() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewChecks(t *testing.T) {
+	tests := []struct {
+		name       string
+		checks     []Check
+		wantState  string
+		wantFailed []string
+		wantString string
+	}{
+		{
+			name:       "none yet",
+			wantState:  CheckPending,
+			wantString: "no checks reported yet",
+		},
+		{
+			name:       "passed",
+			checks:     []Check{{Name: "build", State: CheckSuccess}, {Name: "test", State: CheckSuccess}},
+			wantState:  CheckSuccess,
+			wantString: "2 passed",
+		},
+		{
+			name:       "running",
+			checks:     []Check{{Name: "build", State: CheckSuccess}, {Name: "test", State: CheckPending}},
+			wantState:  CheckPending,
+			wantString: "1 passed, 1 pending",
+		},
+		{
+			name:       "failed while others run",
+			checks:     []Check{{Name: "lint", State: CheckFailure}, {Name: "test", State: CheckPending}},
+			wantState:  CheckFailure,
+			wantFailed: []string{"lint"},
+			wantString: "0 passed, 1 pending, 1 failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewChecks("abc123", tt.checks)
+			if got.SHA != "abc123" || got.State != tt.wantState {
+				t.Errorf("NewChecks() = %s %s, want abc123 %s", got.SHA, got.State, tt.wantState)
+			}
+			if !reflect.DeepEqual(got.Failed(), tt.wantFailed) {
+				t.Errorf("Failed() = %v, want %v", got.Failed(), tt.wantFailed)
+			}
+			if got.String() != tt.wantString {
+				t.Errorf("String() = %q, want %q", got.String(), tt.wantString)
+			}
+		})
+	}
+}
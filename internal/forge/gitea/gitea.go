@@ -0,0 +1,321 @@
+// Package gitea implements forge.Forge for Gitea and Forgejo (including
+// Codeberg) through the REST API (v1).
+package gitea
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/user/git-mfpr/internal/forge"
+	"github.com/user/git-mfpr/internal/runner"
+)
+
+// DefaultBaseURL is the instance used unless WithBaseURL says otherwise.
+const DefaultBaseURL = "https://codeberg.org"
+
+// listLimit is the page size Gitea allows by default.
+const listLimit = 50
+
+type Client struct {
+	api     forge.API
+	baseURL string
+	host    string
+	runner  runner.Runner
+	dir     string
+}
+
+type Option func(*Client)
+
+// WithBaseURL talks to the instance at url, such as
+// https://gitea.example.com, instead of Codeberg.
+func WithBaseURL(url string) Option {
+	return func(c *Client) {
+		c.baseURL = strings.TrimRight(url, "/")
+	}
+}
+
+// WithToken authenticates with an access token. Without it the token comes
+// from GITEA_TOKEN or FORGEJO_TOKEN.
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.api.Header.Set("Authorization", "token "+token)
+	}
+}
+
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *Client) {
+		c.api.Client = client
+	}
+}
+
+// WithTimeout bounds each API request and each git command of CheckoutPR.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.api.Timeout = timeout
+	}
+}
+
+// WithRunner runs the git commands of CheckoutPR through r.
+func WithRunner(r runner.Runner) Option {
+	return func(c *Client) {
+		c.runner = r
+	}
+}
+
+// WithDir runs the git commands of CheckoutPR in dir.
+func WithDir(dir string) Option {
+	return func(c *Client) {
+		c.dir = dir
+	}
+}
+
+func New() forge.Forge {
+	return NewWithOptions()
+}
+
+func NewWithOptions(opts ...Option) forge.Forge {
+	client := &Client{
+		api: forge.API{
+			Forge:   forge.Gitea,
+			Header:  http.Header{},
+			Client:  http.DefaultClient,
+			Timeout: 30 * time.Second,
+		},
+		baseURL: DefaultBaseURL,
+		runner:  runner.New(),
+	}
+	for _, key := range []string{"GITEA_TOKEN", "FORGEJO_TOKEN"} {
+		if token := os.Getenv(key); token != "" {
+			client.api.Header.Set("Authorization", "token "+token)
+			break
+		}
+	}
+
+	for _, opt := range opts {
+		opt(client)
+	}
+
+	client.api.BaseURL = client.baseURL + "/api/v1"
+	if u, err := url.Parse(client.baseURL); err == nil {
+		client.host = u.Host
+	}
+	return client
+}
+
+type branch struct {
+	Ref  string `json:"ref"`
+	SHA  string `json:"sha"`
+	Repo *struct {
+		ID int `json:"id"`
+	} `json:"repo"`
+}
+
+type pullRequest struct {
+	Number    int       `json:"number"`
+	Title     string    `json:"title"`
+	State     string    `json:"state"`
+	Merged    bool      `json:"merged"`
+	HTMLURL   string    `json:"html_url"`
+	CreatedAt time.Time `json:"created_at"`
+	User      struct {
+		Login string `json:"login"`
+	} `json:"user"`
+	Head   branch `json:"head"`
+	Base   branch `json:"base"`
+	Labels []struct {
+		Name string `json:"name"`
+	} `json:"labels"`
+}
+
+func (p *pullRequest) toPRInfo() *forge.PRInfo {
+	pr := &forge.PRInfo{
+		Number:     p.Number,
+		Title:      p.Title,
+		Author:     p.User.Login,
+		HeadBranch: p.Head.Ref,
+		BaseBranch: p.Base.Ref,
+		URL:        p.HTMLURL,
+		HeadRefOID: p.Head.SHA,
+		// A PR whose fork was deleted has no head repository.
+		IsFork:    p.Head.Repo == nil || p.Base.Repo == nil || p.Head.Repo.ID != p.Base.Repo.ID,
+		CreatedAt: p.CreatedAt,
+	}
+	switch {
+	case p.Merged:
+		pr.State = forge.StateMerged
+	case p.State == "open":
+		pr.State = forge.StateOpen
+	default:
+		pr.State = forge.StateClosed
+	}
+	for _, label := range p.Labels {
+		pr.Labels = append(pr.Labels, label.Name)
+	}
+	return pr
+}
+
+func pullPath(owner, repo string, number int) string {
+	return fmt.Sprintf("repos/%s/%s/pulls/%d", owner, repo, number)
+}
+
+func (c *Client) GetPR(ctx context.Context, owner, repo string, number int) (*forge.PRInfo, error) {
+	var pr pullRequest
+	if err := c.api.Do(ctx, http.MethodGet, pullPath(owner, repo, number), nil, &pr); err != nil {
+		if forge.IsNotFound(err) {
+			return nil, &forge.ErrPRNotFound{Number: number, Owner: owner, Repo: repo}
+		}
+		return nil, err
+	}
+	return pr.toPRInfo(), nil
+}
+
+// CheckoutPR fetches refs/pull/N/head, which Gitea keeps in the base
+// repository even for PRs from forks.
+func (c *Client) CheckoutPR(ctx context.Context, owner, repo string, number int, branch string) error {
+	remote := &forge.Remote{Host: c.host, BaseURL: c.baseURL, Owner: owner, Repo: repo}
+	return forge.CheckoutRef(ctx, c.runner, c.dir, c.api.Timeout, remote, number, fmt.Sprintf("refs/pull/%d/head", number), branch)
+}
+
+func (c *Client) GetPRPatch(ctx context.Context, owner, repo string, number int) ([]byte, error) {
+	return c.api.Raw(ctx, http.MethodGet, pullPath(owner, repo, number)+".patch", nil)
+}
+
+func (c *Client) CreatePR(ctx context.Context, owner, repo string, opts forge.CreatePROptions) (*forge.PRInfo, error) {
+	in := createFields(opts)
+	var pr pullRequest
+	if err := c.api.Do(ctx, http.MethodPost, fmt.Sprintf("repos/%s/%s/pulls", owner, repo), in, &pr); err != nil {
+		return nil, err
+	}
+	return pr.toPRInfo(), nil
+}
+
+func (c *Client) ClosePR(ctx context.Context, owner, repo string, number int, comment string) error {
+	if comment != "" {
+		if err := c.CommentPR(ctx, owner, repo, number, comment); err != nil {
+			return err
+		}
+	}
+	return c.api.Do(ctx, http.MethodPatch, pullPath(owner, repo, number), map[string]string{"state": "closed"}, nil)
+}
+
+// CommentPR comments through the issues API, which Gitea shares between
+// issues and PRs.
+func (c *Client) CommentPR(ctx context.Context, owner, repo string, number int, body string) error {
+	path := fmt.Sprintf("repos/%s/%s/issues/%d/comments", owner, repo, number)
+	return c.api.Do(ctx, http.MethodPost, path, map[string]string{"body": body}, nil)
+}
+
+// FindPRForBranch looks through the most recent PRs, as the API cannot
+// filter them by head branch.
+func (c *Client) FindPRForBranch(ctx context.Context, owner, repo, branch string) (*forge.PRInfo, error) {
+	prs, err := c.listPulls(ctx, owner, repo, "all", listLimit)
+	if err != nil {
+		return nil, err
+	}
+	for i := range prs {
+		if prs[i].Head.Ref == branch && !prs[i].toPRInfo().IsFork {
+			return prs[i].toPRInfo(), nil
+		}
+	}
+	return nil, nil
+}
+
+func (c *Client) ListPRs(ctx context.Context, owner, repo string, opts forge.ListOptions) ([]forge.PRInfo, error) {
+	state := opts.State
+	if state == "" || state == "merged" {
+		state = "all"
+	}
+	limit := opts.Limit
+	if limit <= 0 || limit > listLimit {
+		limit = listLimit
+	}
+
+	pulls, err := c.listPulls(ctx, owner, repo, state, limit)
+	if err != nil {
+		return nil, err
+	}
+	prs := make([]forge.PRInfo, 0, len(pulls))
+	for i := range pulls {
+		pr := pulls[i].toPRInfo()
+		if opts.Base != "" && pr.BaseBranch != opts.Base {
+			continue
+		}
+		if opts.State == "merged" && pr.State != forge.StateMerged {
+			continue
+		}
+		prs = append(prs, *pr)
+	}
+	return prs, nil
+}
+
+func (c *Client) listPulls(ctx context.Context, owner, repo, state string, limit int) ([]pullRequest, error) {
+	query := url.Values{"state": {state}, "limit": {strconv.Itoa(limit)}}
+	var pulls []pullRequest
+	if err := c.api.Do(ctx, http.MethodGet, fmt.Sprintf("repos/%s/%s/pulls?%s", owner, repo, query.Encode()), nil, &pulls); err != nil {
+		return nil, err
+	}
+	return pulls, nil
+}
+
+// GetChecks reports the commit statuses on sha, which is where Gitea and
+// Forgejo Actions and external CI report.
+func (c *Client) GetChecks(ctx context.Context, owner, repo, sha string) (*forge.Checks, error) {
+	var combined struct {
+		Statuses []struct {
+			Context   string `json:"context"`
+			Status    string `json:"status"`
+			TargetURL string `json:"target_url"`
+		} `json:"statuses"`
+	}
+	if err := c.api.Do(ctx, http.MethodGet, fmt.Sprintf("repos/%s/%s/commits/%s/status", owner, repo, sha), nil, &combined); err != nil {
+		return nil, err
+	}
+
+	checks := make([]forge.Check, 0, len(combined.Statuses))
+	for _, s := range combined.Statuses {
+		check := forge.Check{Name: s.Context, State: forge.CheckPending, URL: s.TargetURL}
+		switch s.Status {
+		case "success", "warning":
+			check.State = forge.CheckSuccess
+		case "error", "failure":
+			check.State = forge.CheckFailure
+		}
+		checks = append(checks, check)
+	}
+	return forge.NewChecks(sha, checks), nil
+}
+
+func (c *Client) DescribeCheckoutPR(owner, repo string, number int, branch string) string {
+	remote := &forge.Remote{Host: c.host, BaseURL: c.baseURL, Owner: owner, Repo: repo}
+	return forge.DescribeCheckoutRef(remote, fmt.Sprintf("refs/pull/%d/head", number), branch)
+}
+
+func (c *Client) DescribeGetPRPatch(owner, repo string, number int) string {
+	return c.api.Describe(http.MethodGet, pullPath(owner, repo, number)+".patch", nil) + " | git am"
+}
+
+func (c *Client) DescribeCreatePR(owner, repo string, opts forge.CreatePROptions) string {
+	return c.api.Describe(http.MethodPost, fmt.Sprintf("repos/%s/%s/pulls", owner, repo), createFields(opts))
+}
+
+// DescribeClosePR leaves out the comment linking the replacement, which
+// ClosePR posts first.
+func (c *Client) DescribeClosePR(owner, repo string, number int) string {
+	return c.api.Describe(http.MethodPatch, pullPath(owner, repo, number), map[string]string{"state": "closed"})
+}
+
+// createFields is the request body that creates a PR for opts.
+func createFields(opts forge.CreatePROptions) map[string]string {
+	return map[string]string{
+		"title": opts.Title,
+		"body":  opts.Body,
+		"head":  opts.Head,
+		"base":  opts.Base,
+	}
+}
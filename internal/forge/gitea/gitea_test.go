@@ -0,0 +1,312 @@
+package gitea
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/user/git-mfpr/internal/forge"
+	"github.com/user/git-mfpr/internal/runner"
+)
+
+type request struct {
+	Route string
+	Body  string
+}
+
+// standIn is a local Gitea that answers routes ("METHOD /request-uri") with
+// canned JSON and 404s everything else.
+type standIn struct {
+	t        *testing.T
+	routes   map[string]string
+	requests []request
+}
+
+func newStandIn(t *testing.T, routes map[string]string) (*standIn, forge.Forge) {
+	t.Helper()
+	s := &standIn{t: t, routes: routes}
+	server := httptest.NewServer(s)
+	t.Cleanup(server.Close)
+	return s, NewWithOptions(WithBaseURL(server.URL), WithToken("secret"), WithTimeout(5*time.Second))
+}
+
+func (s *standIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if got := r.Header.Get("Authorization"); got != "token secret" {
+		s.t.Errorf("Authorization = %q, want the configured token", got)
+	}
+	route := r.Method + " " + r.URL.RequestURI()
+	body, _ := io.ReadAll(r.Body)
+	s.requests = append(s.requests, request{Route: route, Body: string(body)})
+
+	response, ok := s.routes[route]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"message":"The target couldn't be found.","url":"https://codeberg.org/api/swagger"}`))
+		return
+	}
+	w.Write([]byte(response))
+}
+
+const pullsPath = "/api/v1/repos/owner/repo/pulls"
+
+func TestClient_GetPR(t *testing.T) {
+	pull, err := os.ReadFile("testdata/pull_request.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, client := newStandIn(t, map[string]string{"GET " + pullsPath + "/42": string(pull)})
+
+	pr, err := client.GetPR(context.Background(), "owner", "repo", 42)
+	if err != nil {
+		t.Fatalf("GetPR() error = %v", err)
+	}
+	want := &forge.PRInfo{
+		Number:     42,
+		Title:      "Add widgets",
+		Author:     "contributor",
+		HeadBranch: "widgets",
+		BaseBranch: "main",
+		State:      forge.StateOpen,
+		URL:        "https://codeberg.org/owner/repo/pulls/42",
+		HeadRefOID: "abc123",
+		IsFork:     true,
+		CreatedAt:  time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+		Labels:     []string{"feature"},
+	}
+	if !reflect.DeepEqual(pr, want) {
+		t.Errorf("GetPR() = %+v, want %+v", pr, want)
+	}
+}
+
+func TestClient_GetPR_States(t *testing.T) {
+	tests := []struct {
+		name   string
+		pull   string
+		want   string
+		isFork bool
+	}{
+		{name: "open", pull: `{"number":1,"state":"open","head":{"repo":{"id":3}},"base":{"repo":{"id":3}}}`, want: forge.StateOpen},
+		{name: "merged", pull: `{"number":1,"state":"closed","merged":true,"head":{"repo":{"id":3}},"base":{"repo":{"id":3}}}`, want: forge.StateMerged},
+		{name: "closed", pull: `{"number":1,"state":"closed","head":{"repo":{"id":3}},"base":{"repo":{"id":3}}}`, want: forge.StateClosed},
+		{name: "deleted fork", pull: `{"number":1,"state":"open","head":{"repo":null},"base":{"repo":{"id":3}}}`, want: forge.StateOpen, isFork: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, client := newStandIn(t, map[string]string{"GET " + pullsPath + "/1": tt.pull})
+			pr, err := client.GetPR(context.Background(), "owner", "repo", 1)
+			if err != nil {
+				t.Fatalf("GetPR() error = %v", err)
+			}
+			if pr.State != tt.want || pr.IsFork != tt.isFork {
+				t.Errorf("GetPR() state %s, fork %v; want %s, %v", pr.State, pr.IsFork, tt.want, tt.isFork)
+			}
+		})
+	}
+}
+
+func TestClient_GetPR_NotFound(t *testing.T) {
+	_, client := newStandIn(t, nil)
+
+	_, err := client.GetPR(context.Background(), "owner", "repo", 9)
+	var notFound *forge.ErrPRNotFound
+	if !errors.As(err, &notFound) || notFound.Number != 9 {
+		t.Errorf("GetPR() error = %v, want ErrPRNotFound for #9", err)
+	}
+}
+
+func TestClient_CreatePR(t *testing.T) {
+	s, client := newStandIn(t, map[string]string{
+		"POST " + pullsPath: `{"number":43,"state":"open","html_url":"https://codeberg.org/owner/repo/pulls/43",
+			"head":{"ref":"mfpr-42","repo":{"id":3}},"base":{"ref":"main","repo":{"id":3}}}`,
+	})
+
+	pr, err := client.CreatePR(context.Background(), "owner", "repo", forge.CreatePROptions{
+		Title: "Add widgets", Body: "Migrated from #42", Base: "main", Head: "mfpr-42",
+	})
+	if err != nil {
+		t.Fatalf("CreatePR() error = %v", err)
+	}
+	if pr.Number != 43 || pr.URL != "https://codeberg.org/owner/repo/pulls/43" || pr.IsFork {
+		t.Errorf("CreatePR() = %+v, want #43", pr)
+	}
+	want := `{"base":"main","body":"Migrated from #42","head":"mfpr-42","title":"Add widgets"}`
+	if s.requests[0].Body != want {
+		t.Errorf("CreatePR() sent %s, want %s", s.requests[0].Body, want)
+	}
+}
+
+func TestClient_ClosePR(t *testing.T) {
+	s, client := newStandIn(t, map[string]string{
+		"POST /api/v1/repos/owner/repo/issues/42/comments": `{"id":1}`,
+		"PATCH " + pullsPath + "/42":                       `{"number":42,"state":"closed"}`,
+	})
+
+	if err := client.ClosePR(context.Background(), "owner", "repo", 42, "Moved to #43"); err != nil {
+		t.Fatalf("ClosePR() error = %v", err)
+	}
+	want := []request{
+		{Route: "POST /api/v1/repos/owner/repo/issues/42/comments", Body: `{"body":"Moved to #43"}`},
+		{Route: "PATCH " + pullsPath + "/42", Body: `{"state":"closed"}`},
+	}
+	if !reflect.DeepEqual(s.requests, want) {
+		t.Errorf("ClosePR() sent %+v, want %+v", s.requests, want)
+	}
+}
+
+const pulls = `[
+	{"number":3,"state":"open","head":{"ref":"mfpr-1","repo":{"id":9}},"base":{"ref":"main","repo":{"id":3}}},
+	{"number":2,"state":"closed","merged":true,"head":{"ref":"mfpr-1","repo":{"id":3}},"base":{"ref":"main","repo":{"id":3}}},
+	{"number":1,"state":"open","head":{"ref":"docs","repo":{"id":3}},"base":{"ref":"release","repo":{"id":3}}}
+]`
+
+func TestClient_ListPRs(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  forge.ListOptions
+		route string
+		want  []int
+	}{
+		{name: "default", route: "GET " + pullsPath + "?limit=50&state=all", want: []int{3, 2, 1}},
+		{name: "base", opts: forge.ListOptions{State: "open", Base: "main", Limit: 10},
+			route: "GET " + pullsPath + "?limit=10&state=open", want: []int{3, 2}},
+		{name: "merged", opts: forge.ListOptions{State: "merged", Limit: 500},
+			route: "GET " + pullsPath + "?limit=50&state=all", want: []int{2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, client := newStandIn(t, map[string]string{tt.route: pulls})
+			prs, err := client.ListPRs(context.Background(), "owner", "repo", tt.opts)
+			if err != nil {
+				t.Fatalf("ListPRs() error = %v", err)
+			}
+			var got []int
+			for _, pr := range prs {
+				got = append(got, pr.Number)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ListPRs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_FindPRForBranch(t *testing.T) {
+	_, client := newStandIn(t, map[string]string{"GET " + pullsPath + "?limit=50&state=all": pulls})
+
+	// #3 has the same branch name but comes from a fork.
+	pr, err := client.FindPRForBranch(context.Background(), "owner", "repo", "mfpr-1")
+	if err != nil || pr == nil || pr.Number != 2 {
+		t.Errorf("FindPRForBranch(mfpr-1) = %+v, %v; want #2", pr, err)
+	}
+	pr, err = client.FindPRForBranch(context.Background(), "owner", "repo", "other")
+	if err != nil || pr != nil {
+		t.Errorf("FindPRForBranch(other) = %+v, %v; want none", pr, err)
+	}
+}
+
+func TestClient_GetChecks(t *testing.T) {
+	_, client := newStandIn(t, map[string]string{
+		"GET /api/v1/repos/owner/repo/commits/abc123/status": `{"state":"failure","statuses":[
+			{"context":"ci/build","status":"success","target_url":"https://ci/1"},
+			{"context":"ci/lint","status":"warning"},
+			{"context":"ci/test","status":"failure"},
+			{"context":"ci/e2e","status":"pending"}
+		]}`,
+	})
+
+	checks, err := client.GetChecks(context.Background(), "owner", "repo", "abc123")
+	if err != nil {
+		t.Fatalf("GetChecks() error = %v", err)
+	}
+	want := []forge.Check{
+		{Name: "ci/build", State: forge.CheckSuccess, URL: "https://ci/1"},
+		{Name: "ci/lint", State: forge.CheckSuccess},
+		{Name: "ci/test", State: forge.CheckFailure},
+		{Name: "ci/e2e", State: forge.CheckPending},
+	}
+	if !reflect.DeepEqual(checks.Checks, want) || checks.State != forge.CheckFailure {
+		t.Errorf("GetChecks() = %s %+v, want failure %+v", checks.State, checks.Checks, want)
+	}
+}
+
+func TestClient_GetPRPatch(t *testing.T) {
+	_, client := newStandIn(t, map[string]string{
+		"GET " + pullsPath + "/42.patch": "From abc123 Mon Sep 17 00:00:00 2001\n",
+	})
+
+	patch, err := client.GetPRPatch(context.Background(), "owner", "repo", 42)
+	if err != nil || string(patch) != "From abc123 Mon Sep 17 00:00:00 2001\n" {
+		t.Errorf("GetPRPatch() = %q, %v; want the patch", patch, err)
+	}
+}
+
+func TestClient_CheckoutPR(t *testing.T) {
+	fake := runner.NewFake(
+		runner.Entry{Command: "git remote get-url origin", Dir: "/work", Stdout: "git@codeberg.org:me/repo.git\n"},
+		runner.Entry{Command: "git fetch https://codeberg.org/owner/repo.git refs/pull/42/head:refs/heads/pr-42", Dir: "/work"},
+		runner.Entry{Command: "git checkout pr-42", Dir: "/work"},
+	)
+	client := NewWithOptions(WithRunner(fake), WithDir("/work"))
+
+	if err := client.CheckoutPR(context.Background(), "owner", "repo", 42, "pr-42"); err != nil {
+		t.Errorf("CheckoutPR() error = %v", err)
+	}
+}
+
+func TestNewWithOptions_TokenFromEnvironment(t *testing.T) {
+	tests := []struct {
+		gitea, forgejo string
+		want           string
+	}{
+		{gitea: "a", forgejo: "b", want: "token a"},
+		{forgejo: "b", want: "token b"},
+		{want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Setenv("GITEA_TOKEN", tt.gitea)
+		t.Setenv("FORGEJO_TOKEN", tt.forgejo)
+		client := NewWithOptions().(*Client)
+		if got := client.api.Header.Get("Authorization"); got != tt.want {
+			t.Errorf("Authorization = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestClient_Describe(t *testing.T) {
+	client := NewWithOptions().(forge.Describer)
+
+	tests := []struct {
+		got, want string
+	}{
+		{
+			client.DescribeCheckoutPR("owner", "repo", 42, "pr-42"),
+			"git fetch https://codeberg.org/owner/repo.git refs/pull/42/head:refs/heads/pr-42 && git checkout pr-42",
+		},
+		{
+			client.DescribeGetPRPatch("owner", "repo", 42),
+			"GET https://codeberg.org/api/v1/repos/owner/repo/pulls/42.patch | git am",
+		},
+		{
+			client.DescribeCreatePR("owner", "repo", forge.CreatePROptions{Title: "Fix", Body: "Moved", Base: "main", Head: "pr-42"}),
+			`POST https://codeberg.org/api/v1/repos/owner/repo/pulls base="main" body="Moved" head="pr-42" title="Fix"`,
+		},
+		{
+			client.DescribeClosePR("owner", "repo", 42),
+			`PATCH https://codeberg.org/api/v1/repos/owner/repo/pulls/42 state="closed"`,
+		},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("got %q, want %q", tt.got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,341 @@
+// Package gitlab implements forge.Forge for GitLab merge requests through
+// the REST API (v4).
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/user/git-mfpr/internal/forge"
+	"github.com/user/git-mfpr/internal/runner"
+)
+
+// DefaultBaseURL is the GitLab instance used unless WithBaseURL says
+// otherwise.
+const DefaultBaseURL = "https://gitlab.com"
+
+type Client struct {
+	api     forge.API
+	baseURL string
+	host    string
+	runner  runner.Runner
+	dir     string
+}
+
+type Option func(*Client)
+
+// WithBaseURL talks to the GitLab instance at url, such as
+// https://gitlab.example.com, instead of gitlab.com.
+func WithBaseURL(url string) Option {
+	return func(c *Client) {
+		c.baseURL = strings.TrimRight(url, "/")
+	}
+}
+
+// WithToken authenticates with a personal, project or group access token.
+// Without it the token comes from GITLAB_TOKEN.
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.api.Header.Set("PRIVATE-TOKEN", token)
+	}
+}
+
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *Client) {
+		c.api.Client = client
+	}
+}
+
+// WithTimeout bounds each API request and each git command of CheckoutPR.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.api.Timeout = timeout
+	}
+}
+
+// WithRunner runs the git commands of CheckoutPR through r.
+func WithRunner(r runner.Runner) Option {
+	return func(c *Client) {
+		c.runner = r
+	}
+}
+
+// WithDir runs the git commands of CheckoutPR in dir.
+func WithDir(dir string) Option {
+	return func(c *Client) {
+		c.dir = dir
+	}
+}
+
+func New() forge.Forge {
+	return NewWithOptions()
+}
+
+func NewWithOptions(opts ...Option) forge.Forge {
+	client := &Client{
+		api: forge.API{
+			Forge:   forge.GitLab,
+			Header:  http.Header{},
+			Client:  http.DefaultClient,
+			Timeout: 30 * time.Second,
+		},
+		baseURL: DefaultBaseURL,
+		runner:  runner.New(),
+	}
+	if token := os.Getenv("GITLAB_TOKEN"); token != "" {
+		client.api.Header.Set("PRIVATE-TOKEN", token)
+	}
+
+	for _, opt := range opts {
+		opt(client)
+	}
+
+	client.api.BaseURL = client.baseURL + "/api/v4"
+	if u, err := url.Parse(client.baseURL); err == nil {
+		client.host = u.Host
+	}
+	return client
+}
+
+// project is the API path of owner/repo, which GitLab takes URL-encoded in
+// place of a project ID.
+func project(owner, repo string) string {
+	return "projects/" + url.PathEscape(owner+"/"+repo)
+}
+
+type mergeRequest struct {
+	IID             int       `json:"iid"`
+	Title           string    `json:"title"`
+	State           string    `json:"state"`
+	SourceBranch    string    `json:"source_branch"`
+	TargetBranch    string    `json:"target_branch"`
+	SourceProjectID int       `json:"source_project_id"`
+	TargetProjectID int       `json:"target_project_id"`
+	SHA             string    `json:"sha"`
+	WebURL          string    `json:"web_url"`
+	CreatedAt       time.Time `json:"created_at"`
+	Labels          []string  `json:"labels"`
+	Author          struct {
+		Username string `json:"username"`
+	} `json:"author"`
+	HeadPipeline *struct {
+		Status string `json:"status"`
+	} `json:"head_pipeline"`
+	DiffRefs struct {
+		BaseSHA string `json:"base_sha"`
+	} `json:"diff_refs"`
+}
+
+func (mr *mergeRequest) toPRInfo() *forge.PRInfo {
+	pr := &forge.PRInfo{
+		Number:     mr.IID,
+		Title:      mr.Title,
+		Author:     mr.Author.Username,
+		HeadBranch: mr.SourceBranch,
+		BaseBranch: mr.TargetBranch,
+		URL:        mr.WebURL,
+		HeadRefOID: mr.SHA,
+		IsFork:     mr.SourceProjectID != mr.TargetProjectID,
+		CreatedAt:  mr.CreatedAt,
+		Labels:     mr.Labels,
+	}
+	switch mr.State {
+	case "opened":
+		pr.State = forge.StateOpen
+	case "merged":
+		pr.State = forge.StateMerged
+	default:
+		pr.State = forge.StateClosed
+	}
+	if mr.HeadPipeline != nil {
+		pr.CheckStatus = checkState(mr.HeadPipeline.Status, false)
+	}
+	return pr
+}
+
+// checkState maps a pipeline or job status to a forge check state. Jobs
+// allowed to fail never fail the commit.
+func checkState(status string, allowFailure bool) string {
+	switch status {
+	case "success", "skipped", "manual":
+		return forge.CheckSuccess
+	case "failed", "canceled":
+		if allowFailure {
+			return forge.CheckSuccess
+		}
+		return forge.CheckFailure
+	default:
+		return forge.CheckPending
+	}
+}
+
+func (c *Client) mergeRequestPath(owner, repo string, number int) string {
+	return fmt.Sprintf("%s/merge_requests/%d", project(owner, repo), number)
+}
+
+func (c *Client) GetPR(ctx context.Context, owner, repo string, number int) (*forge.PRInfo, error) {
+	var mr mergeRequest
+	if err := c.api.Do(ctx, http.MethodGet, c.mergeRequestPath(owner, repo, number), nil, &mr); err != nil {
+		if forge.IsNotFound(err) {
+			return nil, &forge.ErrPRNotFound{Number: number, Owner: owner, Repo: repo}
+		}
+		return nil, err
+	}
+	return mr.toPRInfo(), nil
+}
+
+// CheckoutPR fetches refs/merge-requests/N/head, which GitLab keeps in the
+// target project even for merge requests from forks.
+func (c *Client) CheckoutPR(ctx context.Context, owner, repo string, number int, branch string) error {
+	return forge.CheckoutRef(ctx, c.runner, c.dir, c.api.Timeout, c.remote(owner, repo), number, headRef(number), branch)
+}
+
+// GetPRPatch fetches the merge request's head with git and formats its commits
+// since the merge base. The API has no patch series, and the web .patch
+// ignores API tokens, so would fail for private projects.
+func (c *Client) GetPRPatch(ctx context.Context, owner, repo string, number int) ([]byte, error) {
+	var mr mergeRequest
+	if err := c.api.Do(ctx, http.MethodGet, c.mergeRequestPath(owner, repo, number), nil, &mr); err != nil {
+		if forge.IsNotFound(err) {
+			return nil, &forge.ErrPRNotFound{Number: number, Owner: owner, Repo: repo}
+		}
+		return nil, err
+	}
+	return forge.FetchPatches(ctx, c.runner, c.dir, c.api.Timeout, c.remote(owner, repo), number, headRef(number), mr.DiffRefs.BaseSHA, mr.SHA)
+}
+
+func (c *Client) remote(owner, repo string) *forge.Remote {
+	return &forge.Remote{Host: c.host, BaseURL: c.baseURL, Owner: owner, Repo: repo}
+}
+
+func headRef(number int) string {
+	return fmt.Sprintf("refs/merge-requests/%d/head", number)
+}
+
+func (c *Client) CreatePR(ctx context.Context, owner, repo string, opts forge.CreatePROptions) (*forge.PRInfo, error) {
+	in := createFields(opts)
+	var mr mergeRequest
+	if err := c.api.Do(ctx, http.MethodPost, project(owner, repo)+"/merge_requests", in, &mr); err != nil {
+		return nil, err
+	}
+	return mr.toPRInfo(), nil
+}
+
+func (c *Client) ClosePR(ctx context.Context, owner, repo string, number int, comment string) error {
+	if comment != "" {
+		if err := c.CommentPR(ctx, owner, repo, number, comment); err != nil {
+			return err
+		}
+	}
+	return c.api.Do(ctx, http.MethodPut, c.mergeRequestPath(owner, repo, number), map[string]string{"state_event": "close"}, nil)
+}
+
+func (c *Client) CommentPR(ctx context.Context, owner, repo string, number int, body string) error {
+	return c.api.Do(ctx, http.MethodPost, c.mergeRequestPath(owner, repo, number)+"/notes", map[string]string{"body": body}, nil)
+}
+
+func (c *Client) FindPRForBranch(ctx context.Context, owner, repo, branch string) (*forge.PRInfo, error) {
+	query := url.Values{"source_branch": {branch}, "state": {"all"}, "per_page": {"1"}}
+	prs, err := c.listMergeRequests(ctx, owner, repo, query)
+	if err != nil || len(prs) == 0 {
+		return nil, err
+	}
+	return &prs[0], nil
+}
+
+func (c *Client) ListPRs(ctx context.Context, owner, repo string, opts forge.ListOptions) ([]forge.PRInfo, error) {
+	query := url.Values{"per_page": {"100"}}
+	switch opts.State {
+	case "":
+	case "open":
+		query.Set("state", "opened")
+	default:
+		query.Set("state", opts.State)
+	}
+	if opts.Base != "" {
+		query.Set("target_branch", opts.Base)
+	}
+	if opts.Limit > 0 && opts.Limit < 100 {
+		query.Set("per_page", strconv.Itoa(opts.Limit))
+	}
+	return c.listMergeRequests(ctx, owner, repo, query)
+}
+
+func (c *Client) listMergeRequests(ctx context.Context, owner, repo string, query url.Values) ([]forge.PRInfo, error) {
+	var mrs []mergeRequest
+	if err := c.api.Do(ctx, http.MethodGet, project(owner, repo)+"/merge_requests?"+query.Encode(), nil, &mrs); err != nil {
+		return nil, err
+	}
+	prs := make([]forge.PRInfo, 0, len(mrs))
+	for i := range mrs {
+		prs = append(prs, *mrs[i].toPRInfo())
+	}
+	return prs, nil
+}
+
+// GetChecks reports the jobs of the pipelines that ran on sha, keeping only
+// the latest run of a retried job.
+func (c *Client) GetChecks(ctx context.Context, owner, repo, sha string) (*forge.Checks, error) {
+	var statuses []struct {
+		ID           int    `json:"id"`
+		Name         string `json:"name"`
+		Status       string `json:"status"`
+		TargetURL    string `json:"target_url"`
+		AllowFailure bool   `json:"allow_failure"`
+	}
+	path := fmt.Sprintf("%s/repository/commits/%s/statuses?per_page=100", project(owner, repo), sha)
+	if err := c.api.Do(ctx, http.MethodGet, path, nil, &statuses); err != nil {
+		return nil, err
+	}
+
+	type seen struct{ index, id int }
+	latest := map[string]seen{}
+	var checks []forge.Check
+	for _, s := range statuses {
+		check := forge.Check{Name: s.Name, State: checkState(s.Status, s.AllowFailure), URL: s.TargetURL}
+		if prev, ok := latest[s.Name]; ok {
+			if s.ID > prev.id {
+				checks[prev.index] = check
+				latest[s.Name] = seen{prev.index, s.ID}
+			}
+			continue
+		}
+		latest[s.Name] = seen{len(checks), s.ID}
+		checks = append(checks, check)
+	}
+	return forge.NewChecks(sha, checks), nil
+}
+
+func (c *Client) DescribeCheckoutPR(owner, repo string, number int, branch string) string {
+	return forge.DescribeCheckoutRef(c.remote(owner, repo), headRef(number), branch)
+}
+
+func (c *Client) DescribeGetPRPatch(owner, repo string, number int) string {
+	return forge.DescribeFetchPatches(c.remote(owner, repo), headRef(number))
+}
+
+func (c *Client) DescribeCreatePR(owner, repo string, opts forge.CreatePROptions) string {
+	return c.api.Describe(http.MethodPost, project(owner, repo)+"/merge_requests", createFields(opts))
+}
+
+// DescribeClosePR leaves out the note linking the replacement, which ClosePR
+// posts first.
+func (c *Client) DescribeClosePR(owner, repo string, number int) string {
+	return c.api.Describe(http.MethodPut, c.mergeRequestPath(owner, repo, number), map[string]string{"state_event": "close"})
+}
+
+// createFields is the request body that creates a PR for opts.
+func createFields(opts forge.CreatePROptions) map[string]string {
+	return map[string]string{
+		"title":         opts.Title,
+		"description":   opts.Body,
+		"source_branch": opts.Head,
+		"target_branch": opts.Base,
+	}
+}
@@ -0,0 +1,329 @@
+package gitlab
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/user/git-mfpr/internal/forge"
+	"github.com/user/git-mfpr/internal/runner"
+)
+
+type request struct {
+	Route string
+	Body  string
+}
+
+// standIn is a local GitLab that answers routes ("METHOD /request-uri") with
+// canned JSON and 404s everything else.
+type standIn struct {
+	t        *testing.T
+	routes   map[string]string
+	requests []request
+}
+
+func newStandIn(t *testing.T, routes map[string]string) (*standIn, forge.Forge) {
+	t.Helper()
+	s := &standIn{t: t, routes: routes}
+	server := httptest.NewServer(s)
+	t.Cleanup(server.Close)
+	return s, NewWithOptions(WithBaseURL(server.URL+"/"), WithToken("secret"), WithTimeout(5*time.Second))
+}
+
+func (s *standIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if got := r.Header.Get("PRIVATE-TOKEN"); got != "secret" {
+		s.t.Errorf("PRIVATE-TOKEN = %q, want the configured token", got)
+	}
+	route := r.Method + " " + r.URL.RequestURI()
+	body, _ := io.ReadAll(r.Body)
+	s.requests = append(s.requests, request{Route: route, Body: string(body)})
+
+	response, ok := s.routes[route]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"message":"404 Not found"}`))
+		return
+	}
+	w.Write([]byte(response))
+}
+
+func (s *standIn) routesRequested() []string {
+	var routes []string
+	for _, r := range s.requests {
+		routes = append(routes, r.Route)
+	}
+	return routes
+}
+
+const mrPath = "/api/v4/projects/group%2Fsub%2Frepo/merge_requests"
+
+func TestClient_GetPR(t *testing.T) {
+	mr, err := os.ReadFile("testdata/merge_request.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, client := newStandIn(t, map[string]string{"GET " + mrPath + "/42": string(mr)})
+
+	pr, err := client.GetPR(context.Background(), "group/sub", "repo", 42)
+	if err != nil {
+		t.Fatalf("GetPR() error = %v", err)
+	}
+	want := &forge.PRInfo{
+		Number:      42,
+		Title:       "Add widgets",
+		Author:      "contributor",
+		HeadBranch:  "widgets",
+		BaseBranch:  "main",
+		State:       forge.StateOpen,
+		URL:         "https://gitlab.example.com/group/sub/repo/-/merge_requests/42",
+		HeadRefOID:  "abc123",
+		IsFork:      true,
+		CreatedAt:   time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+		Labels:      []string{"feature"},
+		CheckStatus: forge.CheckPending,
+	}
+	if !reflect.DeepEqual(pr, want) {
+		t.Errorf("GetPR() = %+v, want %+v", pr, want)
+	}
+}
+
+func TestClient_GetPR_States(t *testing.T) {
+	tests := []struct {
+		state  string
+		fork   string
+		want   string
+		isFork bool
+	}{
+		{state: "opened", fork: "3", want: forge.StateOpen},
+		{state: "merged", fork: "3", want: forge.StateMerged},
+		{state: "closed", fork: "3", want: forge.StateClosed},
+		{state: "locked", fork: "9", want: forge.StateClosed, isFork: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.state, func(t *testing.T) {
+			_, client := newStandIn(t, map[string]string{
+				"GET " + mrPath + "/1": `{"iid":1,"state":"` + tt.state + `","source_project_id":` + tt.fork + `,"target_project_id":3}`,
+			})
+			pr, err := client.GetPR(context.Background(), "group/sub", "repo", 1)
+			if err != nil {
+				t.Fatalf("GetPR() error = %v", err)
+			}
+			if pr.State != tt.want || pr.IsFork != tt.isFork {
+				t.Errorf("GetPR() state %s, fork %v; want %s, %v", pr.State, pr.IsFork, tt.want, tt.isFork)
+			}
+		})
+	}
+}
+
+func TestClient_GetPR_NotFound(t *testing.T) {
+	_, client := newStandIn(t, nil)
+
+	_, err := client.GetPR(context.Background(), "group/sub", "repo", 9)
+	var notFound *forge.ErrPRNotFound
+	if !errors.As(err, &notFound) || notFound.Number != 9 || notFound.Owner != "group/sub" {
+		t.Errorf("GetPR() error = %v, want ErrPRNotFound for !9", err)
+	}
+}
+
+func TestClient_CreatePR(t *testing.T) {
+	s, client := newStandIn(t, map[string]string{
+		"POST " + mrPath: `{"iid":43,"title":"Add widgets","state":"opened","source_branch":"mfpr-42","target_branch":"main",
+			"source_project_id":3,"target_project_id":3,"web_url":"https://gitlab.example.com/group/sub/repo/-/merge_requests/43"}`,
+	})
+
+	pr, err := client.CreatePR(context.Background(), "group/sub", "repo", forge.CreatePROptions{
+		Title: "Add widgets", Body: "Migrated from !42", Base: "main", Head: "mfpr-42",
+	})
+	if err != nil {
+		t.Fatalf("CreatePR() error = %v", err)
+	}
+	if pr.Number != 43 || pr.URL != "https://gitlab.example.com/group/sub/repo/-/merge_requests/43" || pr.IsFork {
+		t.Errorf("CreatePR() = %+v, want !43", pr)
+	}
+	want := `{"description":"Migrated from !42","source_branch":"mfpr-42","target_branch":"main","title":"Add widgets"}`
+	if s.requests[0].Body != want {
+		t.Errorf("CreatePR() sent %s, want %s", s.requests[0].Body, want)
+	}
+}
+
+func TestClient_CreatePR_Conflict(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte(`{"message":["Another open merge request already exists for this source branch: !43"]}`))
+	}))
+	defer server.Close()
+	client := NewWithOptions(WithBaseURL(server.URL))
+
+	_, err := client.CreatePR(context.Background(), "group", "repo", forge.CreatePROptions{Head: "mfpr-42", Base: "main"})
+	want := `gitlab API request projects/group%2Frepo/merge_requests failed: 409 Conflict: ["Another open merge request already exists for this source branch: !43"]`
+	if err == nil || err.Error() != want {
+		t.Errorf("CreatePR() error = %v, want %s", err, want)
+	}
+}
+
+func TestClient_ClosePR(t *testing.T) {
+	s, client := newStandIn(t, map[string]string{
+		"POST " + mrPath + "/42/notes": `{"id":1}`,
+		"PUT " + mrPath + "/42":        `{"iid":42,"state":"closed"}`,
+	})
+
+	if err := client.ClosePR(context.Background(), "group/sub", "repo", 42, "Moved to !43"); err != nil {
+		t.Fatalf("ClosePR() error = %v", err)
+	}
+	want := []request{
+		{Route: "POST " + mrPath + "/42/notes", Body: `{"body":"Moved to !43"}`},
+		{Route: "PUT " + mrPath + "/42", Body: `{"state_event":"close"}`},
+	}
+	if !reflect.DeepEqual(s.requests, want) {
+		t.Errorf("ClosePR() sent %+v, want %+v", s.requests, want)
+	}
+}
+
+func TestClient_ListPRs(t *testing.T) {
+	tests := []struct {
+		name string
+		opts forge.ListOptions
+		want string
+	}{
+		{name: "default", want: "GET " + mrPath + "?per_page=100"},
+		{name: "open", opts: forge.ListOptions{State: "open", Base: "main", Limit: 5},
+			want: "GET " + mrPath + "?per_page=5&state=opened&target_branch=main"},
+		{name: "merged", opts: forge.ListOptions{State: "merged"}, want: "GET " + mrPath + "?per_page=100&state=merged"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, client := newStandIn(t, map[string]string{tt.want: `[{"iid":1,"state":"opened"},{"iid":2,"state":"merged"}]`})
+			prs, err := client.ListPRs(context.Background(), "group/sub", "repo", tt.opts)
+			if err != nil {
+				t.Fatalf("ListPRs() error = %v (requested %v)", err, s.routesRequested())
+			}
+			if len(prs) != 2 || prs[0].Number != 1 || prs[1].State != forge.StateMerged {
+				t.Errorf("ListPRs() = %+v, want !1 and !2", prs)
+			}
+		})
+	}
+}
+
+func TestClient_FindPRForBranch(t *testing.T) {
+	_, client := newStandIn(t, map[string]string{
+		"GET " + mrPath + "?per_page=1&source_branch=mfpr-42&state=all": `[{"iid":43,"state":"opened","source_branch":"mfpr-42"}]`,
+		"GET " + mrPath + "?per_page=1&source_branch=other&state=all":   `[]`,
+	})
+
+	pr, err := client.FindPRForBranch(context.Background(), "group/sub", "repo", "mfpr-42")
+	if err != nil || pr == nil || pr.Number != 43 {
+		t.Errorf("FindPRForBranch(mfpr-42) = %+v, %v; want !43", pr, err)
+	}
+	pr, err = client.FindPRForBranch(context.Background(), "group/sub", "repo", "other")
+	if err != nil || pr != nil {
+		t.Errorf("FindPRForBranch(other) = %+v, %v; want none", pr, err)
+	}
+}
+
+func TestClient_GetChecks(t *testing.T) {
+	_, client := newStandIn(t, map[string]string{
+		"GET /api/v4/projects/group%2Fsub%2Frepo/repository/commits/abc123/statuses?per_page=100": `[
+			{"id":3,"name":"test","status":"success","target_url":"https://ci/3"},
+			{"id":1,"name":"test","status":"failed","target_url":"https://ci/1"},
+			{"id":2,"name":"lint","status":"failed","allow_failure":true},
+			{"id":4,"name":"deploy","status":"manual"},
+			{"id":5,"name":"e2e","status":"running"}
+		]`,
+	})
+
+	checks, err := client.GetChecks(context.Background(), "group/sub", "repo", "abc123")
+	if err != nil {
+		t.Fatalf("GetChecks() error = %v", err)
+	}
+	want := []forge.Check{
+		{Name: "test", State: forge.CheckSuccess, URL: "https://ci/3"},
+		{Name: "lint", State: forge.CheckSuccess},
+		{Name: "deploy", State: forge.CheckSuccess},
+		{Name: "e2e", State: forge.CheckPending},
+	}
+	if !reflect.DeepEqual(checks.Checks, want) || checks.State != forge.CheckPending {
+		t.Errorf("GetChecks() = %s %+v, want pending %+v", checks.State, checks.Checks, want)
+	}
+}
+
+func TestClient_GetPRPatch(t *testing.T) {
+	mr, err := os.ReadFile("testdata/merge_request.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(&standIn{t: t, routes: map[string]string{"GET " + mrPath + "/42": string(mr)}})
+	t.Cleanup(server.Close)
+	fake := runner.NewFake(
+		runner.Entry{Command: "git remote get-url origin", Dir: "/work", Stdout: "git@gitlab.example.com:me/repo.git\n"},
+		runner.Entry{Command: "git fetch " + server.URL + "/group/sub/repo.git refs/merge-requests/42/head", Dir: "/work"},
+		runner.Entry{Command: "git format-patch --stdout def456..abc123", Dir: "/work", Stdout: "From abc123 Mon Sep 17 00:00:00 2001\n"},
+	)
+	client := NewWithOptions(WithBaseURL(server.URL), WithToken("secret"), WithRunner(fake), WithDir("/work"))
+
+	patch, err := client.GetPRPatch(context.Background(), "group/sub", "repo", 42)
+	if err != nil || string(patch) != "From abc123 Mon Sep 17 00:00:00 2001\n" {
+		t.Errorf("GetPRPatch() = %q, %v; want the formatted commits", patch, err)
+	}
+}
+
+func TestClient_CheckoutPR(t *testing.T) {
+	fake := runner.NewFake(
+		runner.Entry{Command: "git remote get-url origin", Dir: "/work", Stdout: "git@gitlab.example.com:group/sub/repo.git\n"},
+		runner.Entry{Command: "git fetch origin refs/merge-requests/42/head:refs/heads/pr-42", Dir: "/work"},
+		runner.Entry{Command: "git checkout pr-42", Dir: "/work"},
+	)
+	client := NewWithOptions(WithBaseURL("https://gitlab.example.com"), WithRunner(fake), WithDir("/work"))
+
+	if err := client.CheckoutPR(context.Background(), "group/sub", "repo", 42, "pr-42"); err != nil {
+		t.Errorf("CheckoutPR() error = %v", err)
+	}
+}
+
+func TestNewWithOptions_TokenFromEnvironment(t *testing.T) {
+	t.Setenv("GITLAB_TOKEN", "from-env")
+	client := NewWithOptions().(*Client)
+	if got := client.api.Header.Get("PRIVATE-TOKEN"); got != "from-env" {
+		t.Errorf("PRIVATE-TOKEN = %q, want GITLAB_TOKEN", got)
+	}
+	if client.api.BaseURL != "https://gitlab.com/api/v4" || client.host != "gitlab.com" {
+		t.Errorf("default API = %s on %s, want gitlab.com", client.api.BaseURL, client.host)
+	}
+}
+
+func TestClient_Describe(t *testing.T) {
+	client := NewWithOptions(WithBaseURL("https://gitlab.example.com")).(forge.Describer)
+
+	tests := []struct {
+		got, want string
+	}{
+		{
+			client.DescribeCheckoutPR("group/sub", "repo", 42, "pr-42"),
+			"git fetch https://gitlab.example.com/group/sub/repo.git refs/merge-requests/42/head:refs/heads/pr-42 && git checkout pr-42",
+		},
+		{
+			client.DescribeGetPRPatch("group/sub", "repo", 42),
+			"git fetch https://gitlab.example.com/group/sub/repo.git refs/merge-requests/42/head && git format-patch --stdout <base>..FETCH_HEAD | git am",
+		},
+		{
+			client.DescribeCreatePR("group/sub", "repo", forge.CreatePROptions{Title: "Fix", Body: "Moved", Base: "main", Head: "pr-42"}),
+			`POST https://gitlab.example.com/api/v4/projects/group%2Fsub%2Frepo/merge_requests description="Moved" source_branch="pr-42" target_branch="main" title="Fix"`,
+		},
+		{
+			client.DescribeClosePR("group/sub", "repo", 42),
+			`PUT https://gitlab.example.com/api/v4/projects/group%2Fsub%2Frepo/merge_requests/42 state_event="close"`,
+		},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("got %q, want %q", tt.got, tt.want)
+		}
+	}
+}
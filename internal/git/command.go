@@ -0,0 +1,121 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/user/git-mfpr/internal/runner"
+)
+
+// run executes git with args and returns its stdout. On failure it returns an
+// *ErrCommand holding everything git printed, classified where possible.
+func (c *Client) run(ctx context.Context, stdin []byte, args ...string) ([]byte, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	result := c.runner.Run(ctx, runner.Cmd{Name: "git", Args: args, Dir: c.dir, Stdin: stdin})
+	if result.Err == nil {
+		return result.Stdout, nil
+	}
+
+	stderr := string(result.Stderr)
+	failure := &ErrCommand{
+		Args:     args,
+		ExitCode: result.ExitCode,
+		Output:   strings.TrimSpace(string(result.Stdout) + stderr),
+		Err:      result.Err,
+	}
+
+	switch ctx.Err() {
+	case context.DeadlineExceeded:
+		failure.Summary = fmt.Sprintf("timed out after %s", c.timeout)
+		failure.Err = ctx.Err()
+	case context.Canceled:
+		failure.Summary = "cancelled"
+		failure.Err = ctx.Err()
+	default:
+		if classified := classify(stderr); classified != nil {
+			failure.Summary = classified.Error()
+			failure.Err = classified
+		} else {
+			failure.Summary = summarize(stderr, result.Err)
+		}
+	}
+	return result.Stdout, failure
+}
+
+var (
+	quotedRevision   = regexp.MustCompile(`(?:argument|pathspec|revision) '([^']+)'`)
+	remoteRefMissing = regexp.MustCompile(`couldn't find remote ref (\S+)`)
+	protectedRef     = regexp.MustCompile(`(?:protected branch|Protected branch update failed for) (?:refs/heads/)?([^\s.]+)`)
+)
+
+// classify recognises the git failures users most often need to act on.
+func classify(stderr string) error {
+	lower := strings.ToLower(stderr)
+	switch {
+	case strings.Contains(lower, "protected branch"):
+		err := &ErrProtectedBranch{}
+		if m := protectedRef.FindStringSubmatch(stderr); m != nil {
+			err.Branch = m[1]
+		}
+		return err
+	case strings.Contains(lower, "non-fast-forward"), strings.Contains(lower, "fetch first"):
+		return &ErrNonFastForward{}
+	case strings.Contains(lower, "permission denied"),
+		strings.Contains(lower, "authentication failed"),
+		strings.Contains(lower, "could not read username"),
+		strings.Contains(lower, "the requested url returned error: 403"):
+		return &ErrAuthDenied{}
+	case strings.Contains(lower, "would be overwritten by"):
+		return &ErrLocalChanges{Files: overwrittenFiles(stderr)}
+	case strings.Contains(lower, "unknown revision"),
+		strings.Contains(lower, "did not match any file(s) known to git"),
+		strings.Contains(lower, "not a valid object name"),
+		strings.Contains(lower, "couldn't find remote ref"):
+		err := &ErrUnknownRevision{}
+		if m := quotedRevision.FindStringSubmatch(stderr); m != nil {
+			err.Revision = m[1]
+		} else if m := remoteRefMissing.FindStringSubmatch(stderr); m != nil {
+			err.Revision = m[1]
+		}
+		return err
+	}
+	return nil
+}
+
+// overwrittenFiles lists the tab-indented paths git prints after "would be
+// overwritten by checkout:" and similar messages.
+func overwrittenFiles(stderr string) []string {
+	var files []string
+	for _, line := range strings.Split(stderr, "\n") {
+		if strings.HasPrefix(line, "\t") {
+			files = append(files, strings.TrimSpace(line))
+		}
+	}
+	return files
+}
+
+// summarize picks the most useful line of stderr: the first error or fatal
+// line if there is one, otherwise the first line, otherwise err itself.
+func summarize(stderr string, err error) string {
+	first := ""
+	for _, line := range strings.Split(stderr, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "error:") || strings.HasPrefix(line, "fatal:") {
+			return line
+		}
+		if first == "" {
+			first = line
+		}
+	}
+	if first != "" {
+		return first
+	}
+	return err.Error()
+}
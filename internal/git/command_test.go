@@ -0,0 +1,182 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name   string
+		stderr string
+		want   error
+	}{
+		{
+			name: "non-fast-forward",
+			stderr: "To github.com:owner/repo.git\n ! [rejected]        main -> main (non-fast-forward)\n" +
+				"error: failed to push some refs to 'github.com:owner/repo.git'",
+			want: &ErrNonFastForward{},
+		},
+		{
+			name:   "fetch first",
+			stderr: " ! [rejected]        main -> main (fetch first)\nerror: failed to push some refs",
+			want:   &ErrNonFastForward{},
+		},
+		{
+			name:   "ssh permission denied",
+			stderr: "git@github.com: Permission denied (publickey).\nfatal: Could not read from remote repository.",
+			want:   &ErrAuthDenied{},
+		},
+		{
+			name:   "https authentication failed",
+			stderr: "remote: Invalid username or password.\nfatal: Authentication failed for 'https://github.com/owner/repo.git/'",
+			want:   &ErrAuthDenied{},
+		},
+		{
+			name:   "https 403",
+			stderr: "fatal: unable to access 'https://github.com/owner/repo.git/': The requested URL returned error: 403",
+			want:   &ErrAuthDenied{},
+		},
+		{
+			name: "github protected branch",
+			stderr: "remote: error: GH006: Protected branch update failed for refs/heads/main.\n" +
+				" ! [remote rejected] main -> main (protected branch hook declined)",
+			want: &ErrProtectedBranch{Branch: "main"},
+		},
+		{
+			name: "local changes",
+			stderr: "error: Your local changes to the following files would be overwritten by checkout:\n" +
+				"\tREADME.md\n\tmain.go\nPlease commit your changes or stash them before you switch branches.\nAborting",
+			want: &ErrLocalChanges{Files: []string{"README.md", "main.go"}},
+		},
+		{
+			name:   "unknown pathspec",
+			stderr: "error: pathspec 'nope' did not match any file(s) known to git",
+			want:   &ErrUnknownRevision{Revision: "nope"},
+		},
+		{
+			name:   "ambiguous argument",
+			stderr: "fatal: ambiguous argument 'v9': unknown revision or path not in the working tree.",
+			want:   &ErrUnknownRevision{Revision: "v9"},
+		},
+		{
+			name:   "missing remote ref",
+			stderr: "fatal: couldn't find remote ref feature-x",
+			want:   &ErrUnknownRevision{Revision: "feature-x"},
+		},
+		{
+			name:   "unrecognised",
+			stderr: "fatal: something else went wrong",
+			want:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classify(tt.stderr); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("classify() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	tests := []struct {
+		name   string
+		stderr string
+		want   string
+	}{
+		{"prefers error line", "hint: something\nerror: the real problem\n", "error: the real problem"},
+		{"prefers fatal line", "warning: x\nfatal: not a git repository\n", "fatal: not a git repository"},
+		{"first line otherwise", "\nsomething odd\nmore\n", "something odd"},
+		{"falls back to exec error", "", "exit status 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := summarize(tt.stderr, errors.New("exit status 1")); got != tt.want {
+				t.Errorf("summarize() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_ClassifiedErrors(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	defer os.Chdir(oldWd)
+
+	remote := tmpDir + "/remote.git"
+	runGitCommand(t, "init", "--bare", "-b", "main", remote)
+	runGitCommand(t, "clone", remote, tmpDir+"/a")
+	runGitCommand(t, "clone", remote, tmpDir+"/b")
+
+	commit := func(file, content string) {
+		t.Helper()
+		if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+		runGitCommand(t, "add", file)
+		runGitCommand(t, "-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "-m", file)
+	}
+
+	if err := os.Chdir(tmpDir + "/a"); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+	commit("a.txt", "a")
+	runGitCommand(t, "push", "origin", "main")
+
+	if err := os.Chdir(tmpDir + "/b"); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+	runGitCommand(t, "checkout", "-b", "main")
+	commit("b.txt", "b")
+
+	client := New()
+
+	t.Run("non-fast-forward push", func(t *testing.T) {
+		err := client.Push(ctx, "origin", "main")
+		var rejected *ErrNonFastForward
+		if !errors.As(err, &rejected) {
+			t.Fatalf("Push() error = %v, want ErrNonFastForward", err)
+		}
+		var cmdErr *ErrCommand
+		if !errors.As(err, &cmdErr) || !strings.Contains(cmdErr.Output, "[rejected]") {
+			t.Errorf("Push() error should keep the full git output, got %#v", cmdErr)
+		}
+	})
+
+	t.Run("unknown revision", func(t *testing.T) {
+		err := client.Checkout(ctx, "does-not-exist")
+		var unknown *ErrUnknownRevision
+		if !errors.As(err, &unknown) || unknown.Revision != "does-not-exist" {
+			t.Fatalf("Checkout() error = %v, want ErrUnknownRevision for does-not-exist", err)
+		}
+		if !strings.Contains(err.Error(), "unknown revision does-not-exist") {
+			t.Errorf("Checkout() error = %q, want the classified message", err.Error())
+		}
+	})
+
+	t.Run("local changes", func(t *testing.T) {
+		runGitCommand(t, "checkout", "-b", "other")
+		commit("shared.txt", "other")
+		runGitCommand(t, "checkout", "main")
+		if err := os.WriteFile("shared.txt", []byte("local"), 0o600); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+
+		err := client.Checkout(ctx, "other")
+		var changes *ErrLocalChanges
+		if !errors.As(err, &changes) {
+			t.Fatalf("Checkout() error = %v, want ErrLocalChanges", err)
+		}
+		if len(changes.Files) != 1 || changes.Files[0] != "shared.txt" {
+			t.Errorf("ErrLocalChanges.Files = %v, want [shared.txt]", changes.Files)
+		}
+	})
+}
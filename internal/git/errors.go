@@ -1,6 +1,11 @@
 package git
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+
+	"github.com/user/git-mfpr/internal/errs"
+)
 
 type (
 	ErrNotInRepo struct{}
@@ -16,66 +21,312 @@ type (
 	ErrCheckoutFailed struct {
 		Branch string
 		Detail string
+		Err    error
 	}
 
 	ErrPullFailed struct {
 		Remote string
 		Branch string
 		Detail string
+		Err    error
 	}
 
 	ErrPushFailed struct {
 		Remote string
 		Branch string
 		Detail string
+		Err    error
 	}
 
 	ErrDeleteBranchFailed struct {
 		Branch string
 		Detail string
+		Err    error
+	}
+
+	ErrDeleteRemoteBranchFailed struct {
+		Remote string
+		Branch string
+		Detail string
+		Err    error
 	}
 
 	ErrGetCurrentBranchFailed struct {
 		Detail string
+		Err    error
 	}
 
 	ErrGetRemoteURLFailed struct {
 		Detail string
+		Err    error
+	}
+
+	ErrWriteRefFailed struct {
+		Ref    string
+		Detail string
+		Err    error
+	}
+
+	ErrReadRefFailed struct {
+		Ref    string
+		Detail string
+		Err    error
+	}
+
+	ErrReadConfigFailed struct {
+		Key    string
+		Detail string
+		Err    error
+	}
+
+	// ErrPatchFailed is a failure while exporting or applying commits as
+	// patches. Op describes what was being done.
+	ErrPatchFailed struct {
+		Op     string
+		Detail string
+		Err    error
+	}
+
+	// ErrCommand is a failed git invocation. Operation errors wrap it, and it
+	// wraps one of the classified errors below when the failure is recognised.
+	ErrCommand struct {
+		Args     []string
+		ExitCode int
+		Summary  string
+		Output   string
+		Err      error
+	}
+
+	ErrNonFastForward struct{}
+
+	ErrAuthDenied struct{}
+
+	ErrProtectedBranch struct {
+		Branch string
+	}
+
+	ErrLocalChanges struct {
+		Files []string
+	}
+
+	ErrUnknownRevision struct {
+		Revision string
 	}
 )
 
-func (e ErrNotInRepo) Error() string {
+func (e *ErrNotInRepo) Error() string {
 	return "not in a git repository"
 }
 
-func (e ErrInvalidRemoteURL) Error() string {
+func (e *ErrInvalidRemoteURL) Error() string {
 	return fmt.Sprintf("invalid remote URL format: %s", e.URL)
 }
 
-func (e ErrBranchNotFound) Error() string {
+func (e *ErrBranchNotFound) Error() string {
 	return fmt.Sprintf("branch %s not found", e.Branch)
 }
 
-func (e ErrCheckoutFailed) Error() string {
+func (e *ErrCheckoutFailed) Error() string {
 	return fmt.Sprintf("failed to checkout branch %s: %s", e.Branch, e.Detail)
 }
 
-func (e ErrPullFailed) Error() string {
+func (e *ErrCheckoutFailed) Unwrap() error {
+	return e.Err
+}
+
+func (e *ErrPullFailed) Error() string {
 	return fmt.Sprintf("failed to pull %s/%s: %s", e.Remote, e.Branch, e.Detail)
 }
 
-func (e ErrPushFailed) Error() string {
+func (e *ErrPullFailed) Unwrap() error {
+	return e.Err
+}
+
+func (e *ErrPushFailed) Error() string {
 	return fmt.Sprintf("failed to push to %s/%s: %s", e.Remote, e.Branch, e.Detail)
 }
 
-func (e ErrDeleteBranchFailed) Error() string {
+func (e *ErrPushFailed) Unwrap() error {
+	return e.Err
+}
+
+func (e *ErrDeleteBranchFailed) Error() string {
 	return fmt.Sprintf("failed to delete branch %s: %s", e.Branch, e.Detail)
 }
 
-func (e ErrGetCurrentBranchFailed) Error() string {
+func (e *ErrDeleteBranchFailed) Unwrap() error {
+	return e.Err
+}
+
+func (e *ErrDeleteRemoteBranchFailed) Error() string {
+	return fmt.Sprintf("failed to delete remote branch %s/%s: %s", e.Remote, e.Branch, e.Detail)
+}
+
+func (e *ErrDeleteRemoteBranchFailed) Unwrap() error {
+	return e.Err
+}
+
+func (e *ErrGetCurrentBranchFailed) Error() string {
 	return fmt.Sprintf("failed to get current branch: %s", e.Detail)
 }
 
-func (e ErrGetRemoteURLFailed) Error() string {
+func (e *ErrGetCurrentBranchFailed) Unwrap() error {
+	return e.Err
+}
+
+func (e *ErrGetRemoteURLFailed) Error() string {
 	return fmt.Sprintf("failed to get remote URL: %s", e.Detail)
 }
+
+func (e *ErrGetRemoteURLFailed) Unwrap() error {
+	return e.Err
+}
+
+func (e *ErrWriteRefFailed) Error() string {
+	return fmt.Sprintf("failed to write ref %s: %s", e.Ref, e.Detail)
+}
+
+func (e *ErrWriteRefFailed) Unwrap() error {
+	return e.Err
+}
+
+func (e *ErrReadRefFailed) Error() string {
+	return fmt.Sprintf("failed to read ref %s: %s", e.Ref, e.Detail)
+}
+
+func (e *ErrReadRefFailed) Unwrap() error {
+	return e.Err
+}
+
+func (e *ErrReadConfigFailed) Error() string {
+	return fmt.Sprintf("failed to read git config %s: %s", e.Key, e.Detail)
+}
+
+func (e *ErrReadConfigFailed) Unwrap() error {
+	return e.Err
+}
+
+func (e *ErrPatchFailed) Error() string {
+	return fmt.Sprintf("failed to %s: %s", e.Op, e.Detail)
+}
+
+func (e *ErrPatchFailed) Unwrap() error {
+	return e.Err
+}
+
+func (e *ErrCommand) Error() string {
+	return e.Summary
+}
+
+func (e *ErrCommand) Unwrap() error {
+	return e.Err
+}
+
+func (e *ErrNonFastForward) Error() string {
+	return "the remote branch has commits that are not in the local branch (non-fast-forward)"
+}
+
+func (e *ErrAuthDenied) Error() string {
+	return "authentication with the remote was denied"
+}
+
+func (e *ErrProtectedBranch) Error() string {
+	if e.Branch == "" {
+		return "the remote branch is protected"
+	}
+	return fmt.Sprintf("branch %s is protected on the remote", e.Branch)
+}
+
+func (e *ErrLocalChanges) Error() string {
+	if len(e.Files) == 0 {
+		return "local changes would be overwritten; commit or stash them first"
+	}
+	return fmt.Sprintf("local changes to %s would be overwritten; commit or stash them first", strings.Join(e.Files, ", "))
+}
+
+func (e *ErrUnknownRevision) Error() string {
+	if e.Revision == "" {
+		return "unknown revision"
+	}
+	return fmt.Sprintf("unknown revision %s", e.Revision)
+}
+
+func (e *ErrNotInRepo) Is(target error) bool {
+	return target == errs.ErrPrecondition
+}
+
+func (e *ErrInvalidRemoteURL) Is(target error) bool {
+	return target == errs.ErrGit
+}
+
+func (e *ErrBranchNotFound) Is(target error) bool {
+	return target == errs.ErrNotFound
+}
+
+func (e *ErrCheckoutFailed) Is(target error) bool {
+	return target == errs.ErrGit
+}
+
+func (e *ErrPullFailed) Is(target error) bool {
+	return target == errs.ErrGit
+}
+
+func (e *ErrPushFailed) Is(target error) bool {
+	return target == errs.ErrGit
+}
+
+func (e *ErrDeleteBranchFailed) Is(target error) bool {
+	return target == errs.ErrGit
+}
+
+func (e *ErrDeleteRemoteBranchFailed) Is(target error) bool {
+	return target == errs.ErrGit
+}
+
+func (e *ErrGetCurrentBranchFailed) Is(target error) bool {
+	return target == errs.ErrGit
+}
+
+func (e *ErrGetRemoteURLFailed) Is(target error) bool {
+	return target == errs.ErrGit
+}
+
+func (e *ErrWriteRefFailed) Is(target error) bool {
+	return target == errs.ErrGit
+}
+
+func (e *ErrReadRefFailed) Is(target error) bool {
+	return target == errs.ErrGit
+}
+
+func (e *ErrReadConfigFailed) Is(target error) bool {
+	return target == errs.ErrGit
+}
+
+func (e *ErrPatchFailed) Is(target error) bool {
+	return target == errs.ErrGit
+}
+
+func (e *ErrCommand) Is(target error) bool {
+	return target == errs.ErrGit
+}
+
+func (e *ErrNonFastForward) Is(target error) bool {
+	return target == errs.ErrGit
+}
+
+func (e *ErrAuthDenied) Is(target error) bool {
+	return target == errs.ErrGit
+}
+
+func (e *ErrProtectedBranch) Is(target error) bool {
+	return target == errs.ErrGit
+}
+
+func (e *ErrLocalChanges) Is(target error) bool {
+	return target == errs.ErrPrecondition
+}
+
+func (e *ErrUnknownRevision) Is(target error) bool {
+	return target == errs.ErrNotFound
+}
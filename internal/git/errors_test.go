@@ -1,12 +1,15 @@
 package git
 
 import (
+	"errors"
 	"strings"
 	"testing"
+
+	"github.com/user/git-mfpr/internal/errs"
 )
 
 func TestErrNotInRepo_Error(t *testing.T) {
-	err := ErrNotInRepo{}
+	err := &ErrNotInRepo{}
 	expected := "not in a git repository"
 	if err.Error() != expected {
 		t.Errorf("Expected error message %q, got %q", expected, err.Error())
@@ -16,22 +19,22 @@ func TestErrNotInRepo_Error(t *testing.T) {
 func TestErrInvalidRemoteURL_Error(t *testing.T) {
 	tests := []struct {
 		name     string
-		err      ErrInvalidRemoteURL
+		err      *ErrInvalidRemoteURL
 		expected string
 	}{
 		{
 			name:     "empty URL",
-			err:      ErrInvalidRemoteURL{URL: ""},
+			err:      &ErrInvalidRemoteURL{URL: ""},
 			expected: "invalid remote URL format: ",
 		},
 		{
 			name:     "malformed URL",
-			err:      ErrInvalidRemoteURL{URL: "not-a-valid-url"},
+			err:      &ErrInvalidRemoteURL{URL: "not-a-valid-url"},
 			expected: "invalid remote URL format: not-a-valid-url",
 		},
 		{
 			name:     "missing protocol",
-			err:      ErrInvalidRemoteURL{URL: "github.com/user/repo"},
+			err:      &ErrInvalidRemoteURL{URL: "github.com/user/repo"},
 			expected: "invalid remote URL format: github.com/user/repo",
 		},
 	}
@@ -48,17 +51,17 @@ func TestErrInvalidRemoteURL_Error(t *testing.T) {
 func TestErrBranchNotFound_Error(t *testing.T) {
 	tests := []struct {
 		name     string
-		err      ErrBranchNotFound
+		err      *ErrBranchNotFound
 		expected string
 	}{
 		{
 			name:     "feature branch",
-			err:      ErrBranchNotFound{Branch: "feature/new-feature"},
+			err:      &ErrBranchNotFound{Branch: "feature/new-feature"},
 			expected: "branch feature/new-feature not found",
 		},
 		{
 			name:     "main branch",
-			err:      ErrBranchNotFound{Branch: "main"},
+			err:      &ErrBranchNotFound{Branch: "main"},
 			expected: "branch main not found",
 		},
 	}
@@ -75,12 +78,12 @@ func TestErrBranchNotFound_Error(t *testing.T) {
 func TestErrCheckoutFailed_Error(t *testing.T) {
 	tests := []struct {
 		name     string
-		err      ErrCheckoutFailed
+		err      *ErrCheckoutFailed
 		expected string
 	}{
 		{
 			name: "uncommitted changes",
-			err: ErrCheckoutFailed{
+			err: &ErrCheckoutFailed{
 				Branch: "develop",
 				Detail: "uncommitted changes in working directory",
 			},
@@ -88,7 +91,7 @@ func TestErrCheckoutFailed_Error(t *testing.T) {
 		},
 		{
 			name: "branch conflict",
-			err: ErrCheckoutFailed{
+			err: &ErrCheckoutFailed{
 				Branch: "hotfix/urgent",
 				Detail: "branch name conflicts with existing file",
 			},
@@ -108,12 +111,12 @@ func TestErrCheckoutFailed_Error(t *testing.T) {
 func TestErrPullFailed_Error(t *testing.T) {
 	tests := []struct {
 		name     string
-		err      ErrPullFailed
+		err      *ErrPullFailed
 		expected string
 	}{
 		{
 			name: "merge conflict",
-			err: ErrPullFailed{
+			err: &ErrPullFailed{
 				Remote: "origin",
 				Branch: "main",
 				Detail: "merge conflict detected",
@@ -122,7 +125,7 @@ func TestErrPullFailed_Error(t *testing.T) {
 		},
 		{
 			name: "network error",
-			err: ErrPullFailed{
+			err: &ErrPullFailed{
 				Remote: "upstream",
 				Branch: "develop",
 				Detail: "connection timeout",
@@ -143,12 +146,12 @@ func TestErrPullFailed_Error(t *testing.T) {
 func TestErrPushFailed_Error(t *testing.T) {
 	tests := []struct {
 		name     string
-		err      ErrPushFailed
+		err      *ErrPushFailed
 		expected string
 	}{
 		{
 			name: "permission denied",
-			err: ErrPushFailed{
+			err: &ErrPushFailed{
 				Remote: "origin",
 				Branch: "feature/protected",
 				Detail: "permission denied",
@@ -157,7 +160,7 @@ func TestErrPushFailed_Error(t *testing.T) {
 		},
 		{
 			name: "non-fast-forward",
-			err: ErrPushFailed{
+			err: &ErrPushFailed{
 				Remote: "origin",
 				Branch: "main",
 				Detail: "non-fast-forward update",
@@ -178,12 +181,12 @@ func TestErrPushFailed_Error(t *testing.T) {
 func TestErrDeleteBranchFailed_Error(t *testing.T) {
 	tests := []struct {
 		name     string
-		err      ErrDeleteBranchFailed
+		err      *ErrDeleteBranchFailed
 		contains string
 	}{
 		{
 			name: "not fully merged",
-			err: ErrDeleteBranchFailed{
+			err: &ErrDeleteBranchFailed{
 				Branch: "feature/incomplete",
 				Detail: "branch is not fully merged",
 			},
@@ -191,7 +194,7 @@ func TestErrDeleteBranchFailed_Error(t *testing.T) {
 		},
 		{
 			name: "current branch",
-			err: ErrDeleteBranchFailed{
+			err: &ErrDeleteBranchFailed{
 				Branch: "main",
 				Detail: "cannot delete current branch",
 			},
@@ -212,17 +215,17 @@ func TestErrDeleteBranchFailed_Error(t *testing.T) {
 func TestErrGetCurrentBranchFailed_Error(t *testing.T) {
 	tests := []struct {
 		name     string
-		err      ErrGetCurrentBranchFailed
+		err      *ErrGetCurrentBranchFailed
 		expected string
 	}{
 		{
 			name:     "detached HEAD",
-			err:      ErrGetCurrentBranchFailed{Detail: "HEAD is detached"},
+			err:      &ErrGetCurrentBranchFailed{Detail: "HEAD is detached"},
 			expected: "failed to get current branch: HEAD is detached",
 		},
 		{
 			name:     "corrupted git",
-			err:      ErrGetCurrentBranchFailed{Detail: "corrupted git directory"},
+			err:      &ErrGetCurrentBranchFailed{Detail: "corrupted git directory"},
 			expected: "failed to get current branch: corrupted git directory",
 		},
 	}
@@ -239,17 +242,17 @@ func TestErrGetCurrentBranchFailed_Error(t *testing.T) {
 func TestErrGetRemoteURLFailed_Error(t *testing.T) {
 	tests := []struct {
 		name     string
-		err      ErrGetRemoteURLFailed
+		err      *ErrGetRemoteURLFailed
 		expected string
 	}{
 		{
 			name:     "no remote",
-			err:      ErrGetRemoteURLFailed{Detail: "no remote 'origin' found"},
+			err:      &ErrGetRemoteURLFailed{Detail: "no remote 'origin' found"},
 			expected: "failed to get remote URL: no remote 'origin' found",
 		},
 		{
 			name:     "invalid config",
-			err:      ErrGetRemoteURLFailed{Detail: "invalid git config"},
+			err:      &ErrGetRemoteURLFailed{Detail: "invalid git config"},
 			expected: "failed to get remote URL: invalid git config",
 		},
 	}
@@ -265,20 +268,20 @@ func TestErrGetRemoteURLFailed_Error(t *testing.T) {
 
 // Test that errors implement the error interface
 func TestErrorsImplementErrorInterface(_ *testing.T) {
-	var _ error = ErrNotInRepo{}
-	var _ error = ErrInvalidRemoteURL{}
-	var _ error = ErrBranchNotFound{}
-	var _ error = ErrCheckoutFailed{}
-	var _ error = ErrPullFailed{}
-	var _ error = ErrPushFailed{}
-	var _ error = ErrDeleteBranchFailed{}
-	var _ error = ErrGetCurrentBranchFailed{}
-	var _ error = ErrGetRemoteURLFailed{}
+	var _ error = &ErrNotInRepo{}
+	var _ error = &ErrInvalidRemoteURL{}
+	var _ error = &ErrBranchNotFound{}
+	var _ error = &ErrCheckoutFailed{}
+	var _ error = &ErrPullFailed{}
+	var _ error = &ErrPushFailed{}
+	var _ error = &ErrDeleteBranchFailed{}
+	var _ error = &ErrGetCurrentBranchFailed{}
+	var _ error = &ErrGetRemoteURLFailed{}
 }
 
 // Benchmark error message generation
 func BenchmarkErrPullFailed_Error(b *testing.B) {
-	err := ErrPullFailed{
+	err := &ErrPullFailed{
 		Remote: "origin",
 		Branch: "main",
 		Detail: "connection refused",
@@ -290,7 +293,7 @@ func BenchmarkErrPullFailed_Error(b *testing.B) {
 }
 
 func BenchmarkErrPushFailed_Error(b *testing.B) {
-	err := ErrPushFailed{
+	err := &ErrPushFailed{
 		Remote: "upstream",
 		Branch: "feature/benchmark",
 		Detail: "authentication failed",
@@ -300,3 +303,77 @@ func BenchmarkErrPushFailed_Error(b *testing.B) {
 		_ = err.Error()
 	}
 }
+
+func TestClassifiedErrors_Error(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{"non-fast-forward", &ErrNonFastForward{}, "the remote branch has commits that are not in the local branch (non-fast-forward)"},
+		{"auth denied", &ErrAuthDenied{}, "authentication with the remote was denied"},
+		{"protected branch", &ErrProtectedBranch{Branch: "main"}, "branch main is protected on the remote"},
+		{"protected branch unknown", &ErrProtectedBranch{}, "the remote branch is protected"},
+		{"local changes", &ErrLocalChanges{Files: []string{"a.go", "b.go"}}, "local changes to a.go, b.go would be overwritten; commit or stash them first"},
+		{"local changes unknown", &ErrLocalChanges{}, "local changes would be overwritten; commit or stash them first"},
+		{"unknown revision", &ErrUnknownRevision{Revision: "v9"}, "unknown revision v9"},
+		{"unknown revision unnamed", &ErrUnknownRevision{}, "unknown revision"},
+		{"command", &ErrCommand{Summary: "fatal: boom", Output: "fatal: boom\nmore"}, "fatal: boom"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.err.Error() != tt.expected {
+				t.Errorf("Expected error message %q, got %q", tt.expected, tt.err.Error())
+			}
+		})
+	}
+}
+
+func TestOperationErrors_Unwrap(t *testing.T) {
+	cause := &ErrCommand{Summary: "rejected", Err: &ErrNonFastForward{}}
+	err := error(&ErrPushFailed{Remote: "origin", Branch: "main", Detail: cause.Error(), Err: cause})
+
+	var rejected *ErrNonFastForward
+	if !errors.As(err, &rejected) {
+		t.Error("errors.As should find ErrNonFastForward through ErrPushFailed and ErrCommand")
+	}
+	var cmdErr *ErrCommand
+	if !errors.As(err, &cmdErr) || cmdErr != cause {
+		t.Error("errors.As should find the wrapped ErrCommand")
+	}
+}
+
+func TestErrorCategories(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{name: "not in repo", err: &ErrNotInRepo{}, want: errs.ErrPrecondition},
+		{name: "local changes", err: &ErrLocalChanges{}, want: errs.ErrPrecondition},
+		{name: "branch not found", err: &ErrBranchNotFound{Branch: "b"}, want: errs.ErrNotFound},
+		{name: "unknown revision", err: &ErrUnknownRevision{Revision: "v9"}, want: errs.ErrNotFound},
+		{name: "push failed", err: &ErrPushFailed{Branch: "b"}, want: errs.ErrGit},
+		{name: "command", err: &ErrCommand{Summary: "boom"}, want: errs.ErrGit},
+		{name: "protected branch", err: &ErrProtectedBranch{}, want: errs.ErrGit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !errors.Is(tt.err, tt.want) {
+				t.Errorf("errors.Is(%v, %v) = false", tt.err, tt.want)
+			}
+			if errors.Is(tt.err, errs.ErrGitHub) {
+				t.Errorf("errors.Is(%v, ErrGitHub) = true", tt.err)
+			}
+		})
+	}
+
+	// A checkout refused because of local changes is both a git failure and
+	// a precondition the user can fix.
+	err := &ErrCheckoutFailed{Branch: "main", Err: &ErrCommand{Err: &ErrLocalChanges{}}}
+	if !errors.Is(err, errs.ErrGit) || !errors.Is(err, errs.ErrPrecondition) {
+		t.Errorf("errors.Is(%v) should match ErrGit and ErrPrecondition", err)
+	}
+}
@@ -2,9 +2,13 @@ package git
 
 import (
 	"context"
-	"os/exec"
+	"errors"
+	"strconv"
 	"strings"
 	"time"
+
+	"github.com/user/git-mfpr/internal/forge"
+	"github.com/user/git-mfpr/internal/runner"
 )
 
 type (
@@ -52,12 +56,32 @@ func (r *OperationResult) IsError() bool {
 type Git interface {
 	CurrentBranch(ctx context.Context) (string, error)
 	CurrentRepo(ctx context.Context) (owner, name string, err error)
+	RevParse(ctx context.Context, rev string) (string, error)
+	CountCommits(ctx context.Context, revRange string) (int, error)
+	RemoteURL(ctx context.Context, remote string) (string, error)
 	Checkout(ctx context.Context, branch string) error
 	Pull(ctx context.Context, remote, branch string) error
 	Push(ctx context.Context, remote, branch string) error
+	ForcePush(ctx context.Context, remote, branch string) error
 	HasBranch(ctx context.Context, name string) bool
+	HasRemoteBranch(ctx context.Context, remote, name string) bool
 	DeleteBranch(ctx context.Context, name string) error
+	DeleteRemoteBranch(ctx context.Context, remote, name string) error
 	IsInRepo(ctx context.Context) bool
+	WriteBlobRef(ctx context.Context, ref string, data []byte) error
+	ReadBlobRefs(ctx context.Context, prefix string) (map[string][]byte, error)
+	DeleteRef(ctx context.Context, ref string) error
+	Config(ctx context.Context, key string) (string, error)
+	ConfigAll(ctx context.Context, key string) ([]string, error)
+
+	MergeBase(ctx context.Context, a, b string) (string, error)
+	FormatPatch(ctx context.Context, revRange string) ([]byte, error)
+	ResetBranch(ctx context.Context, branch, startPoint string) error
+	ApplyMailbox(ctx context.Context, mbox []byte) error
+	CurrentPatch(ctx context.Context) ([]byte, error)
+	ApplyWithRejects(ctx context.Context, patch []byte) ([]Reject, error)
+	ContinueApply(ctx context.Context) error
+	AbortApply(ctx context.Context) error
 
 	CurrentBranchResult(ctx context.Context) *BranchResult
 	CurrentRepoResult(ctx context.Context) *RepoResult
@@ -69,6 +93,8 @@ type Git interface {
 
 type Client struct {
 	timeout time.Duration
+	runner  runner.Runner
+	dir     string
 }
 
 type Option func(*Client)
@@ -79,6 +105,20 @@ func WithTimeout(timeout time.Duration) Option {
 	}
 }
 
+// WithRunner runs commands through r, so that they can be observed.
+func WithRunner(r runner.Runner) Option {
+	return func(c *Client) {
+		c.runner = r
+	}
+}
+
+// WithDir runs every command in dir instead of the current directory.
+func WithDir(dir string) Option {
+	return func(c *Client) {
+		c.dir = dir
+	}
+}
+
 func New() Git {
 	return NewWithOptions()
 }
@@ -86,6 +126,7 @@ func New() Git {
 func NewWithOptions(opts ...Option) Git {
 	client := &Client{
 		timeout: 30 * time.Second,
+		runner:  runner.New(),
 	}
 
 	for _, opt := range opts {
@@ -95,88 +136,184 @@ func NewWithOptions(opts ...Option) Git {
 	return client
 }
 
+// withTimeout bounds a single operation by the client's timeout. A zero
+// timeout leaves ctx as is.
+func (c *Client) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, c.timeout)
+}
+
 func (c *Client) CurrentBranch(ctx context.Context) (string, error) {
-	cmd := exec.CommandContext(ctx, "git", "rev-parse", "--abbrev-ref", "HEAD")
-	output, err := cmd.Output()
+	output, err := c.run(ctx, nil, "rev-parse", "--abbrev-ref", "HEAD")
 	if err != nil {
-		return "", &ErrGetCurrentBranchFailed{Detail: err.Error()}
+		return "", &ErrGetCurrentBranchFailed{Detail: err.Error(), Err: err}
 	}
 	return strings.TrimSpace(string(output)), nil
 }
 
-func (c *Client) CurrentRepo(ctx context.Context) (owner, name string, err error) {
-	cmd := exec.CommandContext(ctx, "git", "remote", "get-url", "origin")
-	output, err := cmd.Output()
+// RevParse returns the commit SHA that rev points at.
+func (c *Client) RevParse(ctx context.Context, rev string) (string, error) {
+	output, err := c.run(ctx, nil, "rev-parse", "--verify", rev+"^{commit}")
 	if err != nil {
-		return "", "", &ErrGetRemoteURLFailed{Detail: err.Error()}
+		return "", &ErrReadRefFailed{Ref: rev, Detail: err.Error(), Err: err}
 	}
+	return strings.TrimSpace(string(output)), nil
+}
 
-	remoteURL := strings.TrimSpace(string(output))
+// CountCommits returns how many commits revRange, such as main..feature,
+// contains.
+func (c *Client) CountCommits(ctx context.Context, revRange string) (int, error) {
+	output, err := c.run(ctx, nil, "rev-list", "--count", revRange)
+	if err != nil {
+		return 0, &ErrReadRefFailed{Ref: revRange, Detail: err.Error(), Err: err}
+	}
+	return strconv.Atoi(strings.TrimSpace(string(output)))
+}
 
-	if strings.HasPrefix(remoteURL, "git@github.com:") {
-		parts := strings.TrimPrefix(remoteURL, "git@github.com:")
-		parts = strings.TrimSuffix(parts, ".git")
-		split := strings.Split(parts, "/")
-		if len(split) != 2 {
-			return "", "", &ErrInvalidRemoteURL{URL: remoteURL}
-		}
-		return split[0], split[1], nil
-	} else if strings.Contains(remoteURL, "github.com") {
-		parts := strings.Split(remoteURL, "github.com/")
-		if len(parts) != 2 {
-			return "", "", &ErrInvalidRemoteURL{URL: remoteURL}
-		}
-		repoPath := strings.TrimSuffix(parts[1], ".git")
-		split := strings.Split(repoPath, "/")
-		if len(split) != 2 {
-			return "", "", &ErrInvalidRemoteURL{URL: remoteURL}
-		}
-		return split[0], split[1], nil
+// CurrentRepo returns the owner and name of origin's repository, on any
+// forge. On GitLab the owner may be a nested group like group/subgroup.
+func (c *Client) CurrentRepo(ctx context.Context) (owner, name string, err error) {
+	remoteURL, err := c.RemoteURL(ctx, "origin")
+	if err != nil {
+		return "", "", err
 	}
 
-	return "", "", &ErrInvalidRemoteURL{URL: remoteURL}
+	remote, err := forge.ParseRemoteURL(remoteURL)
+	if err != nil {
+		return "", "", &ErrInvalidRemoteURL{URL: remoteURL}
+	}
+	return remote.Owner, remote.Repo, nil
+}
+
+func (c *Client) RemoteURL(ctx context.Context, remote string) (string, error) {
+	output, err := c.run(ctx, nil, "remote", "get-url", remote)
+	if err != nil {
+		return "", &ErrGetRemoteURLFailed{Detail: err.Error(), Err: err}
+	}
+	return strings.TrimSpace(string(output)), nil
 }
 
 func (c *Client) Checkout(ctx context.Context, branch string) error {
-	cmd := exec.CommandContext(ctx, "git", "checkout", branch)
-	if err := cmd.Run(); err != nil {
-		return &ErrCheckoutFailed{Branch: branch, Detail: err.Error()}
+	if _, err := c.run(ctx, nil, "checkout", branch); err != nil {
+		return &ErrCheckoutFailed{Branch: branch, Detail: err.Error(), Err: err}
 	}
 	return nil
 }
 
 func (c *Client) Pull(ctx context.Context, remote, branch string) error {
-	cmd := exec.CommandContext(ctx, "git", "pull", remote, branch)
-	if err := cmd.Run(); err != nil {
-		return &ErrPullFailed{Remote: remote, Branch: branch, Detail: err.Error()}
+	if _, err := c.run(ctx, nil, "pull", remote, branch); err != nil {
+		return &ErrPullFailed{Remote: remote, Branch: branch, Detail: err.Error(), Err: err}
 	}
 	return nil
 }
 
 func (c *Client) Push(ctx context.Context, remote, branch string) error {
-	cmd := exec.CommandContext(ctx, "git", "push", "-u", remote, branch)
-	if err := cmd.Run(); err != nil {
-		return &ErrPushFailed{Remote: remote, Branch: branch, Detail: err.Error()}
+	if _, err := c.run(ctx, nil, "push", "-u", remote, branch); err != nil {
+		return &ErrPushFailed{Remote: remote, Branch: branch, Detail: err.Error(), Err: err}
+	}
+	return nil
+}
+
+func (c *Client) ForcePush(ctx context.Context, remote, branch string) error {
+	if _, err := c.run(ctx, nil, "push", "--force", "-u", remote, branch); err != nil {
+		return &ErrPushFailed{Remote: remote, Branch: branch, Detail: err.Error(), Err: err}
 	}
 	return nil
 }
 
 func (c *Client) HasBranch(ctx context.Context, name string) bool {
-	cmd := exec.CommandContext(ctx, "git", "show-ref", "--verify", "--quiet", "refs/heads/"+name) // #nosec G204
-	return cmd.Run() == nil
+	_, err := c.run(ctx, nil, "show-ref", "--verify", "--quiet", "refs/heads/"+name)
+	return err == nil
+}
+
+func (c *Client) HasRemoteBranch(ctx context.Context, remote, name string) bool {
+	_, err := c.run(ctx, nil, "ls-remote", "--exit-code", "--heads", remote, "refs/heads/"+name)
+	return err == nil
 }
 
 func (c *Client) DeleteBranch(ctx context.Context, name string) error {
-	cmd := exec.CommandContext(ctx, "git", "branch", "-D", name)
-	if err := cmd.Run(); err != nil {
-		return &ErrDeleteBranchFailed{Branch: name, Detail: err.Error()}
+	if _, err := c.run(ctx, nil, "branch", "-D", name); err != nil {
+		return &ErrDeleteBranchFailed{Branch: name, Detail: err.Error(), Err: err}
+	}
+	return nil
+}
+
+func (c *Client) DeleteRemoteBranch(ctx context.Context, remote, name string) error {
+	if _, err := c.run(ctx, nil, "push", remote, "--delete", name); err != nil {
+		return &ErrDeleteRemoteBranchFailed{Remote: remote, Branch: name, Detail: err.Error(), Err: err}
 	}
 	return nil
 }
 
 func (c *Client) IsInRepo(ctx context.Context) bool {
-	cmd := exec.CommandContext(ctx, "git", "rev-parse", "--git-dir")
-	return cmd.Run() == nil
+	_, err := c.run(ctx, nil, "rev-parse", "--git-dir")
+	return err == nil
+}
+
+func (c *Client) WriteBlobRef(ctx context.Context, ref string, data []byte) error {
+	output, err := c.run(ctx, data, "hash-object", "-w", "--stdin")
+	if err != nil {
+		return &ErrWriteRefFailed{Ref: ref, Detail: err.Error(), Err: err}
+	}
+
+	sha := strings.TrimSpace(string(output))
+	if _, err := c.run(ctx, nil, "update-ref", ref, sha); err != nil {
+		return &ErrWriteRefFailed{Ref: ref, Detail: err.Error(), Err: err}
+	}
+	return nil
+}
+
+func (c *Client) ReadBlobRefs(ctx context.Context, prefix string) (map[string][]byte, error) {
+	output, err := c.run(ctx, nil, "for-each-ref", "--format=%(refname)", prefix)
+	if err != nil {
+		return nil, &ErrReadRefFailed{Ref: prefix, Detail: err.Error(), Err: err}
+	}
+
+	refs := make(map[string][]byte)
+	for _, ref := range strings.Fields(string(output)) {
+		data, err := c.run(ctx, nil, "cat-file", "blob", ref)
+		if err != nil {
+			return nil, &ErrReadRefFailed{Ref: ref, Detail: err.Error(), Err: err}
+		}
+		refs[ref] = data
+	}
+	return refs, nil
+}
+
+func (c *Client) DeleteRef(ctx context.Context, ref string) error {
+	if _, err := c.run(ctx, nil, "update-ref", "-d", ref); err != nil {
+		return &ErrWriteRefFailed{Ref: ref, Detail: err.Error(), Err: err}
+	}
+	return nil
+}
+
+// Config returns the value of a git config key, or "" if it is not set.
+func (c *Client) Config(ctx context.Context, key string) (string, error) {
+	output, err := c.run(ctx, nil, "config", "--get", key)
+	if err != nil {
+		var cmdErr *ErrCommand
+		if errors.As(err, &cmdErr) && cmdErr.ExitCode == 1 {
+			return "", nil
+		}
+		return "", &ErrReadConfigFailed{Key: key, Detail: err.Error(), Err: err}
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// ConfigAll returns every value of a multi-valued git config key, or none if
+// it is not set.
+func (c *Client) ConfigAll(ctx context.Context, key string) ([]string, error) {
+	output, err := c.run(ctx, nil, "config", "--get-all", key)
+	if err != nil {
+		var cmdErr *ErrCommand
+		if errors.As(err, &cmdErr) && cmdErr.ExitCode == 1 {
+			return nil, nil
+		}
+		return nil, &ErrReadConfigFailed{Key: key, Detail: err.Error(), Err: err}
+	}
+	return strings.Split(strings.TrimRight(string(output), "\n"), "\n"), nil
 }
 
 func (c *Client) CurrentBranchResult(ctx context.Context) *BranchResult {
@@ -5,8 +5,12 @@ import (
 	"errors"
 	"os"
 	"os/exec"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/user/git-mfpr/internal/runner"
 )
 
 // runGitCommand is a helper function for tests to run git commands and handle errors
@@ -64,8 +68,26 @@ func TestClient_CurrentRepo(t *testing.T) {
 			errType:   &ErrInvalidRemoteURL{},
 		},
 		{
-			name:      "Non-GitHub URL",
+			name:      "GitLab URL",
 			remoteURL: "https://gitlab.com/owner/repo.git",
+			wantOwner: "owner",
+			wantRepo:  "repo",
+		},
+		{
+			name:      "GitLab nested group over SSH",
+			remoteURL: "git@gitlab.example.com:group/subgroup/repo.git",
+			wantOwner: "group/subgroup",
+			wantRepo:  "repo",
+		},
+		{
+			name:      "Gitea on a custom port",
+			remoteURL: "http://localhost:3000/owner/repo.git",
+			wantOwner: "owner",
+			wantRepo:  "repo",
+		},
+		{
+			name:      "Nested path on GitHub",
+			remoteURL: "https://github.com/owner/repo/extra",
 			wantErr:   true,
 			errType:   &ErrInvalidRemoteURL{},
 		},
@@ -886,3 +908,315 @@ func TestClient_OperationResults_Error(t *testing.T) {
 		t.Error("DeleteBranchResult() should indicate error")
 	}
 }
+
+func TestClient_BlobRefs(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	defer os.Chdir(oldWd)
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+
+	runGitCommand(t, "init", "-b", "main")
+
+	client := New()
+
+	refs, err := client.ReadBlobRefs(ctx, "refs/mfpr/")
+	if err != nil {
+		t.Fatalf("ReadBlobRefs() error = %v", err)
+	}
+	if len(refs) != 0 {
+		t.Errorf("ReadBlobRefs() returned %d refs, want 0", len(refs))
+	}
+
+	if err := client.WriteBlobRef(ctx, "refs/mfpr/123", []byte(`{"number":123}`)); err != nil {
+		t.Fatalf("WriteBlobRef() error = %v", err)
+	}
+	if err := client.WriteBlobRef(ctx, "refs/mfpr/124", []byte(`{"number":124}`)); err != nil {
+		t.Fatalf("WriteBlobRef() error = %v", err)
+	}
+	if err := client.WriteBlobRef(ctx, "refs/mfpr/123", []byte(`{"number":123,"updated":true}`)); err != nil {
+		t.Fatalf("WriteBlobRef() overwrite error = %v", err)
+	}
+
+	refs, err = client.ReadBlobRefs(ctx, "refs/mfpr/")
+	if err != nil {
+		t.Fatalf("ReadBlobRefs() error = %v", err)
+	}
+	if len(refs) != 2 {
+		t.Fatalf("ReadBlobRefs() returned %d refs, want 2", len(refs))
+	}
+	if got := string(refs["refs/mfpr/123"]); got != `{"number":123,"updated":true}` {
+		t.Errorf("refs/mfpr/123 = %q, want overwritten record", got)
+	}
+	if got := string(refs["refs/mfpr/124"]); got != `{"number":124}` {
+		t.Errorf("refs/mfpr/124 = %q", got)
+	}
+}
+
+func TestClient_WriteBlobRef_Error(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	defer os.Chdir(oldWd)
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+
+	client := New()
+	err := client.WriteBlobRef(ctx, "refs/mfpr/1", []byte("data"))
+	if err == nil {
+		t.Fatal("WriteBlobRef() expected error outside a git repository")
+	}
+	if _, ok := err.(*ErrWriteRefFailed); !ok {
+		t.Errorf("Expected ErrWriteRefFailed, got %T", err)
+	}
+}
+
+func TestClient_HasRemoteBranch(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	defer os.Chdir(oldWd)
+
+	remoteDir := tmpDir + "/remote.git"
+	runGitCommand(t, "init", "--bare", remoteDir)
+
+	workDir := tmpDir + "/work"
+	if err := os.Mkdir(workDir, 0o750); err != nil {
+		t.Fatalf("Failed to create work dir: %v", err)
+	}
+	if err := os.Chdir(workDir); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+
+	runGitCommand(t, "init", "-b", "main")
+	runGitCommand(t, "config", "user.email", "test@example.com")
+	runGitCommand(t, "config", "user.name", "Test User")
+	runGitCommand(t, "remote", "add", "origin", remoteDir)
+
+	if err := os.WriteFile("test.txt", []byte("test"), 0o600); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	runGitCommand(t, "add", ".")
+	runGitCommand(t, "commit", "-m", "initial")
+	runGitCommand(t, "push", "origin", "main")
+
+	client := New()
+
+	if !client.HasRemoteBranch(ctx, "origin", "main") {
+		t.Error("HasRemoteBranch() returned false for pushed branch")
+	}
+	if client.HasRemoteBranch(ctx, "origin", "migrated-1") {
+		t.Error("HasRemoteBranch() returned true for missing branch")
+	}
+}
+
+func TestClient_DeleteRemoteBranch(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	defer os.Chdir(oldWd)
+
+	remoteDir := tmpDir + "/remote.git"
+	runGitCommand(t, "init", "--bare", remoteDir)
+
+	workDir := tmpDir + "/work"
+	if err := os.Mkdir(workDir, 0o750); err != nil {
+		t.Fatalf("Failed to create work dir: %v", err)
+	}
+	if err := os.Chdir(workDir); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+
+	runGitCommand(t, "init", "-b", "main")
+	runGitCommand(t, "config", "user.email", "test@example.com")
+	runGitCommand(t, "config", "user.name", "Test User")
+	runGitCommand(t, "remote", "add", "origin", remoteDir)
+
+	if err := os.WriteFile("test.txt", []byte("test"), 0o600); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	runGitCommand(t, "add", ".")
+	runGitCommand(t, "commit", "-m", "initial")
+	runGitCommand(t, "push", "origin", "main", "main:migrated-1")
+
+	client := New()
+
+	if err := client.DeleteRemoteBranch(ctx, "origin", "migrated-1"); err != nil {
+		t.Fatalf("DeleteRemoteBranch() error = %v", err)
+	}
+	if client.HasRemoteBranch(ctx, "origin", "migrated-1") {
+		t.Error("migrated-1 should be deleted from origin")
+	}
+
+	err := client.DeleteRemoteBranch(ctx, "origin", "migrated-1")
+	if _, ok := err.(*ErrDeleteRemoteBranchFailed); !ok {
+		t.Errorf("Expected ErrDeleteRemoteBranchFailed, got %T", err)
+	}
+}
+
+func TestClient_DeleteRef(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	defer os.Chdir(oldWd)
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+
+	runGitCommand(t, "init", "-b", "main")
+
+	client := New()
+	if err := client.WriteBlobRef(ctx, "refs/mfpr/1", []byte("data")); err != nil {
+		t.Fatalf("WriteBlobRef() error = %v", err)
+	}
+	if err := client.DeleteRef(ctx, "refs/mfpr/1"); err != nil {
+		t.Fatalf("DeleteRef() error = %v", err)
+	}
+
+	refs, err := client.ReadBlobRefs(ctx, "refs/mfpr/")
+	if err != nil {
+		t.Fatalf("ReadBlobRefs() error = %v", err)
+	}
+	if len(refs) != 0 {
+		t.Errorf("ReadBlobRefs() returned %v after DeleteRef", refs)
+	}
+}
+
+func TestClient_Config(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	defer os.Chdir(oldWd)
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+
+	runGitCommand(t, "init", "-b", "main")
+	runGitCommand(t, "config", "mfpr.timeout", "45s")
+
+	client := New()
+	value, err := client.Config(ctx, "mfpr.timeout")
+	if err != nil {
+		t.Fatalf("Config() error = %v", err)
+	}
+	if value != "45s" {
+		t.Errorf("Config() = %q, want %q", value, "45s")
+	}
+
+	value, err = client.Config(ctx, "mfpr.unset")
+	if err != nil {
+		t.Fatalf("Config() unset key error = %v", err)
+	}
+	if value != "" {
+		t.Errorf("Config() unset key = %q, want empty", value)
+	}
+
+	runGitCommand(t, "config", "--add", "mfpr.hook.pre-push", "make test")
+	runGitCommand(t, "config", "--add", "mfpr.hook.pre-push", "make lint")
+	values, err := client.ConfigAll(ctx, "mfpr.hook.pre-push")
+	if err != nil {
+		t.Fatalf("ConfigAll() error = %v", err)
+	}
+	if want := []string{"make test", "make lint"}; !reflect.DeepEqual(values, want) {
+		t.Errorf("ConfigAll() = %q, want %q", values, want)
+	}
+
+	values, err = client.ConfigAll(ctx, "mfpr.hook.unset")
+	if err != nil || values != nil {
+		t.Errorf("ConfigAll() unset key = %q, %v; want none", values, err)
+	}
+}
+
+func TestClient_Timeout(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	defer os.Chdir(oldWd)
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+
+	runGitCommand(t, "init", "-b", "main")
+
+	client := NewWithOptions(WithTimeout(time.Nanosecond))
+	err := client.Checkout(context.Background(), "main")
+	if err == nil {
+		t.Fatal("Checkout() expected error with an expired timeout")
+	}
+	if !strings.Contains(err.Error(), "timed out after 1ns") {
+		t.Errorf("Checkout() error = %v, want timeout detail", err)
+	}
+}
+
+func TestClient_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := New().Pull(ctx, "origin", "main")
+	if err == nil {
+		t.Fatal("Pull() expected error with a cancelled context")
+	}
+	if !strings.Contains(err.Error(), "cancelled") {
+		t.Errorf("Pull() error = %v, want cancellation detail", err)
+	}
+}
+
+func TestClient_WithDir(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+	runGitCommand(t, "init", "-b", "trunk", tmpDir)
+	runGitCommand(t, "-C", tmpDir, "remote", "add", "origin", "https://github.com/owner/elsewhere.git")
+
+	client := NewWithOptions(WithDir(tmpDir))
+	owner, repo, err := client.CurrentRepo(ctx)
+	if err != nil {
+		t.Fatalf("CurrentRepo() error = %v", err)
+	}
+	if owner != "owner" || repo != "elsewhere" {
+		t.Errorf("CurrentRepo() = %s/%s, want owner/elsewhere", owner, repo)
+	}
+	if !client.IsInRepo(ctx) {
+		t.Error("IsInRepo() = false for the configured directory")
+	}
+}
+
+func TestClient_FakeRunner(t *testing.T) {
+	ctx := context.Background()
+	fake := runner.NewFake(
+		runner.Entry{Command: "git rev-parse --abbrev-ref HEAD", Stdout: "feature\n"},
+		runner.Entry{Command: "git rev-parse --verify 'feature^{commit}'", Stdout: "0123456789abcdef0123456789abcdef01234567\n"},
+		runner.Entry{Command: "git rev-list --count main..feature", Stdout: "3\n"},
+		runner.Entry{
+			Command:  "git push -u origin feature",
+			Stderr:   "remote: error: GH006: Protected branch update failed for refs/heads/feature.\n",
+			ExitCode: 1,
+		},
+	)
+	client := NewWithOptions(WithRunner(fake))
+
+	branch, err := client.CurrentBranch(ctx)
+	if err != nil || branch != "feature" {
+		t.Fatalf("CurrentBranch() = %q, %v; want feature", branch, err)
+	}
+
+	sha, err := client.RevParse(ctx, "feature")
+	if err != nil || sha != "0123456789abcdef0123456789abcdef01234567" {
+		t.Errorf("RevParse() = %q, %v; want the branch's commit", sha, err)
+	}
+
+	count, err := client.CountCommits(ctx, "main..feature")
+	if err != nil || count != 3 {
+		t.Errorf("CountCommits() = %d, %v; want 3", count, err)
+	}
+
+	err = client.Push(ctx, "origin", "feature")
+	var protected *ErrProtectedBranch
+	if !errors.As(err, &protected) || protected.Branch != "feature" {
+		t.Errorf("Push() error = %v, want ErrProtectedBranch for feature", err)
+	}
+	if got := len(fake.Calls()); got != 4 {
+		t.Errorf("ran %d commands, want 4", got)
+	}
+}
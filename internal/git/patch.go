@@ -0,0 +1,236 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Reject is part of a patch that git apply could not apply. Hunk is the
+// 1-based hunk number within File, or 0 when none of File could be applied.
+type Reject struct {
+	File   string
+	Hunk   int
+	Header string
+}
+
+func (r Reject) String() string {
+	if r.Hunk == 0 {
+		return fmt.Sprintf("%s: whole file", r.File)
+	}
+	if r.Header == "" {
+		return fmt.Sprintf("%s: hunk #%d", r.File, r.Hunk)
+	}
+	return fmt.Sprintf("%s: hunk #%d %s", r.File, r.Hunk, r.Header)
+}
+
+func (c *Client) MergeBase(ctx context.Context, a, b string) (string, error) {
+	output, err := c.run(ctx, nil, "merge-base", a, b)
+	if err != nil {
+		return "", &ErrPatchFailed{Op: fmt.Sprintf("find the merge base of %s and %s", a, b), Detail: err.Error(), Err: err}
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// FormatPatch exports the commits in revRange as an mbox patch series.
+func (c *Client) FormatPatch(ctx context.Context, revRange string) ([]byte, error) {
+	output, err := c.run(ctx, nil, "format-patch", "--stdout", revRange)
+	if err != nil {
+		return nil, &ErrPatchFailed{Op: "export " + revRange, Detail: err.Error(), Err: err}
+	}
+	return output, nil
+}
+
+// ResetBranch checks out branch, creating it or moving it to startPoint.
+func (c *Client) ResetBranch(ctx context.Context, branch, startPoint string) error {
+	if _, err := c.run(ctx, nil, "checkout", "-B", branch, startPoint); err != nil {
+		return &ErrCheckoutFailed{Branch: branch, Detail: err.Error(), Err: err}
+	}
+	return nil
+}
+
+// ApplyMailbox applies an mbox patch series to the current branch with git am,
+// keeping each commit's author and date. When a patch does not apply, git am
+// stops with that patch in progress; see CurrentPatch.
+func (c *Client) ApplyMailbox(ctx context.Context, mbox []byte) error {
+	if _, err := c.run(ctx, mbox, "am"); err != nil {
+		return &ErrPatchFailed{Op: "apply patches", Detail: err.Error(), Err: err}
+	}
+	return nil
+}
+
+// CurrentPatch returns the diff of the patch git am stopped on. It fails if no
+// git am is in progress.
+func (c *Client) CurrentPatch(ctx context.Context) ([]byte, error) {
+	output, err := c.run(ctx, nil, "am", "--show-current-patch=diff")
+	if err != nil {
+		return nil, &ErrPatchFailed{Op: "read the current patch", Detail: err.Error(), Err: err}
+	}
+	return output, nil
+}
+
+var (
+	rejectingFile = regexp.MustCompile(`^Applying patch (.+) with \d+ rejects?\.\.\.$`)
+	rejectedHunk  = regexp.MustCompile(`^Rejected hunk #(\d+)\.$`)
+	rejectedFile  = regexp.MustCompile(`^error: (.+): (?:No such file or directory|does not exist in index|already exists in working directory)$`)
+)
+
+// ApplyWithRejects applies as much of patch as it can to the working tree,
+// leaving the hunks that do not apply in .rej files, and stages the result.
+func (c *Client) ApplyWithRejects(ctx context.Context, patch []byte) ([]Reject, error) {
+	_, err := c.run(ctx, patch, "apply", "--reject")
+
+	var rejects []Reject
+	if err != nil {
+		var cmdErr *ErrCommand
+		if errors.As(err, &cmdErr) && ctx.Err() == nil {
+			rejects = parseRejects(cmdErr.Output, patch)
+		}
+		if len(rejects) == 0 {
+			return nil, &ErrPatchFailed{Op: "apply patch", Detail: err.Error(), Err: err}
+		}
+	}
+
+	skip := map[string]bool{}
+	for _, reject := range rejects {
+		if reject.Hunk == 0 {
+			skip[reject.File] = true
+		}
+	}
+	var paths []string
+	for _, path := range patchPaths(patch) {
+		if !skip[path] {
+			paths = append(paths, path)
+		}
+	}
+	if len(paths) > 0 {
+		if _, err := c.run(ctx, nil, append([]string{"add", "-A", "--"}, paths...)...); err != nil {
+			return rejects, &ErrPatchFailed{Op: "stage the applied patch", Detail: err.Error(), Err: err}
+		}
+	}
+	return rejects, nil
+}
+
+// ContinueApply commits the staged patch and carries on with the rest of the
+// series. A patch that left nothing to commit is skipped.
+func (c *Client) ContinueApply(ctx context.Context) error {
+	_, err := c.run(ctx, nil, "am", "--continue")
+	var cmdErr *ErrCommand
+	if err != nil && errors.As(err, &cmdErr) && strings.Contains(cmdErr.Output, "No changes") {
+		_, err = c.run(ctx, nil, "am", "--skip")
+	}
+	if err != nil {
+		return &ErrPatchFailed{Op: "apply patches", Detail: err.Error(), Err: err}
+	}
+	return nil
+}
+
+// AbortApply stops a git am in progress and restores the branch it started on.
+func (c *Client) AbortApply(ctx context.Context) error {
+	if _, err := c.run(ctx, nil, "am", "--abort"); err != nil {
+		return &ErrPatchFailed{Op: "abort applying patches", Detail: err.Error(), Err: err}
+	}
+	return nil
+}
+
+// parseRejects reads the hunks git apply --reject could not apply from its
+// output, taking each hunk's @@ line from patch.
+func parseRejects(output string, patch []byte) []Reject {
+	headers := hunkHeaders(patch)
+
+	var rejects []Reject
+	file := ""
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if m := rejectingFile.FindStringSubmatch(line); m != nil {
+			file = m[1]
+			continue
+		}
+		if m := rejectedFile.FindStringSubmatch(line); m != nil {
+			rejects = append(rejects, Reject{File: m[1]})
+			continue
+		}
+		if m := rejectedHunk.FindStringSubmatch(line); m != nil && file != "" {
+			n, _ := strconv.Atoi(m[1])
+			reject := Reject{File: file, Hunk: n}
+			if n <= len(headers[file]) {
+				reject.Header = headers[file][n-1]
+			}
+			rejects = append(rejects, reject)
+		}
+	}
+	return rejects
+}
+
+// patchFile is one file section of a diff.
+type patchFile struct {
+	oldPath string
+	newPath string
+	hunks   []string
+}
+
+// parsePatch splits a diff into its file sections. Paths come from the ---,
+// +++, rename and copy lines, without their a/ and b/ prefixes.
+func parsePatch(patch []byte) []patchFile {
+	var files []patchFile
+	var current *patchFile
+	for _, line := range strings.Split(string(patch), "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			files = append(files, patchFile{})
+			current = &files[len(files)-1]
+		case current == nil:
+		case strings.HasPrefix(line, "@@ "):
+			current.hunks = append(current.hunks, line)
+		case len(current.hunks) > 0:
+			// Hunk bodies can contain lines that look like headers.
+		case strings.HasPrefix(line, "--- "):
+			current.oldPath = diffPath(line[4:], "a/", current.oldPath)
+		case strings.HasPrefix(line, "+++ "):
+			current.newPath = diffPath(line[4:], "b/", current.newPath)
+		case strings.HasPrefix(line, "rename from "), strings.HasPrefix(line, "copy from "):
+			current.oldPath = line[strings.Index(line, " from ")+6:]
+		case strings.HasPrefix(line, "rename to "), strings.HasPrefix(line, "copy to "):
+			current.newPath = line[strings.Index(line, " to ")+4:]
+		}
+	}
+	return files
+}
+
+func diffPath(name, prefix, fallback string) string {
+	name = strings.TrimSuffix(name, "\t")
+	if name == "/dev/null" {
+		return fallback
+	}
+	return strings.TrimPrefix(name, prefix)
+}
+
+func hunkHeaders(patch []byte) map[string][]string {
+	headers := map[string][]string{}
+	for _, file := range parsePatch(patch) {
+		for _, path := range []string{file.oldPath, file.newPath} {
+			if path != "" {
+				headers[path] = file.hunks
+			}
+		}
+	}
+	return headers
+}
+
+// patchPaths lists every path a patch touches.
+func patchPaths(patch []byte) []string {
+	seen := map[string]bool{}
+	var paths []string
+	for _, file := range parsePatch(patch) {
+		for _, path := range []string{file.oldPath, file.newPath} {
+			if path != "" && !seen[path] {
+				seen[path] = true
+				paths = append(paths, path)
+			}
+		}
+	}
+	return paths
+}
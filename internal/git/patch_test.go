@@ -0,0 +1,166 @@
+package git
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const samplePatch = `diff --git a/f.txt b/f.txt
+index 1111111..2222222 100644
+--- a/f.txt
++++ b/f.txt
+@@ -1,3 +1,3 @@
+ a
+-b
++B
+ c
+@@ -9,3 +9,3 @@ h
+ i
+--- not a header
++K
+diff --git a/old.txt b/new.txt
+similarity index 100%
+rename from old.txt
+rename to new.txt
+diff --git a/gone.txt b/gone.txt
+deleted file mode 100644
+--- a/gone.txt
++++ /dev/null
+@@ -1 +0,0 @@
+-bye
+`
+
+func TestParsePatch(t *testing.T) {
+	want := []patchFile{
+		{oldPath: "f.txt", newPath: "f.txt", hunks: []string{"@@ -1,3 +1,3 @@", "@@ -9,3 +9,3 @@ h"}},
+		{oldPath: "old.txt", newPath: "new.txt"},
+		{oldPath: "gone.txt", hunks: []string{"@@ -1 +0,0 @@"}},
+	}
+	if got := parsePatch([]byte(samplePatch)); !reflect.DeepEqual(got, want) {
+		t.Errorf("parsePatch() = %+v, want %+v", got, want)
+	}
+
+	wantPaths := []string{"f.txt", "old.txt", "new.txt", "gone.txt"}
+	if got := patchPaths([]byte(samplePatch)); !reflect.DeepEqual(got, wantPaths) {
+		t.Errorf("patchPaths() = %v, want %v", got, wantPaths)
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	output := `Checking patch f.txt...
+error: while searching for:
+i
+error: patch failed: f.txt:9
+Checking patch gone.txt...
+error: gone.txt: No such file or directory
+Applying patch f.txt with 1 reject...
+Hunk #1 applied cleanly.
+Rejected hunk #2.`
+
+	want := []Reject{
+		{File: "gone.txt"},
+		{File: "f.txt", Hunk: 2, Header: "@@ -9,3 +9,3 @@ h"},
+	}
+	if got := parseRejects(output, []byte(samplePatch)); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseRejects() = %+v, want %+v", got, want)
+	}
+}
+
+func TestReject_String(t *testing.T) {
+	tests := []struct {
+		reject Reject
+		want   string
+	}{
+		{Reject{File: "a.go"}, "a.go: whole file"},
+		{Reject{File: "a.go", Hunk: 2}, "a.go: hunk #2"},
+		{Reject{File: "a.go", Hunk: 2, Header: "@@ -9,3 +9,3 @@"}, "a.go: hunk #2 @@ -9,3 +9,3 @@"},
+	}
+	for _, tt := range tests {
+		if got := tt.reject.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestClient_ApplyPatches(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	defer os.Chdir(oldWd)
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+	for _, key := range []string{"GIT_AUTHOR_NAME", "GIT_COMMITTER_NAME"} {
+		t.Setenv(key, "Test")
+	}
+	for _, key := range []string{"GIT_AUTHOR_EMAIL", "GIT_COMMITTER_EMAIL"} {
+		t.Setenv(key, "test@example.com")
+	}
+
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(name, []byte(content), 0o600); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	runGitCommand(t, "init", "-b", "main")
+	write("f.txt", "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\n")
+	runGitCommand(t, "add", ".")
+	runGitCommand(t, "commit", "-m", "init")
+
+	runGitCommand(t, "checkout", "-b", "pr")
+	write("f.txt", "a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nK\nl\n")
+	write("g.txt", "new\n")
+	runGitCommand(t, "add", ".")
+	runGitCommand(t, "commit", "-m", "change")
+
+	runGitCommand(t, "checkout", "main")
+	write("f.txt", "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nX\nl\n")
+	runGitCommand(t, "commit", "-am", "conflicting")
+
+	client := New()
+	base, err := client.MergeBase(ctx, "main", "pr")
+	if err != nil {
+		t.Fatalf("MergeBase() error = %v", err)
+	}
+	mbox, err := client.FormatPatch(ctx, base+"..pr")
+	if err != nil || !strings.Contains(string(mbox), "Subject: [PATCH] change") {
+		t.Fatalf("FormatPatch() = %q, %v", mbox, err)
+	}
+	if err := client.ResetBranch(ctx, "replay", "main"); err != nil {
+		t.Fatalf("ResetBranch() error = %v", err)
+	}
+
+	if err := client.ApplyMailbox(ctx, mbox); err == nil {
+		t.Fatal("ApplyMailbox() expected the conflicting patch to stop git am")
+	}
+	patch, err := client.CurrentPatch(ctx)
+	if err != nil {
+		t.Fatalf("CurrentPatch() error = %v", err)
+	}
+	rejects, err := client.ApplyWithRejects(ctx, patch)
+	if err != nil {
+		t.Fatalf("ApplyWithRejects() error = %v", err)
+	}
+	if len(rejects) != 1 || rejects[0].File != "f.txt" || rejects[0].Hunk != 2 {
+		t.Errorf("ApplyWithRejects() = %+v, want hunk #2 of f.txt", rejects)
+	}
+	if err := client.ContinueApply(ctx); err != nil {
+		t.Fatalf("ContinueApply() error = %v", err)
+	}
+
+	content, _ := os.ReadFile("f.txt")
+	if string(content) != "a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nX\nl\n" {
+		t.Errorf("f.txt = %q, want the first hunk applied and the second rejected", content)
+	}
+	if _, err := os.Stat("g.txt"); err != nil {
+		t.Errorf("g.txt was not applied: %v", err)
+	}
+	if _, err := client.CurrentPatch(ctx); err == nil {
+		t.Error("CurrentPatch() should fail once git am has finished")
+	}
+}
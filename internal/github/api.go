@@ -0,0 +1,38 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+)
+
+// WithHost sends gh api requests to host, such as a GitHub Enterprise
+// server, instead of gh's default host.
+func WithHost(host string) Option {
+	return func(c *Client) {
+		c.host = host
+	}
+}
+
+// apiArgs is the gh api command line for path on the client's host. gh
+// authenticates it, so no token passes through git-mfpr.
+func (c *Client) apiArgs(path string, args ...string) []string {
+	args = append([]string{"api", path}, args...)
+	if c.host != "" && !strings.EqualFold(c.host, "github.com") {
+		args = append(args, "--hostname", c.host)
+	}
+	return args
+}
+
+// apiGet fetches path from the REST API with gh api and decodes the JSON
+// response into out.
+func (c *Client) apiGet(ctx context.Context, path string, out interface{}) error {
+	result, detail := c.run(ctx, c.apiArgs(path)...)
+	if result.Err != nil {
+		return &ErrAPIRequestFailed{Path: path, Detail: detail, Err: result.Err}
+	}
+	if err := json.Unmarshal(result.Stdout, out); err != nil {
+		return &ErrPRParseFailed{Detail: err.Error(), Err: err}
+	}
+	return nil
+}
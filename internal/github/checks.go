@@ -0,0 +1,62 @@
+package github
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/user/git-mfpr/internal/forge"
+)
+
+type (
+	Check  = forge.Check
+	Checks = forge.Checks
+)
+
+type apiCombinedStatus struct {
+	Statuses []struct {
+		Context   string `json:"context"`
+		State     string `json:"state"`
+		TargetURL string `json:"target_url"`
+	} `json:"statuses"`
+}
+
+type apiCheckRuns struct {
+	CheckRuns []struct {
+		Name       string `json:"name"`
+		Status     string `json:"status"`
+		Conclusion string `json:"conclusion"`
+		HTMLURL    string `json:"html_url"`
+	} `json:"check_runs"`
+}
+
+// GetChecks fetches the commit statuses and check runs for sha through gh
+// api.
+func (c *Client) GetChecks(ctx context.Context, owner, repo, sha string) (*Checks, error) {
+	var status apiCombinedStatus
+	if err := c.apiGet(ctx, fmt.Sprintf("repos/%s/%s/commits/%s/status", owner, repo, sha), &status); err != nil {
+		return nil, err
+	}
+	var runs apiCheckRuns
+	if err := c.apiGet(ctx, fmt.Sprintf("repos/%s/%s/commits/%s/check-runs?per_page=100", owner, repo, sha), &runs); err != nil {
+		return nil, err
+	}
+
+	checks := &Checks{SHA: sha}
+	rollup := make([]ghCheck, 0, len(status.Statuses)+len(runs.CheckRuns))
+	for _, s := range status.Statuses {
+		check := ghCheck{State: s.State}
+		rollup = append(rollup, check)
+		checks.Checks = append(checks.Checks, Check{Name: s.Context, State: summarizeChecks([]ghCheck{check}), URL: s.TargetURL})
+	}
+	for _, run := range runs.CheckRuns {
+		check := ghCheck{Status: run.Status, Conclusion: run.Conclusion}
+		rollup = append(rollup, check)
+		checks.Checks = append(checks.Checks, Check{Name: run.Name, State: summarizeChecks([]ghCheck{check}), URL: run.HTMLURL})
+	}
+
+	checks.State = summarizeChecks(rollup)
+	if checks.State == "" {
+		checks.State = CheckPending
+	}
+	return checks, nil
+}
@@ -0,0 +1,122 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/user/git-mfpr/internal/runner"
+)
+
+// checksRunner answers the two gh api requests GetChecks makes for
+// owner/repo@abc123.
+func checksRunner(status, checkRuns string) *runner.Fake {
+	return runner.NewFake(
+		runner.Entry{Command: "gh api repos/owner/repo/commits/abc123/status", Stdout: status},
+		runner.Entry{Command: "gh api 'repos/owner/repo/commits/abc123/check-runs?per_page=100'", Stdout: checkRuns},
+	)
+}
+
+func TestClient_GetChecks(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     string
+		checkRuns  string
+		wantState  string
+		wantChecks []Check
+		wantString string
+	}{
+		{
+			name:       "no checks yet",
+			status:     `{"state":"pending","statuses":[]}`,
+			checkRuns:  `{"total_count":0,"check_runs":[]}`,
+			wantState:  CheckPending,
+			wantString: "no checks reported yet",
+		},
+		{
+			name:      "running",
+			status:    `{"state":"success","statuses":[{"context":"ci/legacy","state":"success","target_url":"https://ci.example.com/1"}]}`,
+			checkRuns: `{"check_runs":[{"name":"test","status":"in_progress","conclusion":null,"html_url":"https://github.com/owner/repo/runs/2"}]}`,
+			wantState: CheckPending,
+			wantChecks: []Check{
+				{Name: "ci/legacy", State: CheckSuccess, URL: "https://ci.example.com/1"},
+				{Name: "test", State: CheckPending, URL: "https://github.com/owner/repo/runs/2"},
+			},
+			wantString: "1 passed, 1 pending",
+		},
+		{
+			name:      "passed",
+			status:    `{"state":"pending","statuses":[]}`,
+			checkRuns: `{"check_runs":[{"name":"test","status":"completed","conclusion":"success"},{"name":"docs","status":"completed","conclusion":"skipped"}]}`,
+			wantState: CheckSuccess,
+			wantChecks: []Check{
+				{Name: "test", State: CheckSuccess},
+				{Name: "docs", State: CheckSuccess},
+			},
+			wantString: "2 passed",
+		},
+		{
+			name:      "failed",
+			status:    `{"state":"error","statuses":[{"context":"ci/legacy","state":"error"}]}`,
+			checkRuns: `{"check_runs":[{"name":"test","status":"queued"},{"name":"lint","status":"completed","conclusion":"timed_out"}]}`,
+			wantState: CheckFailure,
+			wantChecks: []Check{
+				{Name: "ci/legacy", State: CheckFailure},
+				{Name: "test", State: CheckPending},
+				{Name: "lint", State: CheckFailure},
+			},
+			wantString: "0 passed, 1 pending, 2 failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewWithOptions(WithRunner(checksRunner(tt.status, tt.checkRuns)))
+
+			checks, err := client.GetChecks(context.Background(), "owner", "repo", "abc123")
+			if err != nil {
+				t.Fatalf("GetChecks() error = %v", err)
+			}
+			if checks.SHA != "abc123" || checks.State != tt.wantState {
+				t.Errorf("GetChecks() = %s %s, want abc123 %s", checks.SHA, checks.State, tt.wantState)
+			}
+			if !reflect.DeepEqual(checks.Checks, tt.wantChecks) {
+				t.Errorf("GetChecks() checks = %+v, want %+v", checks.Checks, tt.wantChecks)
+			}
+			if got := checks.String(); got != tt.wantString {
+				t.Errorf("String() = %q, want %q", got, tt.wantString)
+			}
+		})
+	}
+}
+
+func TestClient_GetChecksErrors(t *testing.T) {
+	fake := runner.NewFake(runner.Entry{
+		Command:  "gh api repos/owner/repo/commits/abc123/status",
+		Stderr:   "gh: No commit found for SHA: abc123 (HTTP 422)\n",
+		ExitCode: 1,
+	})
+	client := NewWithOptions(WithRunner(fake))
+	_, err := client.GetChecks(context.Background(), "owner", "repo", "abc123")
+
+	var apiErr *ErrAPIRequestFailed
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("GetChecks() error = %v, want ErrAPIRequestFailed", err)
+	}
+	if want := "gh: No commit found for SHA: abc123 (HTTP 422)"; apiErr.Detail != want {
+		t.Errorf("Detail = %q, want %q", apiErr.Detail, want)
+	}
+}
+
+func TestClient_GetChecksEnterprise(t *testing.T) {
+	fake := runner.NewFake(
+		runner.Entry{Command: "gh api repos/owner/repo/commits/abc123/status --hostname github.example.com", Stdout: `{"statuses":[]}`},
+		runner.Entry{Command: "gh api 'repos/owner/repo/commits/abc123/check-runs?per_page=100' --hostname github.example.com", Stdout: `{"check_runs":[]}`},
+	)
+	client := NewWithOptions(WithRunner(fake), WithHost("github.example.com"))
+
+	if _, err := client.GetChecks(context.Background(), "owner", "repo", "abc123"); err != nil {
+		t.Errorf("GetChecks() error = %v, want the checks from the configured host", err)
+	}
+}
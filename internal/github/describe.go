@@ -0,0 +1,33 @@
+package github
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/user/git-mfpr/internal/runner"
+)
+
+// DescribeCheckoutPR is the gh command CheckoutPR runs.
+func (c *Client) DescribeCheckoutPR(owner, repo string, number int, branch string) string {
+	return runner.Cmd{Name: "gh", Args: []string{"pr", "checkout", strconv.Itoa(number),
+		"--repo", fmt.Sprintf("%s/%s", owner, repo), "-b", branch}}.String()
+}
+
+// DescribeGetPRPatch is the gh command GetPRPatch runs, piped to git am.
+func (c *Client) DescribeGetPRPatch(owner, repo string, number int) string {
+	args := c.apiArgs(fmt.Sprintf("repos/%s/%s/pulls/%d", owner, repo, number),
+		"-H", "Accept: application/vnd.github.patch")
+	return runner.Cmd{Name: "gh", Args: args}.String() + " | git am"
+}
+
+// DescribeCreatePR is the gh command CreatePR runs. The body is written with
+// \n escapes so that the command stays on one line.
+func (c *Client) DescribeCreatePR(owner, repo string, opts CreatePROptions) string {
+	return fmt.Sprintf("gh pr create --title %q --body %q --base %s --head %s --repo %s/%s",
+		opts.Title, opts.Body, opts.Base, opts.Head, owner, repo)
+}
+
+// DescribeClosePR is the gh command ClosePR runs.
+func (c *Client) DescribeClosePR(owner, repo string, number int) string {
+	return fmt.Sprintf("gh pr close %d --repo %s/%s --comment <link to replacement>", number, owner, repo)
+}
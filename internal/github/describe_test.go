@@ -0,0 +1,37 @@
+package github
+
+import (
+	"testing"
+
+	"github.com/user/git-mfpr/internal/forge"
+)
+
+func TestClient_Describe(t *testing.T) {
+	client := NewWithOptions(WithHost("github.example.com")).(forge.Describer)
+
+	tests := []struct {
+		got, want string
+	}{
+		{
+			client.DescribeCheckoutPR("owner", "repo", 42, "pr-42"),
+			"gh pr checkout 42 --repo owner/repo -b pr-42",
+		},
+		{
+			client.DescribeGetPRPatch("owner", "repo", 42),
+			"gh api repos/owner/repo/pulls/42 -H 'Accept: application/vnd.github.patch' --hostname github.example.com | git am",
+		},
+		{
+			client.DescribeCreatePR("owner", "repo", forge.CreatePROptions{Title: "Fix", Body: "Migrated from #42\nOriginal author: @dev", Base: "main", Head: "pr-42"}),
+			`gh pr create --title "Fix" --body "Migrated from #42\nOriginal author: @dev" --base main --head pr-42 --repo owner/repo`,
+		},
+		{
+			client.DescribeClosePR("owner", "repo", 42),
+			"gh pr close 42 --repo owner/repo --comment <link to replacement>",
+		},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("got %q, want %q", tt.got, tt.want)
+		}
+	}
+}
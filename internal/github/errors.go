@@ -1,57 +1,154 @@
 package github
 
-import "fmt"
+import (
+	"fmt"
 
+	"github.com/user/git-mfpr/internal/errs"
+	"github.com/user/git-mfpr/internal/forge"
+)
+
+// Errors shared with other forges.
 type (
-	ErrGHNotInstalled struct{}
+	ErrPRNotFound       = forge.ErrPRNotFound
+	ErrPRCheckoutFailed = forge.ErrPRCheckoutFailed
+	ErrPRPatchFailed    = forge.ErrPRPatchFailed
+)
 
-	ErrPRNotFound struct {
-		Number int
-		Owner  string
-		Repo   string
-	}
+type (
+	ErrGHNotInstalled struct{}
 
 	ErrPRFetchFailed struct {
 		Number int
 		Owner  string
 		Repo   string
 		Detail string
+		Err    error
 	}
 
 	ErrPRParseFailed struct {
 		Detail string
+		Err    error
 	}
 
-	ErrPRCheckoutFailed struct {
+	ErrPRCreateFailed struct {
+		Detail string
+		Err    error
+	}
+
+	ErrPRCloseFailed struct {
 		Number int
 		Detail string
+		Err    error
 	}
 
-	ErrPRCreateFailed struct {
+	ErrPRCommentFailed struct {
+		Number int
+		Detail string
+		Err    error
+	}
+
+	ErrAPIRequestFailed struct {
+		Path   string
 		Detail string
+		Err    error
+	}
+
+	ErrPRListFailed struct {
+		Owner  string
+		Repo   string
+		Detail string
+		Err    error
 	}
 )
 
-func (e ErrGHNotInstalled) Error() string {
+func (e *ErrGHNotInstalled) Error() string {
 	return "gh CLI is not installed. Install it from https://cli.github.com"
 }
 
-func (e ErrPRNotFound) Error() string {
-	return fmt.Sprintf("PR #%d not found in %s/%s", e.Number, e.Owner, e.Repo)
+func (e *ErrPRFetchFailed) Error() string {
+	return fmt.Sprintf("failed to fetch PR #%d from %s/%s: %s", e.Number, e.Owner, e.Repo, e.Detail)
 }
 
-func (e ErrPRFetchFailed) Error() string {
-	return fmt.Sprintf("failed to fetch PR #%d from %s/%s: %s", e.Number, e.Owner, e.Repo, e.Detail)
+func (e *ErrPRFetchFailed) Unwrap() error {
+	return e.Err
 }
 
-func (e ErrPRParseFailed) Error() string {
+func (e *ErrPRParseFailed) Error() string {
 	return fmt.Sprintf("failed to parse PR data: %s", e.Detail)
 }
 
-func (e ErrPRCheckoutFailed) Error() string {
-	return fmt.Sprintf("failed to checkout PR #%d: %s", e.Number, e.Detail)
+func (e *ErrPRParseFailed) Unwrap() error {
+	return e.Err
 }
 
-func (e ErrPRCreateFailed) Error() string {
+func (e *ErrPRCreateFailed) Error() string {
 	return fmt.Sprintf("failed to create PR: %s", e.Detail)
 }
+
+func (e *ErrPRCreateFailed) Unwrap() error {
+	return e.Err
+}
+
+func (e *ErrPRCloseFailed) Error() string {
+	return fmt.Sprintf("failed to close PR #%d: %s", e.Number, e.Detail)
+}
+
+func (e *ErrPRCloseFailed) Unwrap() error {
+	return e.Err
+}
+
+func (e *ErrPRCommentFailed) Error() string {
+	return fmt.Sprintf("failed to comment on PR #%d: %s", e.Number, e.Detail)
+}
+
+func (e *ErrPRCommentFailed) Unwrap() error {
+	return e.Err
+}
+
+func (e *ErrPRListFailed) Error() string {
+	return fmt.Sprintf("failed to list PRs in %s/%s: %s", e.Owner, e.Repo, e.Detail)
+}
+
+func (e *ErrPRListFailed) Unwrap() error {
+	return e.Err
+}
+
+func (e *ErrAPIRequestFailed) Error() string {
+	return fmt.Sprintf("GitHub API request %s failed: %s", e.Path, e.Detail)
+}
+
+func (e *ErrAPIRequestFailed) Unwrap() error {
+	return e.Err
+}
+
+func (e *ErrGHNotInstalled) Is(target error) bool {
+	return target == errs.ErrPrecondition
+}
+
+func (e *ErrPRFetchFailed) Is(target error) bool {
+	return target == errs.ErrGitHub
+}
+
+func (e *ErrPRParseFailed) Is(target error) bool {
+	return target == errs.ErrGitHub
+}
+
+func (e *ErrPRCreateFailed) Is(target error) bool {
+	return target == errs.ErrGitHub
+}
+
+func (e *ErrPRCloseFailed) Is(target error) bool {
+	return target == errs.ErrGitHub
+}
+
+func (e *ErrPRCommentFailed) Is(target error) bool {
+	return target == errs.ErrGitHub
+}
+
+func (e *ErrAPIRequestFailed) Is(target error) bool {
+	return target == errs.ErrGitHub
+}
+
+func (e *ErrPRListFailed) Is(target error) bool {
+	return target == errs.ErrGitHub
+}
@@ -1,12 +1,16 @@
 package github
 
 import (
+	"context"
+	"errors"
 	"strings"
 	"testing"
+
+	"github.com/user/git-mfpr/internal/errs"
 )
 
 func TestErrGHNotInstalled_Error(t *testing.T) {
-	err := ErrGHNotInstalled{}
+	err := &ErrGHNotInstalled{}
 	expected := "gh CLI is not installed. Install it from https://cli.github.com"
 	if err.Error() != expected {
 		t.Errorf("Expected error message %q, got %q", expected, err.Error())
@@ -16,12 +20,12 @@ func TestErrGHNotInstalled_Error(t *testing.T) {
 func TestErrPRNotFound_Error(t *testing.T) {
 	tests := []struct {
 		name     string
-		err      ErrPRNotFound
+		err      *ErrPRNotFound
 		expected string
 	}{
 		{
 			name: "basic PR not found",
-			err: ErrPRNotFound{
+			err: &ErrPRNotFound{
 				Number: 123,
 				Owner:  "testowner",
 				Repo:   "testrepo",
@@ -30,7 +34,7 @@ func TestErrPRNotFound_Error(t *testing.T) {
 		},
 		{
 			name: "different PR number",
-			err: ErrPRNotFound{
+			err: &ErrPRNotFound{
 				Number: 456,
 				Owner:  "owner2",
 				Repo:   "repo2",
@@ -51,12 +55,12 @@ func TestErrPRNotFound_Error(t *testing.T) {
 func TestErrPRFetchFailed_Error(t *testing.T) {
 	tests := []struct {
 		name     string
-		err      ErrPRFetchFailed
+		err      *ErrPRFetchFailed
 		expected string
 	}{
 		{
 			name: "network error",
-			err: ErrPRFetchFailed{
+			err: &ErrPRFetchFailed{
 				Number: 789,
 				Owner:  "myorg",
 				Repo:   "myrepo",
@@ -66,7 +70,7 @@ func TestErrPRFetchFailed_Error(t *testing.T) {
 		},
 		{
 			name: "auth error",
-			err: ErrPRFetchFailed{
+			err: &ErrPRFetchFailed{
 				Number: 321,
 				Owner:  "private",
 				Repo:   "repo",
@@ -88,17 +92,17 @@ func TestErrPRFetchFailed_Error(t *testing.T) {
 func TestErrPRParseFailed_Error(t *testing.T) {
 	tests := []struct {
 		name     string
-		err      ErrPRParseFailed
+		err      *ErrPRParseFailed
 		contains string
 	}{
 		{
 			name:     "invalid JSON",
-			err:      ErrPRParseFailed{Detail: "invalid JSON format"},
+			err:      &ErrPRParseFailed{Detail: "invalid JSON format"},
 			contains: "failed to parse PR data: invalid JSON format",
 		},
 		{
 			name:     "missing field",
-			err:      ErrPRParseFailed{Detail: "missing required field 'number'"},
+			err:      &ErrPRParseFailed{Detail: "missing required field 'number'"},
 			contains: "missing required field 'number'",
 		},
 	}
@@ -116,12 +120,12 @@ func TestErrPRParseFailed_Error(t *testing.T) {
 func TestErrPRCheckoutFailed_Error(t *testing.T) {
 	tests := []struct {
 		name     string
-		err      ErrPRCheckoutFailed
+		err      *ErrPRCheckoutFailed
 		expected string
 	}{
 		{
 			name: "branch conflict",
-			err: ErrPRCheckoutFailed{
+			err: &ErrPRCheckoutFailed{
 				Number: 555,
 				Detail: "branch already exists",
 			},
@@ -129,7 +133,7 @@ func TestErrPRCheckoutFailed_Error(t *testing.T) {
 		},
 		{
 			name: "permission denied",
-			err: ErrPRCheckoutFailed{
+			err: &ErrPRCheckoutFailed{
 				Number: 999,
 				Detail: "permission denied",
 			},
@@ -149,17 +153,17 @@ func TestErrPRCheckoutFailed_Error(t *testing.T) {
 func TestErrPRCreateFailed_Error(t *testing.T) {
 	tests := []struct {
 		name     string
-		err      ErrPRCreateFailed
+		err      *ErrPRCreateFailed
 		contains string
 	}{
 		{
 			name:     "validation error",
-			err:      ErrPRCreateFailed{Detail: "title cannot be empty"},
+			err:      &ErrPRCreateFailed{Detail: "title cannot be empty"},
 			contains: "failed to create PR: title cannot be empty",
 		},
 		{
 			name:     "API limit",
-			err:      ErrPRCreateFailed{Detail: "API rate limit exceeded"},
+			err:      &ErrPRCreateFailed{Detail: "API rate limit exceeded"},
 			contains: "API rate limit exceeded",
 		},
 	}
@@ -176,17 +180,17 @@ func TestErrPRCreateFailed_Error(t *testing.T) {
 
 // Test that errors implement the error interface
 func TestErrorsImplementErrorInterface(_ *testing.T) {
-	var _ error = ErrGHNotInstalled{}
-	var _ error = ErrPRNotFound{}
-	var _ error = ErrPRFetchFailed{}
-	var _ error = ErrPRParseFailed{}
-	var _ error = ErrPRCheckoutFailed{}
-	var _ error = ErrPRCreateFailed{}
+	var _ error = &ErrGHNotInstalled{}
+	var _ error = &ErrPRNotFound{}
+	var _ error = &ErrPRFetchFailed{}
+	var _ error = &ErrPRParseFailed{}
+	var _ error = &ErrPRCheckoutFailed{}
+	var _ error = &ErrPRCreateFailed{}
 }
 
 // Benchmark error message generation
 func BenchmarkErrPRNotFound_Error(b *testing.B) {
-	err := ErrPRNotFound{
+	err := &ErrPRNotFound{
 		Number: 12345,
 		Owner:  "benchowner",
 		Repo:   "benchrepo",
@@ -198,7 +202,7 @@ func BenchmarkErrPRNotFound_Error(b *testing.B) {
 }
 
 func BenchmarkErrPRFetchFailed_Error(b *testing.B) {
-	err := ErrPRFetchFailed{
+	err := &ErrPRFetchFailed{
 		Number: 67890,
 		Owner:  "testorg",
 		Repo:   "testrepo",
@@ -209,3 +213,37 @@ func BenchmarkErrPRFetchFailed_Error(b *testing.B) {
 		_ = err.Error()
 	}
 }
+
+func TestErrorCategories(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{name: "gh not installed", err: &ErrGHNotInstalled{}, want: errs.ErrPrecondition},
+		{name: "PR not found", err: &ErrPRNotFound{Number: 1}, want: errs.ErrNotFound},
+		{name: "fetch failed", err: &ErrPRFetchFailed{Number: 1}, want: errs.ErrGitHub},
+		{name: "API request failed", err: &ErrAPIRequestFailed{Path: "/x"}, want: errs.ErrGitHub},
+		{name: "list failed", err: &ErrPRListFailed{}, want: errs.ErrGitHub},
+		{name: "comment failed", err: &ErrPRCommentFailed{Number: 1}, want: errs.ErrGitHub},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !errors.Is(tt.err, tt.want) {
+				t.Errorf("errors.Is(%v, %v) = false", tt.err, tt.want)
+			}
+		})
+	}
+}
+
+func TestErrors_Unwrap(t *testing.T) {
+	err := error(&ErrAPIRequestFailed{Path: "/x", Detail: "cancelled", Err: context.Canceled})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("errors.Is(%v, context.Canceled) = false", err)
+	}
+	err = &ErrPRCreateFailed{Detail: "boom", Err: context.DeadlineExceeded}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("errors.Is(%v, context.DeadlineExceeded) = false", err)
+	}
+}
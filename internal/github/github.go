@@ -4,33 +4,38 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os/exec"
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/user/git-mfpr/internal/forge"
+	"github.com/user/git-mfpr/internal/runner"
 )
 
-type PRInfo struct {
-	Number     int
-	Title      string
-	Author     string
-	HeadBranch string
-	BaseBranch string
-	State      string
-	URL        string
-	HeadRefOID string
-	IsFork     bool
-}
+// The types GitHub shares with other forges.
+type (
+	PRInfo          = forge.PRInfo
+	CreatePROptions = forge.CreatePROptions
+	ListOptions     = forge.ListOptions
+)
 
+const (
+	CheckSuccess = forge.CheckSuccess
+	CheckFailure = forge.CheckFailure
+	CheckPending = forge.CheckPending
+)
+
+// GitHub is the forge.Forge that runs gh, plus a check that gh is installed.
 type GitHub interface {
-	GetPR(ctx context.Context, owner, repo string, number int) (*PRInfo, error)
-	CheckoutPR(ctx context.Context, owner, repo string, number int, branch string) error
-	CreatePR(ctx context.Context, title, body, base string) error
+	forge.Forge
 	IsGHInstalled(ctx context.Context) error
 }
 
 type Client struct {
 	timeout time.Duration
+	runner  runner.Runner
+	dir     string
+	host    string
 }
 
 type Option func(*Client)
@@ -41,6 +46,20 @@ func WithTimeout(timeout time.Duration) Option {
 	}
 }
 
+// WithRunner runs gh through r, so that commands can be observed.
+func WithRunner(r runner.Runner) Option {
+	return func(c *Client) {
+		c.runner = r
+	}
+}
+
+// WithDir runs every command in dir instead of the current directory.
+func WithDir(dir string) Option {
+	return func(c *Client) {
+		c.dir = dir
+	}
+}
+
 func New() GitHub {
 	return NewWithOptions()
 }
@@ -48,6 +67,7 @@ func New() GitHub {
 func NewWithOptions(opts ...Option) GitHub {
 	client := &Client{
 		timeout: 30 * time.Second,
+		runner:  runner.New(),
 	}
 
 	for _, opt := range opts {
@@ -57,6 +77,46 @@ func NewWithOptions(opts ...Option) GitHub {
 	return client
 }
 
+// withTimeout bounds a single operation by the client's timeout. A zero
+// timeout leaves ctx as is.
+func (c *Client) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, c.timeout)
+}
+
+// run executes gh with args, bounded by the client's timeout. For a failed
+// command, detail explains why, preferring timeouts and cancellation over
+// what gh printed.
+func (c *Client) run(ctx context.Context, args ...string) (result *runner.Result, detail string) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	result = c.runner.Run(ctx, runner.Cmd{Name: "gh", Args: args, Dir: c.dir})
+	if result.Err == nil {
+		return result, ""
+	}
+
+	// Report the context's error rather than gh being killed, so callers can
+	// tell an interrupt or timeout from gh failing.
+	switch ctx.Err() {
+	case context.DeadlineExceeded:
+		result.Err = ctx.Err()
+		return result, fmt.Sprintf("timed out after %s", c.timeout)
+	case context.Canceled:
+		result.Err = ctx.Err()
+		return result, "cancelled"
+	}
+	if stderr := strings.TrimSpace(string(result.Stderr)); stderr != "" {
+		return result, stderr
+	}
+	if stdout := strings.TrimSpace(string(result.Stdout)); stdout != "" {
+		return result, stdout
+	}
+	return result, result.Err.Error()
+}
+
 type ghPRResponse struct {
 	Number            int    `json:"number"`
 	Title             string `json:"title"`
@@ -69,6 +129,76 @@ type ghPRResponse struct {
 	Author            struct {
 		Login string `json:"login"`
 	} `json:"author"`
+	CreatedAt time.Time `json:"createdAt"`
+	Labels    []struct {
+		Name string `json:"name"`
+	} `json:"labels"`
+	StatusCheckRollup []ghCheck `json:"statusCheckRollup"`
+}
+
+// ghCheck is either a CheckRun (status/conclusion) or a StatusContext (state).
+type ghCheck struct {
+	Status     string `json:"status"`
+	Conclusion string `json:"conclusion"`
+	State      string `json:"state"`
+}
+
+const (
+	prJSONFields     = "number,title,author,headRefName,baseRefName,state,headRefOid,isCrossRepository,url"
+	prListJSONFields = prJSONFields + ",createdAt,labels,statusCheckRollup"
+)
+
+func (r *ghPRResponse) toPRInfo() *PRInfo {
+	pr := &PRInfo{
+		Number:      r.Number,
+		Title:       r.Title,
+		Author:      r.Author.Login,
+		HeadBranch:  r.HeadRefName,
+		BaseBranch:  r.BaseRefName,
+		State:       r.State,
+		URL:         r.URL,
+		HeadRefOID:  r.HeadRefOID,
+		IsFork:      r.IsCrossRepository,
+		CreatedAt:   r.CreatedAt,
+		CheckStatus: summarizeChecks(r.StatusCheckRollup),
+	}
+	for _, label := range r.Labels {
+		pr.Labels = append(pr.Labels, label.Name)
+	}
+	return pr
+}
+
+func summarizeChecks(checks []ghCheck) string {
+	if len(checks) == 0 {
+		return ""
+	}
+
+	pending := false
+	for _, check := range checks {
+		switch {
+		case check.State != "":
+			switch strings.ToUpper(check.State) {
+			case "SUCCESS":
+			case "PENDING", "EXPECTED":
+				pending = true
+			default:
+				return CheckFailure
+			}
+		case !strings.EqualFold(check.Status, "completed"):
+			pending = true
+		default:
+			switch strings.ToUpper(check.Conclusion) {
+			case "SUCCESS", "NEUTRAL", "SKIPPED":
+			default:
+				return CheckFailure
+			}
+		}
+	}
+
+	if pending {
+		return CheckPending
+	}
+	return CheckSuccess
 }
 
 type Result struct {
@@ -90,8 +220,10 @@ func (r *PRResult) IsError() bool {
 }
 
 func (c *Client) IsGHInstalled(ctx context.Context) error {
-	cmd := exec.CommandContext(ctx, "gh", "--version")
-	if err := cmd.Run(); err != nil {
+	if result, _ := c.run(ctx, "--version"); result.Err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
 		return &ErrGHNotInstalled{}
 	}
 	return nil
@@ -102,63 +234,163 @@ func (c *Client) GetPR(ctx context.Context, owner, repo string, number int) (*PR
 		return nil, err
 	}
 
-	cmd := exec.CommandContext(ctx, "gh", "pr", "view", strconv.Itoa(number), // #nosec G204
+	result, detail := c.run(ctx, "pr", "view", strconv.Itoa(number),
 		"--repo", fmt.Sprintf("%s/%s", owner, repo),
-		"--json", "number,title,author,headRefName,baseRefName,state,headRefOid,isCrossRepository,url")
-
-	output, err := cmd.Output()
-	if err != nil {
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			stderr := string(exitErr.Stderr)
-			if strings.Contains(stderr, "no pull requests found") {
-				return nil, &ErrPRNotFound{Number: number, Owner: owner, Repo: repo}
-			}
-			return nil, &ErrPRFetchFailed{Number: number, Owner: owner, Repo: repo, Detail: stderr}
+		"--json", prJSONFields)
+	if result.Err != nil {
+		if strings.Contains(detail, "no pull requests found") {
+			return nil, &ErrPRNotFound{Number: number, Owner: owner, Repo: repo}
 		}
-		return nil, &ErrPRFetchFailed{Number: number, Owner: owner, Repo: repo, Detail: err.Error()}
+		return nil, &ErrPRFetchFailed{Number: number, Owner: owner, Repo: repo, Detail: detail, Err: result.Err}
 	}
 
 	var pr ghPRResponse
-	if err := json.Unmarshal(output, &pr); err != nil {
-		return nil, &ErrPRParseFailed{Detail: err.Error()}
+	if err := json.Unmarshal(result.Stdout, &pr); err != nil {
+		return nil, &ErrPRParseFailed{Detail: err.Error(), Err: err}
 	}
 
-	return &PRInfo{
-		Number:     pr.Number,
-		Title:      pr.Title,
-		Author:     pr.Author.Login,
-		HeadBranch: pr.HeadRefName,
-		BaseBranch: pr.BaseRefName,
-		State:      pr.State,
-		URL:        pr.URL,
-		HeadRefOID: pr.HeadRefOID,
-		IsFork:     pr.IsCrossRepository,
-	}, nil
+	return pr.toPRInfo(), nil
 }
 
 func (c *Client) CheckoutPR(ctx context.Context, owner, repo string, number int, branch string) error {
-	cmd := exec.CommandContext(ctx, "gh", "pr", "checkout", strconv.Itoa(number),
+	result, detail := c.run(ctx, "pr", "checkout", strconv.Itoa(number),
 		"--repo", fmt.Sprintf("%s/%s", owner, repo),
-		"-b", branch) // #nosec G204
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		detail := err.Error()
-		if len(output) > 0 {
-			detail = string(output)
-		}
-		return &ErrPRCheckoutFailed{Number: number, Detail: detail}
+		"-b", branch)
+	if result.Err != nil {
+		return &ErrPRCheckoutFailed{Number: number, Detail: detail, Err: result.Err}
 	}
 	return nil
 }
 
-func (c *Client) CreatePR(ctx context.Context, title, body, base string) error {
-	cmd := exec.CommandContext(ctx, "gh", "pr", "create",
-		"--title", title,
-		"--body", body,
-		"--base", base)
+// GetPRPatch downloads the PR's commits as an mbox patch series. Unlike
+// CheckoutPR, it works when the PR's fork has been deleted or made private.
+func (c *Client) GetPRPatch(ctx context.Context, owner, repo string, number int) ([]byte, error) {
+	result, detail := c.run(ctx, c.apiArgs(fmt.Sprintf("repos/%s/%s/pulls/%d", owner, repo, number),
+		"-H", "Accept: application/vnd.github.patch")...)
+	if result.Err != nil {
+		return nil, &ErrPRPatchFailed{Number: number, Detail: detail, Err: result.Err}
+	}
+	return result.Stdout, nil
+}
 
-	if err := cmd.Run(); err != nil {
-		return &ErrPRCreateFailed{Detail: err.Error()}
+func (c *Client) CreatePR(ctx context.Context, owner, repo string, opts CreatePROptions) (*PRInfo, error) {
+	result, detail := c.run(ctx, "pr", "create",
+		"--repo", fmt.Sprintf("%s/%s", owner, repo),
+		"--title", opts.Title,
+		"--body", opts.Body,
+		"--base", opts.Base,
+		"--head", opts.Head)
+	if result.Err != nil {
+		return nil, &ErrPRCreateFailed{Detail: detail, Err: result.Err}
+	}
+
+	return parseCreatedPR(result.Stdout, opts)
+}
+
+// parseCreatedPR builds PRInfo from the URL gh prints after creating a PR. If
+// there is no PR number at the end of its output, the PR may exist but cannot
+// be commented on or closed, so that is an error.
+func parseCreatedPR(output []byte, opts CreatePROptions) (*PRInfo, error) {
+	pr := &PRInfo{
+		Title:      opts.Title,
+		BaseBranch: opts.Base,
+		HeadBranch: opts.Head,
+		State:      "OPEN",
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	pr.URL = strings.TrimSpace(lines[len(lines)-1])
+	number, err := strconv.Atoi(pr.URL[strings.LastIndex(pr.URL, "/")+1:])
+	if err != nil || number <= 0 {
+		return nil, &ErrPRParseFailed{Detail: fmt.Sprintf("no PR URL in gh pr create output %q", strings.TrimSpace(string(output))), Err: err}
+	}
+	pr.Number = number
+	return pr, nil
+}
+
+func (c *Client) ClosePR(ctx context.Context, owner, repo string, number int, comment string) error {
+	args := []string{"pr", "close", strconv.Itoa(number), "--repo", fmt.Sprintf("%s/%s", owner, repo)}
+	if comment != "" {
+		args = append(args, "--comment", comment)
+	}
+
+	if result, detail := c.run(ctx, args...); result.Err != nil {
+		return &ErrPRCloseFailed{Number: number, Detail: detail, Err: result.Err}
+	}
+	return nil
+}
+
+func (c *Client) CommentPR(ctx context.Context, owner, repo string, number int, body string) error {
+	result, detail := c.run(ctx, "pr", "comment", strconv.Itoa(number),
+		"--repo", fmt.Sprintf("%s/%s", owner, repo),
+		"--body", body)
+	if result.Err != nil {
+		return &ErrPRCommentFailed{Number: number, Detail: detail, Err: result.Err}
 	}
 	return nil
 }
+
+func (c *Client) FindPRForBranch(ctx context.Context, owner, repo, branch string) (*PRInfo, error) {
+	if err := c.IsGHInstalled(ctx); err != nil {
+		return nil, err
+	}
+
+	result, detail := c.run(ctx, "pr", "list",
+		"--repo", fmt.Sprintf("%s/%s", owner, repo),
+		"--head", branch,
+		"--state", "all",
+		"--limit", "1",
+		"--json", prJSONFields)
+	if result.Err != nil {
+		return nil, &ErrPRListFailed{Owner: owner, Repo: repo, Detail: detail, Err: result.Err}
+	}
+
+	return parsePRList(result.Stdout)
+}
+
+func parsePRList(output []byte) (*PRInfo, error) {
+	prs, err := parsePRs(output)
+	if err != nil || len(prs) == 0 {
+		return nil, err
+	}
+	return &prs[0], nil
+}
+
+func parsePRs(output []byte) ([]PRInfo, error) {
+	var response []ghPRResponse
+	if err := json.Unmarshal(output, &response); err != nil {
+		return nil, &ErrPRParseFailed{Detail: err.Error(), Err: err}
+	}
+
+	prs := make([]PRInfo, 0, len(response))
+	for i := range response {
+		prs = append(prs, *response[i].toPRInfo())
+	}
+	return prs, nil
+}
+
+func (c *Client) ListPRs(ctx context.Context, owner, repo string, opts ListOptions) ([]PRInfo, error) {
+	if err := c.IsGHInstalled(ctx); err != nil {
+		return nil, err
+	}
+
+	args := []string{"pr", "list",
+		"--repo", fmt.Sprintf("%s/%s", owner, repo),
+		"--json", prListJSONFields}
+	if opts.Base != "" {
+		args = append(args, "--base", opts.Base)
+	}
+	if opts.State != "" {
+		args = append(args, "--state", opts.State)
+	}
+	if opts.Limit > 0 {
+		args = append(args, "--limit", strconv.Itoa(opts.Limit))
+	}
+
+	result, detail := c.run(ctx, args...)
+	if result.Err != nil {
+		return nil, &ErrPRListFailed{Owner: owner, Repo: repo, Detail: detail, Err: result.Err}
+	}
+
+	return parsePRs(result.Stdout)
+}
@@ -3,11 +3,16 @@ package github
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
+	"reflect"
 	"testing"
 	"time"
+
+	"github.com/user/git-mfpr/internal/errs"
+	"github.com/user/git-mfpr/internal/runner"
 )
 
 func TestClient_IsGHInstalled(t *testing.T) {
@@ -216,7 +221,7 @@ func TestClient_CreatePR(t *testing.T) {
 
 	// We can't actually create a PR in tests, so we'll just verify the method exists
 	// and returns an error when not in a git repo or without proper setup
-	err := client.CreatePR(ctx, "Test PR", "Test body", "main")
+	_, err := client.CreatePR(ctx, "owner", "repo", CreatePROptions{Title: "Test PR", Body: "Test body", Base: "main", Head: "test-branch"})
 	if err == nil {
 		// If no error, we might be in a real repo with gh auth, which we don't want
 		t.Skip("Skipping to avoid creating real PR")
@@ -383,7 +388,7 @@ func TestCreatePR_CommandFailure(t *testing.T) {
 	client := New()
 
 	// Test with invalid base branch to trigger command failure
-	err := client.CreatePR(ctx, "Test PR", "Test body", "nonexistent-branch")
+	_, err := client.CreatePR(ctx, "owner", "repo", CreatePROptions{Title: "Test PR", Body: "Test body", Base: "nonexistent-branch", Head: "test-branch"})
 	if err == nil {
 		t.Skip("CreatePR() succeeded unexpectedly, skipping command failure test")
 	}
@@ -454,6 +459,16 @@ func TestErrorTypes(t *testing.T) {
 			err:      &ErrPRCreateFailed{Detail: "invalid base branch"},
 			expected: "failed to create PR: invalid base branch",
 		},
+		{
+			name:     "ErrPRPatchFailed",
+			err:      &ErrPRPatchFailed{Number: 123, Detail: "HTTP 404"},
+			expected: "failed to download the patches for PR #123: HTTP 404",
+		},
+		{
+			name:     "ErrAPIRequestFailed",
+			err:      &ErrAPIRequestFailed{Path: "repos/o/r/commits/abc/status", Detail: "404 Not Found"},
+			expected: "GitHub API request repos/o/r/commits/abc/status failed: 404 Not Found",
+		},
 		{
 			name:     "ErrGHNotInstalled",
 			err:      &ErrGHNotInstalled{},
@@ -506,8 +521,290 @@ func TestCreatePR_ContextCancellation(t *testing.T) {
 	cancel() // Cancel immediately
 
 	client := New()
-	err := client.CreatePR(ctx, "Test PR", "Test body", "main")
+	_, err := client.CreatePR(ctx, "owner", "repo", CreatePROptions{Title: "Test PR", Body: "Test body", Base: "main", Head: "test-branch"})
 	if err == nil {
 		t.Error("CreatePR() should return error when context is cancelled")
 	}
 }
+
+func TestParsePRList(t *testing.T) {
+	tests := []struct {
+		name       string
+		output     string
+		wantNumber int
+		wantNil    bool
+		wantErr    bool
+	}{
+		{
+			name:    "no PRs",
+			output:  `[]`,
+			wantNil: true,
+		},
+		{
+			name: "one PR",
+			output: `[{
+				"number": 130,
+				"title": "Migrated PR",
+				"state": "MERGED",
+				"headRefName": "migrated-123",
+				"baseRefName": "main",
+				"url": "https://github.com/owner/repo/pull/130",
+				"author": {"login": "maintainer"}
+			}]`,
+			wantNumber: 130,
+		},
+		{
+			name:    "invalid JSON",
+			output:  `{`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pr, err := parsePRList([]byte(tt.output))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parsePRList() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if _, ok := err.(*ErrPRParseFailed); !ok {
+					t.Errorf("Expected ErrPRParseFailed, got %T", err)
+				}
+				return
+			}
+			if tt.wantNil {
+				if pr != nil {
+					t.Errorf("parsePRList() = %+v, want nil", pr)
+				}
+				return
+			}
+			if pr.Number != tt.wantNumber || pr.Author != "maintainer" || pr.HeadBranch != "migrated-123" {
+				t.Errorf("parsePRList() = %+v", pr)
+			}
+		})
+	}
+}
+
+func TestFindPRForBranch_GHNotInstalled(t *testing.T) {
+	originalPath := os.Getenv("PATH")
+	defer os.Setenv("PATH", originalPath)
+
+	if err := os.Setenv("PATH", ""); err != nil {
+		t.Fatalf("Failed to set PATH: %v", err)
+	}
+
+	_, err := New().FindPRForBranch(context.Background(), "owner", "repo", "migrated-1")
+	if !isErrGHNotInstalled(err) {
+		t.Errorf("Expected ErrGHNotInstalled, got %T: %v", err, err)
+	}
+}
+
+func TestSummarizeChecks(t *testing.T) {
+	tests := []struct {
+		name   string
+		checks []ghCheck
+		want   string
+	}{
+		{name: "no checks", want: ""},
+		{
+			name:   "all passing",
+			checks: []ghCheck{{Status: "COMPLETED", Conclusion: "SUCCESS"}, {State: "SUCCESS"}, {Status: "COMPLETED", Conclusion: "SKIPPED"}},
+			want:   CheckSuccess,
+		},
+		{
+			name:   "check run in progress",
+			checks: []ghCheck{{Status: "COMPLETED", Conclusion: "SUCCESS"}, {Status: "IN_PROGRESS"}},
+			want:   CheckPending,
+		},
+		{
+			name:   "status context pending",
+			checks: []ghCheck{{State: "PENDING"}},
+			want:   CheckPending,
+		},
+		{
+			name:   "failure wins over pending",
+			checks: []ghCheck{{Status: "QUEUED"}, {Status: "COMPLETED", Conclusion: "FAILURE"}},
+			want:   CheckFailure,
+		},
+		{
+			name:   "status context error",
+			checks: []ghCheck{{State: "ERROR"}},
+			want:   CheckFailure,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := summarizeChecks(tt.checks); got != tt.want {
+				t.Errorf("summarizeChecks() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePRs_ListFields(t *testing.T) {
+	output := `[{
+		"number": 101,
+		"title": "Fix leak",
+		"state": "OPEN",
+		"isCrossRepository": true,
+		"author": {"login": "alice"},
+		"createdAt": "2024-05-01T10:00:00Z",
+		"labels": [{"name": "bug"}, {"name": "help wanted"}],
+		"statusCheckRollup": [{"__typename": "CheckRun", "status": "COMPLETED", "conclusion": "SUCCESS"}]
+	}]`
+
+	prs, err := parsePRs([]byte(output))
+	if err != nil {
+		t.Fatalf("parsePRs() error = %v", err)
+	}
+	if len(prs) != 1 {
+		t.Fatalf("parsePRs() returned %d PRs, want 1", len(prs))
+	}
+
+	pr := prs[0]
+	if !pr.CreatedAt.Equal(time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("CreatedAt = %v", pr.CreatedAt)
+	}
+	if len(pr.Labels) != 2 || pr.Labels[1] != "help wanted" {
+		t.Errorf("Labels = %v", pr.Labels)
+	}
+	if pr.CheckStatus != CheckSuccess {
+		t.Errorf("CheckStatus = %q, want %q", pr.CheckStatus, CheckSuccess)
+	}
+}
+
+func TestParseCreatedPR(t *testing.T) {
+	opts := CreatePROptions{Title: "Fix bug", Base: "main", Head: "migrated-123"}
+	output := []byte("Warning: 1 uncommitted change\nhttps://github.com/owner/repo/pull/456\n")
+
+	pr, err := parseCreatedPR(output, opts)
+	if err != nil {
+		t.Fatalf("parseCreatedPR() error = %v", err)
+	}
+	if pr.Number != 456 {
+		t.Errorf("Number = %d, want 456", pr.Number)
+	}
+	if pr.URL != "https://github.com/owner/repo/pull/456" {
+		t.Errorf("URL = %q", pr.URL)
+	}
+	if pr.Title != "Fix bug" || pr.BaseBranch != "main" || pr.HeadBranch != "migrated-123" {
+		t.Errorf("parseCreatedPR() = %+v, want fields from opts", pr)
+	}
+
+	for _, output := range []string{"", "Creating pull request...\n", "https://github.com/owner/repo/pull/\n", "https://github.com/owner/repo/pull/0"} {
+		if pr, err := parseCreatedPR([]byte(output), opts); !errors.Is(err, errs.ErrGitHub) {
+			t.Errorf("parseCreatedPR(%q) = %+v, %v; want a GitHub error", output, pr, err)
+		}
+	}
+}
+
+func TestClient_ReplayedGetPR(t *testing.T) {
+	fake, err := runner.LoadFake("testdata/get_pr.json")
+	if err != nil {
+		t.Fatalf("LoadFake() error = %v", err)
+	}
+	client := NewWithOptions(WithRunner(fake))
+
+	pr, err := client.GetPR(context.Background(), "owner", "repo", 123)
+	if err != nil {
+		t.Fatalf("GetPR() error = %v", err)
+	}
+	want := &PRInfo{
+		Number:     123,
+		Title:      "Fix typo in README",
+		Author:     "contributor",
+		HeadBranch: "fix-typo",
+		BaseBranch: "main",
+		State:      "OPEN",
+		URL:        "https://github.com/owner/repo/pull/123",
+		HeadRefOID: "3f2c1a9",
+		IsFork:     true,
+	}
+	if !reflect.DeepEqual(pr, want) {
+		t.Errorf("GetPR() = %+v, want %+v", pr, want)
+	}
+
+	_, err = client.GetPR(context.Background(), "owner", "repo", 999)
+	if _, ok := err.(*ErrPRNotFound); !ok {
+		t.Errorf("GetPR() error = %v, want ErrPRNotFound", err)
+	}
+	if unused := fake.Unused(); len(unused) != 0 {
+		t.Errorf("recording has unused entries: %v", unused)
+	}
+}
+
+func TestClient_FakeRunner(t *testing.T) {
+	ctx := context.Background()
+	fake := runner.NewFake(
+		runner.Entry{
+			Command: "gh pr create --repo owner/repo --title Title --body Body --base main --head migrated-1",
+			Dir:     "/work/repo",
+			Stdout:  "https://github.com/owner/repo/pull/7\n",
+		},
+		runner.Entry{
+			Command:  "gh pr close 1 --repo owner/repo --comment Moved",
+			Dir:      "/work/repo",
+			Stderr:   "HTTP 403: Resource not accessible by integration\n",
+			ExitCode: 1,
+		},
+		runner.Entry{
+			Command: "gh pr comment 1 --repo owner/repo --body 'Migrated to #7'",
+			Dir:     "/work/repo",
+			Stdout:  "https://github.com/owner/repo/pull/1#issuecomment-1\n",
+		},
+	)
+	client := NewWithOptions(WithRunner(fake), WithDir("/work/repo"))
+
+	pr, err := client.CreatePR(ctx, "owner", "repo", CreatePROptions{Title: "Title", Body: "Body", Base: "main", Head: "migrated-1"})
+	if err != nil {
+		t.Fatalf("CreatePR() error = %v", err)
+	}
+	if pr.Number != 7 {
+		t.Errorf("CreatePR() number = %d, want 7", pr.Number)
+	}
+
+	err = client.ClosePR(ctx, "owner", "repo", 1, "Moved")
+	closeErr, ok := err.(*ErrPRCloseFailed)
+	if !ok || closeErr.Detail != "HTTP 403: Resource not accessible by integration" {
+		t.Errorf("ClosePR() error = %#v, want ErrPRCloseFailed with gh's message", err)
+	}
+
+	if err := client.CommentPR(ctx, "owner", "repo", 1, "Migrated to #7"); err != nil {
+		t.Errorf("CommentPR() error = %v", err)
+	}
+
+	for _, call := range fake.Calls() {
+		if call.Dir != "/work/repo" {
+			t.Errorf("%s ran in %q, want /work/repo", call, call.Dir)
+		}
+	}
+}
+
+func TestClient_GetPRPatch(t *testing.T) {
+	ctx := context.Background()
+	mbox := "From 0123456789abcdef0123456789abcdef01234567 Mon Sep 17 00:00:00 2001\nSubject: [PATCH] Fix\n"
+	fake := runner.NewFake(
+		runner.Entry{
+			Command: "gh api repos/owner/repo/pulls/1 -H 'Accept: application/vnd.github.patch'",
+			Stdout:  mbox,
+		},
+		runner.Entry{
+			Command:  "gh api repos/owner/repo/pulls/2 -H 'Accept: application/vnd.github.patch'",
+			Stderr:   "gh: Not Found (HTTP 404)\n",
+			ExitCode: 1,
+		},
+	)
+	client := NewWithOptions(WithRunner(fake))
+
+	got, err := client.GetPRPatch(ctx, "owner", "repo", 1)
+	if err != nil || string(got) != mbox {
+		t.Errorf("GetPRPatch() = %q, %v; want the mbox", got, err)
+	}
+
+	_, err = client.GetPRPatch(ctx, "owner", "repo", 2)
+	patchErr, ok := err.(*ErrPRPatchFailed)
+	if !ok || patchErr.Detail != "gh: Not Found (HTTP 404)" {
+		t.Errorf("GetPRPatch() error = %#v, want ErrPRPatchFailed with gh's message", err)
+	}
+}
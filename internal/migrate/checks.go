@@ -0,0 +1,72 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/user/git-mfpr/internal/github"
+)
+
+const (
+	// DefaultChecksTimeout bounds how long WaitChecks waits unless
+	// Options.ChecksTimeout says otherwise.
+	DefaultChecksTimeout = 30 * time.Minute
+	// defaultCheckInterval is how often the checks are polled.
+	defaultCheckInterval = 15 * time.Second
+)
+
+// waitForChecks polls the CI checks on the pushed branch's commit until they
+// all pass, one fails or timeout runs out, reporting progress as it goes.
+func (c *Client) waitForChecks(ctx context.Context, t *target, branch string, timeout time.Duration) error {
+	if timeout <= 0 {
+		timeout = DefaultChecksTimeout
+	}
+	interval := c.checkInterval
+	if interval <= 0 {
+		interval = defaultCheckInterval
+	}
+
+	sha, err := c.git.RevParse(ctx, branch)
+	if err != nil {
+		return err
+	}
+	c.emit(EventInfo, fmt.Sprintf("Waiting up to %s for checks on %s (%.7s)...", timeout, branch, sha), "")
+
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+
+	last := ""
+	for {
+		checks, err := c.forge.GetChecks(ctx, t.owner, t.repo, sha)
+		if err != nil {
+			return err
+		}
+		if summary := checks.String(); summary != last {
+			c.emit(EventInfo, "Checks: "+summary, "")
+			last = summary
+		}
+
+		switch checks.State {
+		case github.CheckSuccess:
+			c.emit(EventSuccess, fmt.Sprintf("All %d checks passed on %s", len(checks.Checks), branch), "")
+			return nil
+		case github.CheckFailure:
+			// The error names the failed checks; this says where to look.
+			for _, check := range checks.Checks {
+				if check.State == github.CheckFailure && check.URL != "" {
+					c.emit(EventInfo, fmt.Sprintf("Failed check %s: %s", check.Name, check.URL), "")
+				}
+			}
+			return &ErrChecksFailed{Branch: branch, Failed: checks.Failed()}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-deadline.C:
+			return &ErrChecksTimedOut{Branch: branch, Timeout: timeout, Summary: last}
+		case <-time.After(interval):
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package migrate
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/user/git-mfpr/internal/github"
+	"github.com/user/git-mfpr/internal/runner"
+)
+
+// apiGitHub answers GetChecks with a real client running a fake gh.
+type apiGitHub struct {
+	*mockGitHub
+	api github.GitHub
+}
+
+func (a *apiGitHub) GetChecks(ctx context.Context, owner, repo, sha string) (*github.Checks, error) {
+	return a.api.GetChecks(ctx, owner, repo, sha)
+}
+
+// checksRunner answers gh api with the check runs in polls, one per poll,
+// repeating the last one.
+func checksRunner(polls ...string) *runner.Fake {
+	prefix := "gh api repos/testowner/testrepo/commits/0123456789abcdef0123456789abcdef01234567"
+	entries := []runner.Entry{{Command: prefix + "/status", Stdout: `{"state":"pending","statuses":[]}`}}
+	for _, poll := range polls {
+		entries = append(entries, runner.Entry{Command: "gh api 'repos/testowner/testrepo/commits/0123456789abcdef0123456789abcdef01234567/check-runs?per_page=100'", Stdout: poll})
+	}
+	return runner.NewFake(entries...)
+}
+
+// polls counts the check-runs requests fake answered.
+func polls(fake *runner.Fake) int {
+	n := 0
+	for _, call := range fake.Calls() {
+		if strings.Contains(call.String(), "/check-runs") {
+			n++
+		}
+	}
+	return n
+}
+
+func TestMigratePR_WaitChecks(t *testing.T) {
+	const (
+		none    = `{"check_runs":[]}`
+		running = `{"check_runs":[{"name":"test","status":"in_progress"}]}`
+		passed  = `{"check_runs":[{"name":"test","status":"completed","conclusion":"success"}]}`
+		failed  = `{"check_runs":[{"name":"test","status":"completed","conclusion":"failure","html_url":"https://github.com/testowner/testrepo/runs/1"}]}`
+	)
+
+	tests := []struct {
+		name         string
+		polls        []string
+		opts         Options
+		wantErr      error
+		wantProgress []string
+		wantRequests int
+	}{
+		{
+			name:  "checks pass",
+			polls: []string{none, running, running, passed},
+			opts:  Options{WaitChecks: true},
+			wantProgress: []string{
+				"Waiting up to 30m0s for checks on migrated-123 (0123456)...",
+				"Checks: no checks reported yet",
+				"Checks: 0 passed, 1 pending",
+				"Checks: 1 passed",
+				"All 1 checks passed on migrated-123",
+			},
+			wantRequests: 4,
+		},
+		{
+			name:    "a check fails",
+			polls:   []string{running, failed},
+			opts:    Options{WaitChecks: true},
+			wantErr: &ErrChecksFailed{Branch: "migrated-123", Failed: []string{"test"}},
+			wantProgress: []string{
+				"Checks: 0 passed, 1 pending",
+				"Checks: 0 passed, 1 failed",
+				"Failed check test: https://github.com/testowner/testrepo/runs/1",
+			},
+			wantRequests: 2,
+		},
+		{
+			name:    "timed out",
+			polls:   []string{running},
+			opts:    Options{WaitChecks: true, ChecksTimeout: 20 * time.Millisecond},
+			wantErr: &ErrChecksTimedOut{Branch: "migrated-123", Timeout: 20 * time.Millisecond, Summary: "0 passed, 1 pending"},
+		},
+		{
+			name:  "not pushed",
+			polls: []string{passed},
+			opts:  Options{WaitChecks: true, NoPush: true},
+		},
+		{
+			name:  "not asked to wait",
+			polls: []string{passed},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := checksRunner(tt.polls...)
+			gh := &apiGitHub{
+				mockGitHub: forkPRGitHub(),
+				api:        github.NewWithOptions(github.WithRunner(fake)),
+			}
+			client := newTestClient(&mockGit{}, gh)
+			client.checkInterval = time.Millisecond
+			var events []Event
+			client.SetEventHandler(func(e Event) { events = append(events, e) })
+
+			tt.opts.NoCreate = true
+			err := client.MigratePR(context.Background(), "123", tt.opts)
+
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Fatalf("MigratePR() error = %#v, want %#v", err, tt.wantErr)
+			}
+
+			for _, e := range events {
+				if e.Type == EventError {
+					t.Errorf("unexpected error event %q; the returned error reports the failure", e.Message)
+				}
+			}
+			messages := eventMessages(events)
+			for _, want := range tt.wantProgress {
+				if !strings.Contains(messages, want) {
+					t.Errorf("events missing %q:\n%s", want, messages)
+				}
+			}
+			if tt.wantRequests != 0 && polls(fake) != tt.wantRequests {
+				t.Errorf("polled %d times, want %d", polls(fake), tt.wantRequests)
+			}
+			if !tt.opts.WaitChecks || tt.opts.NoPush {
+				if n := polls(fake); n != 0 {
+					t.Errorf("polled %d times, want no polling", n)
+				}
+			}
+		})
+	}
+}
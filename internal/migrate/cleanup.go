@@ -0,0 +1,153 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type CleanupOptions struct {
+	DryRun     bool
+	OlderThan  time.Duration
+	MergedOnly bool
+}
+
+// CleanupCandidates returns migrated branches that still exist locally or on
+// origin and whose replacement PR has been merged (or closed, unless
+// MergedOnly is set).
+func (c *Client) CleanupCandidates(ctx context.Context, opts CleanupOptions) ([]MigrationStatus, error) {
+	statuses, err := c.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff := time.Now().Add(-opts.OlderThan)
+	var candidates []MigrationStatus
+	for _, status := range statuses {
+		if !status.LocalBranch && !status.Pushed {
+			continue
+		}
+		if status.Replacement == nil {
+			continue
+		}
+		merged := strings.EqualFold(status.Replacement.State, "merged")
+		closed := strings.EqualFold(status.Replacement.State, "closed")
+		if !merged && (opts.MergedOnly || !closed) {
+			continue
+		}
+		if opts.OlderThan > 0 && status.MigratedAt.After(cutoff) {
+			continue
+		}
+		candidates = append(candidates, status)
+	}
+	return candidates, nil
+}
+
+func (c *Client) Cleanup(ctx context.Context, opts CleanupOptions) ([]MigrationStatus, error) {
+	candidates, err := c.CleanupCandidates(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(candidates) == 0 {
+		c.emit(EventInfo, "No migrated branches to clean up", "")
+		return nil, nil
+	}
+
+	failed, kept := 0, 0
+	for i := range candidates {
+		candidate := &candidates[i]
+		c.emit(EventInfo, fmt.Sprintf("%s: replacement PR #%d is %s",
+			candidate.Branch, candidate.Replacement.Number, strings.ToLower(candidate.Replacement.State)), "")
+
+		if opts.DryRun {
+			if candidate.LocalBranch {
+				if base, current := c.checkedOut(ctx, candidate); current {
+					c.emit(EventCommand, "Would execute:", "git checkout "+base)
+				}
+				c.emit(EventCommand, "Would execute:", "git branch -D "+candidate.Branch)
+			}
+			if candidate.Pushed {
+				c.emit(EventCommand, "Would execute:", fmt.Sprintf("git push %s --delete %s", candidate.Remote, candidate.Branch))
+			}
+			c.emit(EventCommand, "Would execute:", "git update-ref -d "+candidate.Ref)
+			continue
+		}
+
+		if !c.confirmed(fmt.Sprintf("Delete %s locally and on %s?", candidate.Branch, candidate.pushLabel())) {
+			c.emit(EventInfo, fmt.Sprintf("Kept %s", candidate.Branch), "")
+			kept++
+			continue
+		}
+
+		if err := c.deleteMigratedBranch(ctx, candidate); err != nil {
+			c.emit(EventError, fmt.Sprintf("Failed to clean up %s", candidate.Branch), err.Error())
+			candidate.Error = err
+			failed++
+		}
+	}
+
+	if opts.DryRun {
+		c.emit(EventInfo, fmt.Sprintf("Would clean up %d migrated branches", len(candidates)), "")
+		return candidates, nil
+	}
+
+	if failed > 0 {
+		return candidates, fmt.Errorf("failed to clean up %d of %d migrated branches", failed, len(candidates))
+	}
+
+	c.emit(EventSuccess, fmt.Sprintf("Cleaned up %d migrated branches", len(candidates)-kept), "")
+	return candidates, nil
+}
+
+func (c *Client) deleteMigratedBranch(ctx context.Context, status *MigrationStatus) error {
+	if status.LocalBranch {
+		if base, current := c.checkedOut(ctx, status); current {
+			if base == "" {
+				return fmt.Errorf("%s is checked out; switch to another branch first", status.Branch)
+			}
+			if err := c.git.Checkout(ctx, base); err != nil {
+				return err
+			}
+			c.emit(EventInfo, fmt.Sprintf("Switched to %s, since %s was checked out", base, status.Branch), "")
+		}
+		if err := c.git.DeleteBranch(ctx, status.Branch); err != nil {
+			return err
+		}
+		status.LocalBranch = false
+		c.emit(EventSuccess, fmt.Sprintf("Deleted local branch %s", status.Branch), "")
+	}
+
+	if status.Pushed {
+		if err := c.git.DeleteRemoteBranch(ctx, status.Remote, status.Branch); err != nil {
+			return err
+		}
+		status.Pushed = false
+		c.emit(EventSuccess, fmt.Sprintf("Deleted %s from %s", status.Branch, status.pushLabel()), "")
+	}
+
+	// Without its branch the record only clutters list and status.
+	if err := c.git.DeleteRef(ctx, status.Ref); err != nil {
+		return err
+	}
+	c.emit(EventSuccess, fmt.Sprintf("Removed migration record %s", status.Ref), "")
+	return nil
+}
+
+// checkedOut reports whether status's branch is the current branch, which git
+// will not delete, and the base branch to switch to first: the replacement
+// PR's, or else the original's.
+func (c *Client) checkedOut(ctx context.Context, status *MigrationStatus) (base string, current bool) {
+	branch, err := c.git.CurrentBranch(ctx)
+	if err != nil || branch != status.Branch {
+		return "", false
+	}
+	if status.Replacement != nil {
+		base = status.Replacement.BaseBranch
+	}
+	if base == "" && status.Original != nil {
+		base = status.Original.BaseBranch
+	}
+	return base, true
+}
@@ -0,0 +1,265 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/user/git-mfpr/internal/github"
+)
+
+func newCleanupTestClient(replacements map[string]string) (*Client, *mockGit) {
+	recent := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	old := time.Now().Add(-60 * 24 * time.Hour).UTC().Format(time.RFC3339)
+
+	mockGit := &mockGit{
+		refs: map[string][]byte{
+			"refs/mfpr/1": []byte(fmt.Sprintf(`{"number":1,"branch":"migrated-1","migrated_at":%q}`, old)),
+			"refs/mfpr/2": []byte(fmt.Sprintf(`{"number":2,"branch":"migrated-2","migrated_at":%q}`, recent)),
+			"refs/mfpr/3": []byte(fmt.Sprintf(`{"number":3,"branch":"migrated-3","migrated_at":%q}`, old)),
+			"refs/mfpr/4": []byte(fmt.Sprintf(`{"number":4,"branch":"migrated-4","migrated_at":%q}`, old)),
+		},
+		hasBranchFunc: func(_ context.Context, name string) bool {
+			return name != "migrated-4"
+		},
+		remoteBranches: map[string]bool{"migrated-1": true, "migrated-2": true},
+	}
+	mockGitHub := &mockGitHub{
+		getPRFunc: func(_, _ string, number int) (*github.PRInfo, error) {
+			return &github.PRInfo{Number: number, State: "CLOSED"}, nil
+		},
+		findPRFunc: func(_, _, branch string) (*github.PRInfo, error) {
+			state, ok := replacements[branch]
+			if !ok {
+				return nil, nil
+			}
+			return &github.PRInfo{Number: 100, State: state}, nil
+		},
+	}
+	return newTestClient(mockGit, mockGitHub), mockGit
+}
+
+func branchesOf(statuses []MigrationStatus) string {
+	var names []string
+	for _, s := range statuses {
+		names = append(names, s.Branch)
+	}
+	return strings.Join(names, ",")
+}
+
+func TestCleanupCandidates(t *testing.T) {
+	replacements := map[string]string{
+		"migrated-1": "MERGED",
+		"migrated-2": "CLOSED",
+		"migrated-3": "OPEN",
+		"migrated-4": "MERGED",
+	}
+
+	tests := []struct {
+		name string
+		opts CleanupOptions
+		want string
+	}{
+		{
+			name: "merged and closed",
+			want: "migrated-1,migrated-2",
+		},
+		{
+			name: "merged only",
+			opts: CleanupOptions{MergedOnly: true},
+			want: "migrated-1",
+		},
+		{
+			name: "older than",
+			opts: CleanupOptions{OlderThan: 30 * 24 * time.Hour},
+			want: "migrated-1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, _ := newCleanupTestClient(replacements)
+			candidates, err := client.CleanupCandidates(context.Background(), tt.opts)
+			if err != nil {
+				t.Fatalf("CleanupCandidates() error = %v", err)
+			}
+			if got := branchesOf(candidates); got != tt.want {
+				t.Errorf("CleanupCandidates() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCleanup(t *testing.T) {
+	client, mockGit := newCleanupTestClient(map[string]string{
+		"migrated-1": "MERGED",
+		"migrated-3": "CLOSED",
+	})
+
+	cleaned, err := client.Cleanup(context.Background(), CleanupOptions{})
+	if err != nil {
+		t.Fatalf("Cleanup() error = %v", err)
+	}
+	if got := branchesOf(cleaned); got != "migrated-1,migrated-3" {
+		t.Errorf("Cleanup() = %q", got)
+	}
+	if got := strings.Join(mockGit.deleted, ","); got != "migrated-1,migrated-3" {
+		t.Errorf("deleted local branches = %q", got)
+	}
+	if got := strings.Join(mockGit.remoteDeleted, ","); got != "migrated-1" {
+		t.Errorf("deleted remote branches = %q", got)
+	}
+	for _, ref := range []string{"refs/mfpr/1", "refs/mfpr/3"} {
+		if _, ok := mockGit.refs[ref]; ok {
+			t.Errorf("%s was kept after its branch was deleted", ref)
+		}
+	}
+	if _, ok := mockGit.refs["refs/mfpr/2"]; !ok {
+		t.Error("refs/mfpr/2 was deleted, but its branch was kept")
+	}
+}
+
+func TestCleanup_DryRun(t *testing.T) {
+	client, mockGit := newCleanupTestClient(map[string]string{"migrated-1": "MERGED"})
+
+	var commands []string
+	client.SetEventHandler(func(event Event) {
+		if event.Type == EventCommand {
+			commands = append(commands, event.Detail)
+		}
+	})
+
+	cleaned, err := client.Cleanup(context.Background(), CleanupOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("Cleanup() error = %v", err)
+	}
+	if len(cleaned) != 1 {
+		t.Errorf("Cleanup() returned %d candidates, want 1", len(cleaned))
+	}
+	if len(mockGit.deleted) != 0 || len(mockGit.remoteDeleted) != 0 || len(mockGit.refs) != 4 {
+		t.Error("dry run deleted branches or records")
+	}
+
+	want := []string{"git branch -D migrated-1", "git push origin --delete migrated-1", "git update-ref -d refs/mfpr/1"}
+	if strings.Join(commands, "|") != strings.Join(want, "|") {
+		t.Errorf("dry run commands = %q, want %q", commands, want)
+	}
+}
+
+func TestCleanup_Declined(t *testing.T) {
+	client, mockGit := newCleanupTestClient(map[string]string{"migrated-1": "MERGED"})
+	var prompts []string
+	client.SetConfirmHandler(func(prompt string) bool {
+		prompts = append(prompts, prompt)
+		return false
+	})
+
+	if _, err := client.Cleanup(context.Background(), CleanupOptions{}); err != nil {
+		t.Fatalf("Cleanup() error = %v", err)
+	}
+	if len(prompts) != 1 || !strings.Contains(prompts[0], "migrated-1") {
+		t.Errorf("prompts = %v, want one prompt for migrated-1", prompts)
+	}
+	if len(mockGit.deleted) != 0 || len(mockGit.remoteDeleted) != 0 {
+		t.Errorf("Cleanup() deleted %v %v after the prompt was declined", mockGit.deleted, mockGit.remoteDeleted)
+	}
+}
+
+func TestCleanup_Target(t *testing.T) {
+	const remote = "git@github.com:acme/other.git"
+	mockGit := &mockGit{
+		refs: map[string][]byte{
+			"refs/mfpr/upstream/project/5": []byte(`{"owner":"upstream","repo":"project","number":5,"branch":"migrated-5","target":"acme/other"}`),
+		},
+		// A same-named branch on origin must be left alone.
+		remoteBranchAt: func(r, name string) bool {
+			return r == remote || r == "origin"
+		},
+	}
+	mockGitHub := &mockGitHub{
+		getPRFunc: func(_, _ string, number int) (*github.PRInfo, error) {
+			return &github.PRInfo{Number: number, State: "CLOSED"}, nil
+		},
+		findPRFunc: func(_, _, _ string) (*github.PRInfo, error) {
+			return &github.PRInfo{Number: 9, State: "MERGED"}, nil
+		},
+	}
+	client := newTestClient(mockGit, mockGitHub)
+	var commands, prompts []string
+	client.SetEventHandler(func(event Event) {
+		if event.Type == EventCommand {
+			commands = append(commands, event.Detail)
+		}
+	})
+
+	if _, err := client.Cleanup(context.Background(), CleanupOptions{DryRun: true}); err != nil {
+		t.Fatalf("Cleanup() dry run error = %v", err)
+	}
+	if want := "git push " + remote + " --delete migrated-5"; len(commands) < 1 || commands[0] != want {
+		t.Errorf("dry run commands = %q, want %q first", commands, want)
+	}
+
+	client.SetConfirmHandler(func(prompt string) bool {
+		prompts = append(prompts, prompt)
+		return true
+	})
+	if _, err := client.Cleanup(context.Background(), CleanupOptions{}); err != nil {
+		t.Fatalf("Cleanup() error = %v", err)
+	}
+	if got := strings.Join(mockGit.remoteDeleted, ","); got != remote+" migrated-5" {
+		t.Errorf("deleted remote branches = %q, want migrated-5 on %s only", got, remote)
+	}
+	if len(prompts) != 1 || !strings.Contains(prompts[0], "on acme/other") {
+		t.Errorf("prompts = %q, want one naming acme/other", prompts)
+	}
+}
+
+func TestCleanup_CheckedOutBranch(t *testing.T) {
+	mockGit := &mockGit{
+		refs: map[string][]byte{
+			"refs/mfpr/1": []byte(`{"number":1,"branch":"migrated-1"}`),
+		},
+		hasBranchFunc: func(context.Context, string) bool { return true },
+		currentBranch: "migrated-1",
+	}
+	var checkedOut []string
+	mockGit.checkoutFunc = func(_ context.Context, branch string) error {
+		checkedOut = append(checkedOut, branch)
+		mockGit.currentBranch = branch
+		return nil
+	}
+	mockGitHub := &mockGitHub{
+		getPRFunc: func(_, _ string, number int) (*github.PRInfo, error) {
+			return &github.PRInfo{Number: number, State: "CLOSED", BaseBranch: "main"}, nil
+		},
+		findPRFunc: func(_, _, _ string) (*github.PRInfo, error) {
+			return &github.PRInfo{Number: 9, State: "MERGED", BaseBranch: "develop"}, nil
+		},
+	}
+	client := newTestClient(mockGit, mockGitHub)
+	var commands []string
+	client.SetEventHandler(func(event Event) {
+		if event.Type == EventCommand {
+			commands = append(commands, event.Detail)
+		}
+	})
+
+	if _, err := client.Cleanup(context.Background(), CleanupOptions{DryRun: true}); err != nil {
+		t.Fatalf("Cleanup() dry run error = %v", err)
+	}
+	if len(commands) < 2 || commands[0] != "git checkout develop" || commands[1] != "git branch -D migrated-1" {
+		t.Errorf("dry run commands = %q, want a checkout of develop before deleting migrated-1", commands)
+	}
+
+	if _, err := client.Cleanup(context.Background(), CleanupOptions{}); err != nil {
+		t.Fatalf("Cleanup() error = %v", err)
+	}
+	if strings.Join(checkedOut, ",") != "develop" {
+		t.Errorf("checked out %q, want the replacement's base branch develop", checkedOut)
+	}
+	if strings.Join(mockGit.deleted, ",") != "migrated-1" {
+		t.Errorf("deleted %q, want migrated-1", mockGit.deleted)
+	}
+}
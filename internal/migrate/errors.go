@@ -1,14 +1,28 @@
 package migrate
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+	"time"
 
-type (
-	ErrPRNotFound struct {
-		Number int
-		Owner  string
-		Repo   string
-	}
+	"github.com/user/git-mfpr/internal/errs"
+	"github.com/user/git-mfpr/internal/github"
+)
 
+// Error categories, for use with errors.Is. Every error from a migration that
+// has a category reports it, including those from git and GitHub.
+var (
+	ErrUsage        = errs.ErrUsage
+	ErrNotFound     = errs.ErrNotFound
+	ErrPrecondition = errs.ErrPrecondition
+	ErrGit          = errs.ErrGit
+	ErrGitHub       = errs.ErrGitHub
+)
+
+// ErrPRNotFound is the error forge lookups return for a missing PR.
+type ErrPRNotFound = github.ErrPRNotFound
+
+type (
 	ErrPRNotFork struct {
 		Number int
 	}
@@ -25,24 +39,132 @@ type (
 	ErrInvalidPRRef struct {
 		Ref string
 	}
-)
 
-func (e ErrPRNotFound) Error() string {
-	return fmt.Sprintf("PR #%d not found in %s/%s", e.Number, e.Owner, e.Repo)
-}
+	ErrInvalidPRRange struct {
+		Ref    string
+		Detail string
+	}
+
+	ErrInvalidProvenance struct {
+		Ref    string
+		Detail string
+	}
+
+	ErrInvalidPathMap struct {
+		Line int
+		Text string
+	}
 
-func (e ErrPRNotFork) Error() string {
+	// ErrHunksRejected is a migration that left hunks to apply by hand, so
+	// its branch was kept but not pushed.
+	ErrHunksRejected struct {
+		Branch string
+		Count  int
+	}
+
+	ErrChecksFailed struct {
+		Branch string
+		Failed []string
+	}
+
+	ErrChecksTimedOut struct {
+		Branch  string
+		Timeout time.Duration
+		Summary string
+	}
+
+	ErrHookFailed struct {
+		Stage    Stage
+		Command  string
+		ExitCode int
+		Detail   string
+	}
+)
+
+func (e *ErrPRNotFork) Error() string {
 	return fmt.Sprintf("PR #%d is not from a fork", e.Number)
 }
 
-func (e ErrPRClosed) Error() string {
+func (e *ErrPRClosed) Error() string {
 	return fmt.Sprintf("PR #%d is %s", e.Number, e.State)
 }
 
-func (e ErrBranchExists) Error() string {
+func (e *ErrBranchExists) Error() string {
 	return fmt.Sprintf("branch %s already exists. Use --branch-name to specify a different name or delete the existing branch", e.BranchName)
 }
 
-func (e ErrInvalidPRRef) Error() string {
+func (e *ErrInvalidPRRef) Error() string {
 	return fmt.Sprintf("unsupported PR reference format: %s", e.Ref)
 }
+
+func (e *ErrInvalidPRRange) Error() string {
+	return fmt.Sprintf("invalid PR range %s: %s", e.Ref, e.Detail)
+}
+
+func (e *ErrInvalidProvenance) Error() string {
+	return fmt.Sprintf("invalid migration record in %s: %s", e.Ref, e.Detail)
+}
+
+func (e *ErrInvalidPathMap) Error() string {
+	return fmt.Sprintf("line %d: expected OLD:NEW, got %q", e.Line, e.Text)
+}
+
+func (e *ErrHookFailed) Error() string {
+	return fmt.Sprintf("%s hook %q failed with exit code %d: %s", e.Stage, e.Command, e.ExitCode, e.Detail)
+}
+
+func (e *ErrHunksRejected) Error() string {
+	return fmt.Sprintf("%d hunks could not be applied to %s; apply them from the .rej files, then push the branch", e.Count, e.Branch)
+}
+
+func (e *ErrChecksFailed) Error() string {
+	return fmt.Sprintf("checks failed on %s: %s", e.Branch, strings.Join(e.Failed, ", "))
+}
+
+func (e *ErrChecksTimedOut) Error() string {
+	return fmt.Sprintf("timed out after %s waiting for checks on %s (%s)", e.Timeout, e.Branch, e.Summary)
+}
+
+func (e *ErrPRNotFork) Is(target error) bool {
+	return target == errs.ErrPrecondition
+}
+
+func (e *ErrPRClosed) Is(target error) bool {
+	return target == errs.ErrPrecondition
+}
+
+func (e *ErrBranchExists) Is(target error) bool {
+	return target == errs.ErrPrecondition
+}
+
+func (e *ErrInvalidPRRef) Is(target error) bool {
+	return target == errs.ErrUsage
+}
+
+func (e *ErrInvalidPRRange) Is(target error) bool {
+	return target == errs.ErrUsage
+}
+
+func (e *ErrInvalidPathMap) Is(target error) bool {
+	return target == errs.ErrUsage
+}
+
+func (e *ErrHunksRejected) Is(target error) bool {
+	return target == errs.ErrGit
+}
+
+func (e *ErrInvalidProvenance) Is(target error) bool {
+	return target == errs.ErrGit
+}
+
+func (e *ErrHookFailed) Is(target error) bool {
+	return target == errs.ErrPrecondition
+}
+
+func (e *ErrChecksFailed) Is(target error) bool {
+	return target == errs.ErrPrecondition
+}
+
+func (e *ErrChecksTimedOut) Is(target error) bool {
+	return target == errs.ErrPrecondition
+}
@@ -1,7 +1,13 @@
 package migrate
 
 import (
+	"errors"
+	"fmt"
 	"testing"
+	"time"
+
+	"github.com/user/git-mfpr/internal/git"
+	"github.com/user/git-mfpr/internal/github"
 )
 
 func TestErrPRNotFound_Error(t *testing.T) {
@@ -29,3 +35,66 @@ func TestErrPRNotFound_EmptyFields(t *testing.T) {
 		t.Errorf("ErrPRNotFound.Error() = %q, want %q", err.Error(), expected)
 	}
 }
+
+func TestErrHookFailed_Error(t *testing.T) {
+	err := &ErrHookFailed{Stage: StagePrePush, Command: "make test", ExitCode: 2, Detail: "FAIL"}
+
+	expected := `pre-push hook "make test" failed with exit code 2: FAIL`
+	if err.Error() != expected {
+		t.Errorf("ErrHookFailed.Error() = %q, want %q", err.Error(), expected)
+	}
+}
+
+func TestErrChecks_Error(t *testing.T) {
+	failed := &ErrChecksFailed{Branch: "migrated-1", Failed: []string{"test", "lint"}}
+	if got, want := failed.Error(), "checks failed on migrated-1: test, lint"; got != want {
+		t.Errorf("ErrChecksFailed.Error() = %q, want %q", got, want)
+	}
+
+	timedOut := &ErrChecksTimedOut{Branch: "migrated-1", Timeout: 30 * time.Minute, Summary: "2 passed, 1 pending"}
+	if got, want := timedOut.Error(), "timed out after 30m0s waiting for checks on migrated-1 (2 passed, 1 pending)"; got != want {
+		t.Errorf("ErrChecksTimedOut.Error() = %q, want %q", got, want)
+	}
+}
+
+func TestErrorCategories(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{name: "invalid ref", err: &ErrInvalidPRRef{Ref: "x"}, want: ErrUsage},
+		{name: "invalid range", err: &ErrInvalidPRRange{Ref: "9-1"}, want: ErrUsage},
+		{name: "invalid path map", err: &ErrInvalidPathMap{Line: 1}, want: ErrUsage},
+		{name: "PR not found", err: &ErrPRNotFound{Number: 1}, want: ErrNotFound},
+		{name: "not a fork", err: &ErrPRNotFork{Number: 1}, want: ErrPrecondition},
+		{name: "closed", err: &ErrPRClosed{Number: 1, State: "merged"}, want: ErrPrecondition},
+		{name: "branch exists", err: &ErrBranchExists{BranchName: "b"}, want: ErrPrecondition},
+		{name: "wrapped git error", err: fmt.Errorf("PR 1: %w", &git.ErrPushFailed{Branch: "b"}), want: ErrGit},
+		{name: "github error", err: &github.ErrPRCreateFailed{Detail: "boom"}, want: ErrGitHub},
+	}
+
+	categories := []error{ErrUsage, ErrNotFound, ErrPrecondition, ErrGit, ErrGitHub}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, category := range categories {
+				if got := errors.Is(tt.err, category); got != (category == tt.want) {
+					t.Errorf("errors.Is(%v, %v) = %v", tt.err, category, got)
+				}
+			}
+		})
+	}
+
+	if err := (&ErrHookFailed{Stage: StagePrePush}); errors.Is(err, ErrGit) || errors.Is(err, ErrUsage) {
+		t.Error("ErrHookFailed should not belong to a category")
+	}
+}
+
+func TestErrPRNotFound_IsGitHubError(t *testing.T) {
+	var err error = &github.ErrPRNotFound{Number: 7, Owner: "o", Repo: "r"}
+
+	var target *ErrPRNotFound
+	if !errors.As(err, &target) || target.Number != 7 {
+		t.Errorf("errors.As(%v, *migrate.ErrPRNotFound) should find GitHub's error", err)
+	}
+}
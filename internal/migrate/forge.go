@@ -0,0 +1,204 @@
+package migrate
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"github.com/user/git-mfpr/internal/forge"
+	"github.com/user/git-mfpr/internal/forge/gitea"
+	"github.com/user/git-mfpr/internal/forge/gitlab"
+	"github.com/user/git-mfpr/internal/github"
+)
+
+// detectedForge is the forge used unless WithForge or WithGitHub chose one.
+// It is picked the first time it is needed: the kind set in git config
+// mfpr.forge, or else the one origin's host or the PR URL being migrated
+// points at, defaulting to GitHub.
+type detectedForge struct {
+	client *Client
+
+	mu    sync.Mutex
+	forge forge.Forge
+	// hint is the first PR URL seen, for when origin doesn't tell.
+	hint     *forge.Remote
+	hintKind forge.Kind
+}
+
+// forgeHint records the PR URL a reference named, in case the forge has not
+// been picked yet.
+func (c *Client) forgeHint(remote *forge.Remote, kind forge.Kind) {
+	d, ok := c.forge.(*detectedForge)
+	if !ok {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.hint == nil {
+		d.hint, d.hintKind = remote, kind
+	}
+}
+
+func (d *detectedForge) get(ctx context.Context) (forge.Forge, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.forge != nil {
+		return d.forge, nil
+	}
+
+	kind, remote, err := d.detect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	d.forge = d.client.newForge(kind, remote)
+	return d.forge, nil
+}
+
+func (d *detectedForge) detect(ctx context.Context) (forge.Kind, *forge.Remote, error) {
+	c := d.client
+
+	var remote *forge.Remote
+	if url, err := c.git.RemoteURL(ctx, "origin"); err == nil {
+		remote, _ = forge.ParseRemoteURL(url)
+	}
+	if remote == nil {
+		remote = d.hint
+	}
+
+	configured, err := c.git.Config(ctx, "mfpr.forge")
+	if err != nil {
+		return "", nil, err
+	}
+	if configured != "" {
+		kind, err := forge.ParseKind(configured)
+		return kind, remote, err
+	}
+
+	var kind forge.Kind
+	if remote != nil {
+		kind = forge.DetectHost(remote.Host)
+	}
+	// An unknown host may still be recognised by the shape of a PR URL on it.
+	if kind == "" && d.hint != nil && remote != nil && d.hint.Host == remote.Host {
+		kind = d.hintKind
+	}
+	if kind == "" {
+		kind = forge.GitHub
+	}
+	return kind, remote, nil
+}
+
+// newForge returns the client for kind, at remote's host for GitLab and
+// Gitea.
+func (c *Client) newForge(kind forge.Kind, remote *forge.Remote) forge.Forge {
+	// API requests are traced like the commands run through c.runner.
+	client := forge.ObserveHTTP(http.DefaultClient, c.traceCommand)
+	switch kind {
+	case forge.GitLab:
+		opts := []gitlab.Option{gitlab.WithTimeout(c.timeout), gitlab.WithRunner(c.runner), gitlab.WithDir(c.dir), gitlab.WithHTTPClient(client)}
+		if remote != nil {
+			opts = append(opts, gitlab.WithBaseURL(remote.BaseURL))
+		}
+		return gitlab.NewWithOptions(opts...)
+	case forge.Gitea:
+		opts := []gitea.Option{gitea.WithTimeout(c.timeout), gitea.WithRunner(c.runner), gitea.WithDir(c.dir), gitea.WithHTTPClient(client)}
+		if remote != nil {
+			opts = append(opts, gitea.WithBaseURL(remote.BaseURL))
+		}
+		return gitea.NewWithOptions(opts...)
+	default:
+		opts := []github.Option{github.WithTimeout(c.timeout), github.WithRunner(c.runner), github.WithDir(c.dir)}
+		if remote != nil {
+			opts = append(opts, github.WithHost(remote.Host))
+		}
+		return github.NewWithOptions(opts...)
+	}
+}
+
+// describer describes the forge's calls for dry runs and for PRs left for the
+// user to create. A forge that cannot describe itself is described as GitHub,
+// the default.
+func (c *Client) describer(ctx context.Context) forge.Describer {
+	f := c.forge
+	if d, ok := f.(*detectedForge); ok {
+		if detected, err := d.get(ctx); err == nil {
+			f = detected
+		}
+	}
+	if describe, ok := f.(forge.Describer); ok {
+		return describe
+	}
+	return github.New().(forge.Describer)
+}
+
+func (d *detectedForge) GetPR(ctx context.Context, owner, repo string, number int) (*PRInfo, error) {
+	f, err := d.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return f.GetPR(ctx, owner, repo, number)
+}
+
+func (d *detectedForge) CheckoutPR(ctx context.Context, owner, repo string, number int, branch string) error {
+	f, err := d.get(ctx)
+	if err != nil {
+		return err
+	}
+	return f.CheckoutPR(ctx, owner, repo, number, branch)
+}
+
+func (d *detectedForge) GetPRPatch(ctx context.Context, owner, repo string, number int) ([]byte, error) {
+	f, err := d.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return f.GetPRPatch(ctx, owner, repo, number)
+}
+
+func (d *detectedForge) CreatePR(ctx context.Context, owner, repo string, opts forge.CreatePROptions) (*PRInfo, error) {
+	f, err := d.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return f.CreatePR(ctx, owner, repo, opts)
+}
+
+func (d *detectedForge) ClosePR(ctx context.Context, owner, repo string, number int, comment string) error {
+	f, err := d.get(ctx)
+	if err != nil {
+		return err
+	}
+	return f.ClosePR(ctx, owner, repo, number, comment)
+}
+
+func (d *detectedForge) CommentPR(ctx context.Context, owner, repo string, number int, body string) error {
+	f, err := d.get(ctx)
+	if err != nil {
+		return err
+	}
+	return f.CommentPR(ctx, owner, repo, number, body)
+}
+
+func (d *detectedForge) FindPRForBranch(ctx context.Context, owner, repo, branch string) (*PRInfo, error) {
+	f, err := d.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return f.FindPRForBranch(ctx, owner, repo, branch)
+}
+
+func (d *detectedForge) ListPRs(ctx context.Context, owner, repo string, opts forge.ListOptions) ([]PRInfo, error) {
+	f, err := d.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return f.ListPRs(ctx, owner, repo, opts)
+}
+
+func (d *detectedForge) GetChecks(ctx context.Context, owner, repo, sha string) (*forge.Checks, error) {
+	f, err := d.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return f.GetChecks(ctx, owner, repo, sha)
+}
@@ -0,0 +1,185 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/user/git-mfpr/internal/forge"
+	"github.com/user/git-mfpr/internal/forge/gitea"
+	"github.com/user/git-mfpr/internal/forge/gitlab"
+	"github.com/user/git-mfpr/internal/github"
+)
+
+// forgeGit is a mockGit with a configurable origin and mfpr.forge.
+type forgeGit struct {
+	*mockGit
+	origin string
+	forge  string
+}
+
+func (m *forgeGit) RemoteURL(context.Context, string) (string, error) {
+	if m.origin == "" {
+		return "", fmt.Errorf("no such remote 'origin'")
+	}
+	return m.origin, nil
+}
+
+func (m *forgeGit) Config(_ context.Context, key string) (string, error) {
+	if key == "mfpr.forge" {
+		return m.forge, nil
+	}
+	return "", nil
+}
+
+func TestDetectedForge(t *testing.T) {
+	tests := []struct {
+		name    string
+		origin  string
+		config  string
+		hint    string
+		want    string
+		wantErr error
+	}{
+		{name: "GitHub origin", origin: "git@github.com:owner/repo.git", want: "github"},
+		{name: "GitLab origin", origin: "git@gitlab.example.com:group/sub/repo.git", want: "gitlab"},
+		{name: "Codeberg origin", origin: "https://codeberg.org/owner/repo.git", want: "gitea"},
+		{name: "Forgejo origin", origin: "ssh://git@forgejo.example.com:2222/owner/repo.git", want: "gitea"},
+		{name: "unknown host", origin: "git@git.example.com:owner/repo.git", want: "github"},
+		{name: "no origin", want: "github"},
+		{name: "configured", origin: "git@git.example.com:owner/repo.git", config: "gitlab", want: "gitlab"},
+		{name: "configured overrides the host", origin: "git@github.com:owner/repo.git", config: "forgejo", want: "gitea"},
+		{name: "invalid config", config: "bitbucket", wantErr: ErrUsage},
+		{name: "merge request URL on origin's host", origin: "git@git.example.com:owner/repo.git",
+			hint: "https://git.example.com/owner/repo/-/merge_requests/1", want: "gitlab"},
+		{name: "merge request URL without origin",
+			hint: "https://git.example.com/owner/repo/-/merge_requests/1", want: "gitlab"},
+		{name: "pulls URL on another host", origin: "git@git.example.com:owner/repo.git",
+			hint: "https://other.example.com/owner/repo/pulls/1", want: "github"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewWithOptions(WithGit(&forgeGit{mockGit: &mockGit{}, origin: tt.origin, forge: tt.config})).(*Client)
+			if tt.hint != "" {
+				if _, _, _, err := client.parsePRRef(context.Background(), tt.hint); err != nil {
+					t.Fatalf("parsePRRef(%q) error = %v", tt.hint, err)
+				}
+			}
+
+			f, err := client.forge.(*detectedForge).get(context.Background())
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("get() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("get() error = %v", err)
+			}
+
+			var got string
+			switch f.(type) {
+			case github.GitHub:
+				got = "github"
+			case *gitlab.Client:
+				got = "gitlab"
+			case *gitea.Client:
+				got = "gitea"
+			}
+			if got != tt.want {
+				t.Errorf("detected %T, want %s", f, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectedForge_UsesHintHost(t *testing.T) {
+	var requested string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = r.URL.EscapedPath()
+		fmt.Fprint(w, `{"iid": 7, "title": "Fix", "state": "opened", "source_branch": "fix", "target_branch": "main",
+			"source_project_id": 2, "target_project_id": 1, "author": {"username": "dev"}}`)
+	}))
+	defer server.Close()
+
+	client := NewWithOptions(WithGit(&forgeGit{mockGit: &mockGit{}})).(*Client)
+	owner, repo, number, err := client.parsePRRef(context.Background(), server.URL+"/group/repo/-/merge_requests/7")
+	if err != nil {
+		t.Fatalf("parsePRRef() error = %v", err)
+	}
+
+	pr, err := client.forge.GetPR(context.Background(), owner, repo, number)
+	if err != nil {
+		t.Fatalf("GetPR() error = %v", err)
+	}
+	if requested != "/api/v4/projects/group%2Frepo/merge_requests/7" {
+		t.Errorf("requested %s, want the merge request from the host in the URL", requested)
+	}
+	if pr.Number != 7 || !pr.IsFork || pr.State != forge.StateOpen {
+		t.Errorf("GetPR() = %+v, want open fork MR !7", pr)
+	}
+}
+
+func TestDryRun_DescribesDetectedForge(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"iid": 7, "title": "Fix", "state": "opened", "source_branch": "fix", "target_branch": "main",
+			"source_project_id": 2, "target_project_id": 1, "author": {"username": "dev"}}`)
+	}))
+	defer server.Close()
+
+	client := NewWithOptions(WithGit(&forgeGit{mockGit: &mockGit{}})).(*Client)
+	var commands []string
+	client.SetEventHandler(func(event Event) {
+		if event.Type == EventCommand {
+			commands = append(commands, event.Detail)
+		}
+	})
+	prRef := server.URL + "/group/repo/-/merge_requests/7"
+	if err := client.MigratePR(context.Background(), prRef, Options{DryRun: true, CloseOriginal: true}); err != nil {
+		t.Fatalf("MigratePR() error = %v", err)
+	}
+
+	all := strings.Join(commands, "\n")
+	for _, want := range []string{
+		"git fetch " + server.URL + "/group/repo.git refs/merge-requests/7/head:refs/heads/migrated-7 && git checkout migrated-7",
+		"POST " + server.URL + "/api/v4/projects/group%2Frepo/merge_requests",
+		`source_branch="migrated-7" target_branch="main"`,
+		"PUT " + server.URL + `/api/v4/projects/group%2Frepo/merge_requests/7 state_event="close"`,
+	} {
+		if !strings.Contains(all, want) {
+			t.Errorf("dry run missing %q:\n%s", want, all)
+		}
+	}
+	if strings.Contains(all, "gh ") {
+		t.Errorf("dry run of a GitLab MR describes gh commands:\n%s", all)
+	}
+}
+
+func TestDetectedForge_TracesRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	client := NewWithOptions(WithGit(&forgeGit{mockGit: &mockGit{}, forge: "gitea"}), WithTrace(TraceCommands)).(*Client)
+	var traced []string
+	client.SetEventHandler(func(event Event) {
+		if event.Type == EventExec {
+			traced = append(traced, fmt.Sprintf("%s exit %d", event.Message, event.Exec.ExitCode))
+		}
+	})
+	client.forgeHint(&forge.Remote{Host: server.Listener.Addr().String(), BaseURL: server.URL}, forge.Gitea)
+
+	if _, err := client.forge.GetPR(context.Background(), "owner", "repo", 7); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("GetPR() error = %v, want not found", err)
+	}
+	want := []string{"GET " + server.URL + "/api/v1/repos/owner/repo/pulls/7 exit 404"}
+	if fmt.Sprint(traced) != fmt.Sprint(want) {
+		t.Errorf("traced %q, want %q", traced, want)
+	}
+}
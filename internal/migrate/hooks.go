@@ -0,0 +1,85 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/user/git-mfpr/internal/runner"
+)
+
+// Stage is a point in a migration where hooks run.
+type Stage string
+
+const (
+	// StagePreCheckout runs on the starting branch, before anything changes.
+	StagePreCheckout Stage = "pre-checkout"
+	// StagePostCheckout runs on the migrated branch once it holds the PR's
+	// commits.
+	StagePostCheckout Stage = "post-checkout"
+	// StagePrePush runs on the migrated branch just before it is pushed.
+	StagePrePush Stage = "pre-push"
+	// StagePostCreate runs after the replacement PR has been created.
+	StagePostCreate Stage = "post-create"
+)
+
+// Stages lists the hook points in the order a migration reaches them.
+var Stages = []Stage{StagePreCheckout, StagePostCheckout, StagePrePush, StagePostCreate}
+
+// Hooks maps each stage to the shell commands run there, in order.
+type Hooks map[Stage][]string
+
+// hookEnv describes the migration to a hook through MFPR_* variables.
+func hookEnv(stage Stage, owner, repo string, pr *PRInfo, t *target, m *migration) []string {
+	env := []string{
+		"MFPR_STAGE=" + string(stage),
+		"MFPR_PR_NUMBER=" + strconv.Itoa(pr.Number),
+		"MFPR_PR_OWNER=" + owner,
+		"MFPR_PR_REPO=" + repo,
+		"MFPR_PR_TITLE=" + pr.Title,
+		"MFPR_PR_AUTHOR=" + pr.Author,
+		"MFPR_PR_URL=" + pr.URL,
+		"MFPR_HEAD_SHA=" + pr.HeadRefOID,
+		"MFPR_BASE_BRANCH=" + pr.BaseBranch,
+		"MFPR_BRANCH=" + m.branch,
+		"MFPR_TARGET=" + t.String(),
+	}
+	if m.strategy != "" {
+		env = append(env, "MFPR_STRATEGY="+string(m.strategy))
+	}
+	if m.created != nil {
+		env = append(env,
+			"MFPR_NEW_PR_NUMBER="+strconv.Itoa(m.created.Number),
+			"MFPR_NEW_PR_URL="+m.created.URL)
+	}
+	return env
+}
+
+// runHooks runs the hooks for stage with sh -c in the repository directory.
+// The first one to fail stops the migration.
+func (c *Client) runHooks(ctx context.Context, stage Stage, hooks Hooks, env []string) error {
+	for _, command := range hooks[stage] {
+		c.emit(EventInfo, fmt.Sprintf("Running %s hook: %s", stage, command), "")
+		result := c.runner.Run(ctx, runner.Cmd{Name: "sh", Args: []string{"-c", command}, Dir: c.dir, Env: env})
+		if result.Err == nil {
+			continue
+		}
+
+		detail := strings.TrimSpace(string(result.Stderr))
+		if detail == "" {
+			detail = strings.TrimSpace(string(result.Stdout))
+		}
+		if detail == "" {
+			detail = result.Err.Error()
+		}
+		return &ErrHookFailed{Stage: stage, Command: command, ExitCode: result.ExitCode, Detail: detail}
+	}
+	return nil
+}
+
+func (c *Client) dryRunHooks(stage Stage, hooks Hooks) {
+	for _, command := range hooks[stage] {
+		c.emit(EventCommand, fmt.Sprintf("Would run %s hook:", stage), command)
+	}
+}
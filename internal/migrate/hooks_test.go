@@ -0,0 +1,170 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/user/git-mfpr/internal/github"
+	"github.com/user/git-mfpr/internal/runner"
+)
+
+func TestMigratePR_Hooks(t *testing.T) {
+	logHook := `echo "$MFPR_STAGE $MFPR_PR_NUMBER $MFPR_BRANCH $MFPR_STRATEGY $MFPR_NEW_PR_NUMBER" >> hooks.log`
+
+	tests := []struct {
+		name        string
+		failAt      Stage
+		wantLog     string
+		wantPushed  bool
+		wantDeleted bool
+	}{
+		{
+			name: "all hooks pass",
+			wantLog: "pre-checkout 123 migrated-123  \n" +
+				"post-checkout 123 migrated-123 checkout \n" +
+				"pre-push 123 migrated-123 checkout \n" +
+				"post-create 123 migrated-123 checkout 456\n",
+			wantPushed: true,
+		},
+		{
+			name:    "pre-checkout fails",
+			failAt:  StagePreCheckout,
+			wantLog: "pre-checkout 123 migrated-123  \n",
+		},
+		{
+			name:        "post-checkout fails",
+			failAt:      StagePostCheckout,
+			wantLog:     "pre-checkout 123 migrated-123  \npost-checkout 123 migrated-123 checkout \n",
+			wantDeleted: true,
+		},
+		{
+			name:   "pre-push fails",
+			failAt: StagePrePush,
+			wantLog: "pre-checkout 123 migrated-123  \n" +
+				"post-checkout 123 migrated-123 checkout \n" +
+				"pre-push 123 migrated-123 checkout \n",
+			wantDeleted: true,
+		},
+		{
+			name:   "post-create fails",
+			failAt: StagePostCreate,
+			wantLog: "pre-checkout 123 migrated-123  \n" +
+				"post-checkout 123 migrated-123 checkout \n" +
+				"pre-push 123 migrated-123 checkout \n" +
+				"post-create 123 migrated-123 checkout 456\n",
+			wantPushed: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			hooks := Hooks{}
+			for _, stage := range Stages {
+				hooks[stage] = []string{logHook}
+			}
+			if tt.failAt != "" {
+				hooks[tt.failAt] = append(hooks[tt.failAt], "echo lockfile out of date >&2; exit 3", "echo not reached >> hooks.log")
+			}
+
+			client, mockGit, checkouts, _ := newRollbackTestClient(nil)
+			client.runner = runner.New()
+			client.dir = dir
+			pushed := false
+			mockGit.pushFunc = func(context.Context, string, string) error {
+				pushed = true
+				return nil
+			}
+			client.forge.(*mockGitHub).createPRFunc = func(string, string, github.CreatePROptions) (*github.PRInfo, error) {
+				return &github.PRInfo{Number: 456, URL: "https://github.com/owner/repo/pull/456"}, nil
+			}
+			client.SetConfirmHandler(func(string) bool { return true })
+
+			err := client.MigratePR(context.Background(), "123", Options{Hooks: hooks})
+
+			if tt.failAt == "" {
+				if err != nil {
+					t.Fatalf("MigratePR() error = %v", err)
+				}
+			} else {
+				var hookErr *ErrHookFailed
+				if !errors.As(err, &hookErr) {
+					t.Fatalf("MigratePR() error = %v, want ErrHookFailed", err)
+				}
+				if hookErr.Stage != tt.failAt || hookErr.ExitCode != 3 || hookErr.Detail != "lockfile out of date" {
+					t.Errorf("ErrHookFailed = %+v, want %s exiting 3 with its stderr", hookErr, tt.failAt)
+				}
+				if last := (*checkouts)[len(*checkouts)-1]; last != "main" {
+					t.Errorf("last checkout = %s, want the rollback back to main", last)
+				}
+			}
+
+			log, _ := os.ReadFile(filepath.Join(dir, "hooks.log"))
+			if string(log) != tt.wantLog {
+				t.Errorf("hooks.log =\n%s\nwant\n%s", log, tt.wantLog)
+			}
+			if pushed != tt.wantPushed {
+				t.Errorf("pushed = %v, want %v", pushed, tt.wantPushed)
+			}
+			deleted := strings.Join(mockGit.deleted, " ") == "migrated-123"
+			if deleted != tt.wantDeleted {
+				t.Errorf("deleted branches = %v, want migrated-123 deleted = %v", mockGit.deleted, tt.wantDeleted)
+			}
+		})
+	}
+}
+
+func TestMigratePR_HooksEnvironment(t *testing.T) {
+	dir := t.TempDir()
+	client, _, _, _ := newRollbackTestClient(nil)
+	client.runner = runner.New()
+	client.dir = dir
+
+	hooks := Hooks{StagePrePush: {"env | grep ^MFPR_ | sort > env.txt"}}
+	if err := client.MigratePR(context.Background(), "123", Options{NoCreate: true, Hooks: hooks}); err != nil {
+		t.Fatalf("MigratePR() error = %v", err)
+	}
+
+	env, _ := os.ReadFile(filepath.Join(dir, "env.txt"))
+	for _, want := range []string{
+		"MFPR_BASE_BRANCH=main",
+		"MFPR_BRANCH=migrated-123",
+		"MFPR_PR_AUTHOR=testuser",
+		"MFPR_PR_NUMBER=123",
+		"MFPR_PR_OWNER=testowner",
+		"MFPR_PR_REPO=testrepo",
+		"MFPR_PR_TITLE=Test PR",
+		"MFPR_STAGE=pre-push",
+		"MFPR_STRATEGY=checkout",
+		"MFPR_TARGET=testowner/testrepo",
+	} {
+		if !strings.Contains(string(env), want+"\n") {
+			t.Errorf("hook environment missing %s:\n%s", want, env)
+		}
+	}
+}
+
+func TestHandleDryRun_Hooks(t *testing.T) {
+	client := newTestClient(&mockGit{}, forkPRGitHub())
+	var events []Event
+	client.SetEventHandler(func(e Event) { events = append(events, e) })
+
+	hooks := Hooks{StagePostCheckout: {"go mod tidy"}, StagePrePush: {"make test"}}
+	if err := client.MigratePR(context.Background(), "123", Options{DryRun: true, NoPush: true, Hooks: hooks}); err != nil {
+		t.Fatalf("MigratePR() error = %v", err)
+	}
+
+	var got []string
+	for _, e := range events {
+		if strings.HasSuffix(e.Message, "hook:") {
+			got = append(got, e.Message+" "+e.Detail)
+		}
+	}
+	if want := "Would run post-checkout hook: go mod tidy"; len(got) != 1 || got[0] != want {
+		t.Errorf("hook events = %q, want only %q since nothing is pushed", got, want)
+	}
+}
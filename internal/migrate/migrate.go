@@ -2,14 +2,17 @@ package migrate
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"net/url"
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/user/git-mfpr/internal/forge"
 	"github.com/user/git-mfpr/internal/git"
 	"github.com/user/git-mfpr/internal/github"
+	"github.com/user/git-mfpr/internal/runner"
 )
 
 const (
@@ -17,6 +20,11 @@ const (
 	EventSuccess EventType = "success"
 	EventError   EventType = "error"
 	EventCommand EventType = "command"
+	EventExec    EventType = "exec"
+	EventResult  EventType = "result"
+	// EventStart is sent by MigratePRs before each PR, with the PR reference
+	// as its message.
+	EventStart EventType = "start"
 )
 
 type EventType string
@@ -24,49 +32,197 @@ type EventType string
 type PRInfo = github.PRInfo
 
 type Options struct {
-	DryRun     bool
-	BranchName string
-	NoPush     bool
-	NoCreate   bool
+	DryRun        bool
+	BranchName    string
+	NoPush        bool
+	NoCreate      bool
+	Force         bool
+	CloseOriginal bool
+	// Target is the owner/repo to migrate the PR into, when it is not the
+	// repository the PR was opened against.
+	Target string
+	// PathRenames rewrites the paths in the PR's commits, for PRs made before
+	// files were moved.
+	PathRenames []PathRename
+	// Hooks are shell commands run at each stage of the migration. A failing
+	// hook stops the migration and rolls it back.
+	Hooks Hooks
+	// Base is the branch to migrate the PR onto, when it is not the branch the
+	// PR targets. The PR's commits are replayed onto it.
+	Base string
+	// WaitChecks waits for CI on the pushed branch and fails the migration if
+	// a check fails or ChecksTimeout (default DefaultChecksTimeout) runs out.
+	WaitChecks    bool
+	ChecksTimeout time.Duration
 }
 
 type Event struct {
 	Type    EventType
 	Message string
 	Detail  string
+	// Exec is set on EventExec: the command that ran and how it finished.
+	Exec *runner.Result
+	// Result is set on EventResult, sent when each MigratePR finishes.
+	Result *MigrationResult
 }
 
+// TraceLevel controls which executed git and gh commands, and GitLab and Gitea
+// API requests, are reported as EventExec.
+type TraceLevel int
+
+const (
+	TraceOff TraceLevel = iota
+	// TraceCommands reports each command, its directory, exit code and duration.
+	TraceCommands
+	// TraceOutput also reports everything each command printed.
+	TraceOutput
+)
+
 type EventHandler func(Event)
 
+// ConfirmHandler is asked before pushing, creating or closing PRs and
+// overwriting branches. Returning false skips that step.
+type ConfirmHandler func(prompt string) bool
+
 type Migrator interface {
 	MigratePR(ctx context.Context, prRef string, opts Options) error
 
-	MigratePRs(ctx context.Context, prRefs []string, opts Options) error
+	MigratePRs(ctx context.Context, prRefs []string, opts Options) (*BatchResult, error)
 
 	GetPRInfo(ctx context.Context, prRef string) (*PRInfo, error)
+	ListOpenForkPRs(ctx context.Context, base string) ([]PRInfo, error)
 	GenerateBranchName(pr *PRInfo) string
 
+	ListMigrations(ctx context.Context) ([]Provenance, error)
+	Status(ctx context.Context) ([]MigrationStatus, error)
+	Cleanup(ctx context.Context, opts CleanupOptions) ([]MigrationStatus, error)
+
 	SetEventHandler(handler EventHandler)
+	SetConfirmHandler(handler ConfirmHandler)
 }
 
+// DefaultTimeout bounds each git and gh command unless WithTimeout says
+// otherwise.
+const DefaultTimeout = 5 * time.Minute
+
 type Client struct {
 	git     git.Git
-	github  github.GitHub
+	forge   forge.Forge
 	handler EventHandler
+	confirm ConfirmHandler
+	timeout time.Duration
+	trace   TraceLevel
+	runner  runner.Runner
+	dir     string
+	// checkInterval is how often WaitChecks polls; zero means the default.
+	checkInterval time.Duration
+}
+
+type Option func(*Client)
+
+// WithTimeout sets the per-command timeout. Zero disables it.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// WithTrace reports executed commands through the event handler.
+func WithTrace(level TraceLevel) Option {
+	return func(c *Client) {
+		c.trace = level
+	}
+}
+
+// WithRunner runs git and gh through r instead of executing them directly.
+func WithRunner(r runner.Runner) Option {
+	return func(c *Client) {
+		c.runner = r
+	}
+}
+
+// WithDir scopes every git and gh command, including detection of the
+// current repository, to dir instead of the working directory.
+func WithDir(dir string) Option {
+	return func(c *Client) {
+		c.dir = dir
+	}
+}
+
+// WithGit uses g for every git operation instead of running git. Commands
+// run by g are not traced.
+func WithGit(g git.Git) Option {
+	return func(c *Client) {
+		c.git = g
+	}
+}
+
+// WithGitHub uses g for every GitHub operation instead of running gh. Commands
+// run by g are not traced.
+func WithGitHub(g github.GitHub) Option {
+	return WithForge(g)
+}
+
+// WithForge uses f for every forge operation instead of detecting the forge
+// from origin.
+func WithForge(f forge.Forge) Option {
+	return func(c *Client) {
+		c.forge = f
+	}
 }
 
 func New() Migrator {
-	return &Client{
-		git:     git.New(),
-		github:  github.New(),
+	return NewWithOptions()
+}
+
+func NewWithOptions(opts ...Option) Migrator {
+	client := &Client{
+		timeout: DefaultTimeout,
+		runner:  runner.New(),
 		handler: func(Event) {},
 	}
+
+	for _, opt := range opts {
+		opt(client)
+	}
+
+	client.runner = runner.Observe(client.runner, client.traceCommand)
+	if client.git == nil {
+		client.git = git.NewWithOptions(git.WithTimeout(client.timeout), git.WithRunner(client.runner), git.WithDir(client.dir))
+	}
+	if client.forge == nil {
+		client.forge = &detectedForge{client: client}
+	}
+	return client
+}
+
+func (c *Client) traceCommand(result runner.Result) {
+	if c.trace == TraceOff {
+		return
+	}
+	if c.trace < TraceOutput {
+		result.Stdout, result.Stderr = nil, nil
+	}
+	c.handler(Event{Type: EventExec, Message: result.Cmd.String(), Exec: &result})
 }
 
 func (c *Client) SetEventHandler(handler EventHandler) {
 	c.handler = handler
 }
 
+func (c *Client) SetConfirmHandler(handler ConfirmHandler) {
+	c.confirm = handler
+}
+
+// confirmed asks the confirm handler, if any. Without one every step proceeds,
+// matching the behaviour before confirmations existed.
+func (c *Client) confirmed(prompt string) bool {
+	if c.confirm == nil {
+		return true
+	}
+	return c.confirm(prompt)
+}
+
 func (c *Client) emit(eventType EventType, message, detail string) {
 	c.handler(Event{
 		Type:    eventType,
@@ -75,9 +231,12 @@ func (c *Client) emit(eventType EventType, message, detail string) {
 	})
 }
 
-func (c *Client) parsePRRef(prRef string) (owner, repo string, number int, err error) {
-	if num, err := strconv.Atoi(prRef); err == nil {
-		ctx := context.Background()
+// parsePRRef resolves a single PR reference: a number or #number in the
+// current repository, owner/repo#number (where owner may be a GitLab group
+// path), owner/repo/pull/number, or a GitHub, GitLab or Gitea PR URL, which
+// may carry trailing segments like /files, a query or a fragment.
+func (c *Client) parsePRRef(ctx context.Context, prRef string) (owner, repo string, number int, err error) {
+	if num, err := strconv.Atoi(strings.TrimPrefix(prRef, "#")); err == nil {
 		owner, repo, err = c.git.CurrentRepo(ctx)
 		if err != nil {
 			return "", "", 0, fmt.Errorf("not in a git repository or no origin remote: %w", err)
@@ -85,23 +244,27 @@ func (c *Client) parsePRRef(prRef string) (owner, repo string, number int, err e
 		return owner, repo, num, nil
 	}
 
-	if strings.HasPrefix(prRef, "http://") || strings.HasPrefix(prRef, "https://") {
-		u, err := url.Parse(prRef)
+	// URLs come before owner/repo#N, as their #fragment is not a PR number.
+	if strings.Contains(prRef, "://") {
+		remote, number, kind, err := forge.ParsePRURL(prRef)
 		if err != nil {
-			return "", "", 0, fmt.Errorf("invalid URL: %w", err)
+			return "", "", 0, err
 		}
+		c.forgeHint(remote, kind)
+		return remote.Owner, remote.Repo, number, nil
+	}
 
-		parts := strings.Split(strings.Trim(u.Path, "/"), "/")
-		if len(parts) != 4 || parts[2] != "pull" {
-			return "", "", 0, fmt.Errorf("invalid GitHub PR URL format")
+	if strings.Contains(prRef, "/pull/") || strings.Contains(prRef, "/pulls/") || strings.Contains(prRef, "/-/merge_requests/") {
+		ref := strings.TrimPrefix(prRef, "/")
+		if host, _, _ := strings.Cut(ref, "/"); strings.Contains(host, ".") {
+			return c.parsePRRef(ctx, "https://"+ref)
 		}
-
-		num, err := strconv.Atoi(parts[3])
-		if err != nil {
-			return "", "", 0, fmt.Errorf("invalid PR number in URL: %w", err)
+		// No host, so the first segment is the owner: owner/repo/pull/N.
+		if i := strings.IndexAny(ref, "?#"); i >= 0 {
+			ref = ref[:i]
 		}
-
-		return parts[0], parts[1], num, nil
+		owner, repo, number, _, err := forge.ParsePRPath(ref)
+		return owner, repo, number, err
 	}
 
 	if strings.Contains(prRef, "#") {
@@ -115,24 +278,44 @@ func (c *Client) parsePRRef(prRef string) (owner, repo string, number int, err e
 			return "", "", 0, fmt.Errorf("invalid PR number: %w", err)
 		}
 
-		repoParts := strings.Split(parts[0], "/")
-		if len(repoParts) != 2 {
+		i := strings.LastIndex(parts[0], "/")
+		if i <= 0 || i == len(parts[0])-1 {
 			return "", "", 0, fmt.Errorf("invalid repo format, expected owner/repo")
 		}
 
-		return repoParts[0], repoParts[1], num, nil
+		return parts[0][:i], parts[0][i+1:], num, nil
 	}
 
 	return "", "", 0, &ErrInvalidPRRef{Ref: prRef}
 }
 
 func (c *Client) GetPRInfo(ctx context.Context, prRef string) (*PRInfo, error) {
-	owner, repo, number, err := c.parsePRRef(prRef)
+	owner, repo, number, err := c.parsePRRef(ctx, prRef)
 	if err != nil {
 		return nil, err
 	}
 
-	return c.github.GetPR(ctx, owner, repo, number)
+	return c.forge.GetPR(ctx, owner, repo, number)
+}
+
+func (c *Client) ListOpenForkPRs(ctx context.Context, base string) ([]PRInfo, error) {
+	owner, repo, err := c.git.CurrentRepo(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("not in a git repository or no origin remote: %w", err)
+	}
+
+	prs, err := c.forge.ListPRs(ctx, owner, repo, github.ListOptions{Base: base, State: "open", Limit: 200})
+	if err != nil {
+		return nil, err
+	}
+
+	forkPRs := make([]PRInfo, 0, len(prs))
+	for _, pr := range prs {
+		if pr.IsFork {
+			forkPRs = append(forkPRs, pr)
+		}
+	}
+	return forkPRs, nil
 }
 
 func (c *Client) GenerateBranchName(pr *PRInfo) string {
@@ -156,14 +339,14 @@ func slugify(s string) string {
 	return s
 }
 
-func (c *Client) validatePRState(pr *PRInfo) error {
-	if !pr.IsFork {
-		c.emit(EventError, "PR is not from a fork (it's from the same repository)", "")
+// validatePRState checks that pr can be migrated. PRs from the same repository
+// only make sense to migrate into a different one.
+func (c *Client) validatePRState(pr *PRInfo, cross bool) error {
+	if !pr.IsFork && !cross {
 		return &ErrPRNotFork{Number: pr.Number}
 	}
 	// GitHub returns state in uppercase, so we need to compare case-insensitively
 	if !strings.EqualFold(pr.State, "open") {
-		c.emit(EventError, fmt.Sprintf("PR is %s (only open PRs can be migrated)", pr.State), "")
 		return &ErrPRClosed{Number: pr.Number, State: pr.State}
 	}
 	return nil
@@ -180,25 +363,69 @@ func (c *Client) emitPRInfo(pr *PRInfo) {
 	}
 }
 
-func (c *Client) handleDryRun(pr *PRInfo, branchName string, opts Options) {
+func (c *Client) handleDryRun(ctx context.Context, owner, repo string, pr *PRInfo, t *target, branchName, fromBase string, overwrite bool, opts Options) {
+	c.dryRunHooks(StagePreCheckout, opts.Hooks)
 	c.emit(EventCommand, "Would execute:", "git checkout "+pr.BaseBranch)
-	c.emit(EventCommand, "Would execute:", "git pull origin "+pr.BaseBranch)
-	c.emit(EventCommand, "Would execute:", fmt.Sprintf("gh pr checkout %d -b %s", pr.Number, branchName))
+	if t.remote == "origin" {
+		c.emit(EventCommand, "Would execute:", "git pull origin "+pr.BaseBranch)
+	}
+	if overwrite {
+		c.emit(EventCommand, "Would execute:", "git branch -D "+branchName)
+	}
+	describe := c.describer(ctx)
+	patchCommand := describe.DescribeGetPRPatch(owner, repo, pr.Number)
+	if fromBase != "" {
+		c.emit(EventCommand, fmt.Sprintf("Would replay the PR's commits from %s onto %s:", fromBase, pr.BaseBranch), patchCommand)
+	} else {
+		c.emit(EventCommand, "Would execute:", describe.DescribeCheckoutPR(owner, repo, pr.Number, branchName))
+		c.emit(EventCommand, "If the fork is unreachable, would execute instead:", patchCommand)
+	}
+	if len(opts.PathRenames) > 0 && fromBase == "" {
+		c.emit(EventCommand, "Would execute:", fmt.Sprintf("git format-patch --stdout $(git merge-base %s %s)..%s", pr.BaseBranch, branchName, branchName))
+		for _, rename := range opts.PathRenames {
+			c.emit(EventInfo, fmt.Sprintf("Would rename paths %s* to %s*", rename.Old, rename.New), "")
+		}
+		c.emit(EventCommand, "Would execute:", fmt.Sprintf("git checkout -B %s %s", branchName, pr.BaseBranch))
+		c.emit(EventCommand, "Would execute:", "git am <remapped patches>")
+	}
+	c.dryRunHooks(StagePostCheckout, opts.Hooks)
+	c.emit(EventCommand, "Would execute:", "git update-ref "+provenanceRef(owner, repo, pr.Number)+" <migration record>")
 	if !opts.NoPush {
-		c.emit(EventCommand, "Would execute:", "git push -u origin "+branchName)
+		c.dryRunHooks(StagePrePush, opts.Hooks)
+		if overwrite {
+			c.emit(EventCommand, "Would execute:", fmt.Sprintf("git push --force -u %s %s", t.remote, branchName))
+		} else {
+			c.emit(EventCommand, "Would execute:", fmt.Sprintf("git push -u %s %s", t.remote, branchName))
+		}
 	}
 	if !opts.NoCreate {
-		c.emit(EventInfo, "Would suggest creating PR with:", "")
-		c.emit(EventCommand, "", fmt.Sprintf(`gh pr create --title "%s" --body "Migrated from #%d\nOriginal author: @%s" --base %s`,
-			pr.Title, pr.Number, pr.Author, pr.BaseBranch))
+		c.emit(EventInfo, "Would offer to create PR with:", "")
+		c.emit(EventCommand, "", createPRCommand(describe, owner, repo, pr, t, branchName))
+		c.dryRunHooks(StagePostCreate, opts.Hooks)
+		if opts.CloseOriginal {
+			c.emit(EventCommand, "Would execute:", describe.DescribeClosePR(owner, repo, pr.Number))
+		}
+	}
+	if opts.WaitChecks && !opts.NoPush {
+		timeout := opts.ChecksTimeout
+		if timeout <= 0 {
+			timeout = DefaultChecksTimeout
+		}
+		c.emit(EventInfo, fmt.Sprintf("Would wait up to %s for checks on %s in %s", timeout, branchName, t), "")
 	}
 }
 
-func (c *Client) checkoutAndPullBase(ctx context.Context, pr *PRInfo) error {
+// checkoutAndPullBase switches to the base branch and, when the target is
+// origin, brings it up to date. A target pushed to by URL is a different
+// repository from the local one, so its base branch is not pulled.
+func (c *Client) checkoutAndPullBase(ctx context.Context, pr *PRInfo, t *target) error {
 	c.emit(EventInfo, fmt.Sprintf("Switching to %s branch...", pr.BaseBranch), "")
 	if err := c.git.Checkout(ctx, pr.BaseBranch); err != nil {
 		return err
 	}
+	if t.remote != "origin" {
+		return nil
+	}
 	c.emit(EventInfo, "Pulling latest changes...", "")
 	if err := c.git.Pull(ctx, "origin", pr.BaseBranch); err != nil {
 		return err
@@ -206,102 +433,300 @@ func (c *Client) checkoutAndPullBase(ctx context.Context, pr *PRInfo) error {
 	return nil
 }
 
-func (c *Client) pushAndEmit(ctx context.Context, branchName string) error {
-	c.emit(EventInfo, "Pushing to origin...", "")
-	if err := c.git.Push(ctx, "origin", branchName); err != nil {
+func (c *Client) pushAndEmit(ctx context.Context, t *target, branchName string) error {
+	c.emit(EventInfo, fmt.Sprintf("Pushing to %s...", t.pushLabel()), "")
+	if err := c.git.Push(ctx, t.remote, branchName); err != nil {
+		return err
+	}
+	c.emit(EventSuccess, fmt.Sprintf("Pushed to %s", t.pushLabel()), "")
+	return nil
+}
+
+func (c *Client) pushBranch(ctx context.Context, t *target, branchName string, overwrite bool) (bool, error) {
+	if !c.confirmed(fmt.Sprintf("Push %s to %s?", branchName, t.pushLabel())) {
+		c.emit(EventInfo, fmt.Sprintf("Skipped pushing to %s", t.pushLabel()), "")
+		return false, nil
+	}
+
+	if !overwrite {
+		if err := c.pushAndEmit(ctx, t, branchName); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+
+	c.emit(EventInfo, fmt.Sprintf("Force-pushing to %s...", t.pushLabel()), "")
+	if err := c.git.ForcePush(ctx, t.remote, branchName); err != nil {
+		return false, err
+	}
+	c.emit(EventSuccess, fmt.Sprintf("Pushed to %s", t.pushLabel()), "")
+	return true, nil
+}
+
+func replacementBody(owner, repo string, pr *PRInfo, t *target) string {
+	return fmt.Sprintf("Migrated from %s\nOriginal author: @%s", t.ref(owner, repo, pr.Number), pr.Author)
+}
+
+// createPRCommand describes creating the replacement PR for branch, in the
+// forge's own terms.
+func createPRCommand(describe forge.Describer, owner, repo string, pr *PRInfo, t *target, branch string) string {
+	return describe.DescribeCreatePR(t.owner, t.repo, forge.CreatePROptions{
+		Title: pr.Title,
+		Body:  replacementBody(owner, repo, pr, t),
+		Base:  pr.BaseBranch,
+		Head:  branch,
+	})
+}
+
+// offerCreatePR creates the replacement PR when a confirm handler agrees to it,
+// and otherwise prints the command to create it by hand.
+func (c *Client) offerCreatePR(ctx context.Context, owner, repo string, pr *PRInfo, t *target, m *migration, opts Options) error {
+	prompt := fmt.Sprintf("Create a pull request for %s against %s?", m.branch, pr.BaseBranch)
+	if t.cross {
+		prompt = fmt.Sprintf("Create a pull request for %s against %s in %s?", m.branch, pr.BaseBranch, t)
+	}
+	if c.confirm == nil || !c.confirm(prompt) {
+		c.emitCreatePR(ctx, owner, repo, pr, t, m.branch)
+		return nil
+	}
+
+	created, err := c.forge.CreatePR(ctx, t.owner, t.repo, github.CreatePROptions{
+		Title: pr.Title,
+		Body:  replacementBody(owner, repo, pr, t),
+		Base:  pr.BaseBranch,
+		Head:  m.branch,
+	})
+	if err != nil {
+		return err
+	}
+	c.emit(EventSuccess, fmt.Sprintf("Created PR #%d", created.Number), created.URL)
+	m.created = created
+
+	if err := c.runHooks(ctx, StagePostCreate, opts.Hooks, hookEnv(StagePostCreate, owner, repo, pr, t, m)); err != nil {
+		return err
+	}
+
+	if !opts.CloseOriginal {
+		return nil
+	}
+	replacement := t.ref(t.owner, t.repo, created.Number)
+	if !c.confirmed(fmt.Sprintf("Close original PR #%d in favour of %s?", pr.Number, replacement)) {
+		c.emit(EventInfo, fmt.Sprintf("Left original PR #%d open", pr.Number), "")
+		return nil
+	}
+
+	comment := fmt.Sprintf("This PR has been migrated to %s so maintainers can continue the work. Thank you, @%s!", replacement, pr.Author)
+	if err := c.forge.ClosePR(ctx, owner, repo, pr.Number, comment); err != nil {
 		return err
 	}
-	c.emit(EventSuccess, "Pushed to origin", "")
+	c.emit(EventSuccess, fmt.Sprintf("Closed original PR #%d", pr.Number), "")
 	return nil
 }
 
-func (c *Client) emitCreatePR(pr *PRInfo) {
+func (c *Client) emitCreatePR(ctx context.Context, owner, repo string, pr *PRInfo, t *target, branch string) {
 	c.emit(EventInfo, "", "")
 	c.emit(EventInfo, "Create PR with:", "")
-	c.emit(EventCommand, "", fmt.Sprintf(`gh pr create --title "%s" --body "Migrated from #%d\nOriginal author: @%s" --base %s`,
-		pr.Title, pr.Number, pr.Author, pr.BaseBranch))
+	c.emit(EventCommand, "", createPRCommand(c.describer(ctx), owner, repo, pr, t, branch))
 }
 
 func (c *Client) MigratePR(ctx context.Context, prRef string, opts Options) error {
-	owner, repo, number, err := c.parsePRRef(prRef)
+	return c.migrate(ctx, prRef, opts).Err
+}
+
+// migrate migrates one PR and reports how it went through an EventResult.
+func (c *Client) migrate(ctx context.Context, prRef string, opts Options) *MigrationResult {
+	result := &MigrationResult{Ref: prRef, DryRun: opts.DryRun, Started: time.Now()}
+	result.Err = c.migratePR(ctx, prRef, opts, result)
+	result.Duration = time.Since(result.Started)
+	c.handler(Event{Type: EventResult, Message: prRef, Result: result})
+	return result
+}
+
+// migratePR migrates one PR, filling in result as it goes.
+func (c *Client) migratePR(ctx context.Context, prRef string, opts Options, result *MigrationResult) error {
+	owner, repo, number, err := c.parsePRRef(ctx, prRef)
 	if err != nil {
 		return err
 	}
 
-	c.emit(EventInfo, fmt.Sprintf("Migrating PR #%d from %s/%s", number, owner, repo), "")
+	t, err := c.resolveTarget(ctx, owner, repo, opts)
+	if err != nil {
+		return err
+	}
+
+	if t.cross {
+		c.emit(EventInfo, fmt.Sprintf("Migrating PR #%d from %s/%s into %s", number, owner, repo, t), "")
+	} else {
+		c.emit(EventInfo, fmt.Sprintf("Migrating PR #%d from %s/%s", number, owner, repo), "")
+	}
 	c.emit(EventInfo, "Fetching PR information...", "")
-	pr, err := c.github.GetPR(ctx, owner, repo, number)
+	pr, err := c.forge.GetPR(ctx, owner, repo, number)
 	if err != nil {
 		return err
 	}
+	result.PR = pr
 
-	if err := c.validatePRState(pr); err != nil {
+	if err := c.validatePRState(pr, t.cross); err != nil {
 		return err
 	}
 
 	c.emitPRInfo(pr)
 
+	fromBase := ""
+	if opts.Base != "" && opts.Base != pr.BaseBranch {
+		c.emit(EventInfo, fmt.Sprintf("Retargeting from %s to %s", pr.BaseBranch, opts.Base), "")
+		fromBase, pr.BaseBranch = pr.BaseBranch, opts.Base
+	}
+
 	branchName := opts.BranchName
 	if branchName == "" {
 		branchName = c.GenerateBranchName(pr)
 	}
 	c.emit(EventInfo, fmt.Sprintf("Branch: %s", branchName), "")
+	result.Branch = branchName
 
+	overwrite := false
 	if c.git.HasBranch(ctx, branchName) {
-		return &ErrBranchExists{BranchName: branchName}
+		if !opts.Force {
+			return &ErrBranchExists{BranchName: branchName}
+		}
+		if !opts.DryRun && !c.confirmed(fmt.Sprintf("Branch %s already exists. Overwrite it?", branchName)) {
+			return &ErrBranchExists{BranchName: branchName}
+		}
+		overwrite = true
 	}
 
 	if opts.DryRun {
-		c.handleDryRun(pr, branchName, opts)
+		c.handleDryRun(ctx, owner, repo, pr, t, branchName, fromBase, overwrite, opts)
 		return nil
 	}
 
-	if err := c.checkoutAndPullBase(ctx, pr); err != nil {
-		return err
+	m := &migration{branch: branchName, base: pr.BaseBranch, fromBase: fromBase, record: provenanceRef(owner, repo, pr.Number), pushedTo: t.pushLabel()}
+	if current, err := c.git.CurrentBranch(ctx); err == nil {
+		m.originalBranch = current
 	}
+	defer func() {
+		result.Commits, result.Strategy, result.Rejects = m.commits, m.strategy, len(m.rejects)
+		result.Pushed, result.SHA, result.Created = m.pushed, m.sha, m.created
+	}()
 
-	c.emit(EventInfo, fmt.Sprintf("Checking out PR #%d...", pr.Number), "")
-	if err := c.github.CheckoutPR(ctx, owner, repo, pr.Number, branchName); err != nil {
+	if err := c.migrateBranch(ctx, owner, repo, pr, t, m, overwrite, opts); err != nil {
+		c.rollback(ctx, m, err)
 		return err
 	}
 
-	if !opts.NoPush {
-		if err := c.pushAndEmit(ctx, branchName); err != nil {
+	if len(m.rejects) > 0 {
+		return &ErrHunksRejected{Branch: branchName, Count: len(m.rejects)}
+	}
+	c.emit(EventSuccess, fmt.Sprintf("Successfully migrated PR #%d", pr.Number), "")
+
+	if !opts.NoCreate && m.pushed {
+		if err := c.offerCreatePR(ctx, owner, repo, pr, t, m, opts); err != nil {
+			var hookErr *ErrHookFailed
+			if errors.As(err, &hookErr) {
+				c.rollback(ctx, m, err)
+			}
 			return err
 		}
 	}
 
-	c.emit(EventSuccess, fmt.Sprintf("Successfully migrated PR #%d", pr.Number), "")
-
-	if !opts.NoCreate && !opts.NoPush {
-		c.emitCreatePR(pr)
+	if opts.WaitChecks && m.pushed {
+		return c.waitForChecks(ctx, t, m.branch, opts.ChecksTimeout)
 	}
 
 	return nil
 }
 
-func (c *Client) MigratePRs(ctx context.Context, prRefs []string, opts Options) error {
-	var errors []string
-	successCount := 0
+// migrateBranch runs the stages of a migration up to pushing the branch:
+// checkout, recording provenance and push, each with its hooks. Every step is
+// recorded in m so that a failure can be rolled back.
+func (c *Client) migrateBranch(ctx context.Context, owner, repo string, pr *PRInfo, t *target, m *migration, overwrite bool, opts Options) error {
+	hooks := func(stage Stage) error {
+		return c.runHooks(ctx, stage, opts.Hooks, hookEnv(stage, owner, repo, pr, t, m))
+	}
 
-	for _, prRef := range prRefs {
-		c.emit(EventInfo, "", "")
-		err := c.MigratePR(ctx, prRef, opts)
-		if err != nil {
-			errors = append(errors, fmt.Sprintf("PR %s: %v", prRef, err))
-			c.emit(EventError, fmt.Sprintf("Failed to migrate %s", prRef), err.Error())
-		} else {
-			successCount++
+	if err := hooks(StagePreCheckout); err != nil {
+		return err
+	}
+	if err := c.checkoutAndPullBase(ctx, pr, t); err != nil {
+		return err
+	}
+
+	if overwrite {
+		c.emit(EventInfo, fmt.Sprintf("Deleting existing branch %s...", m.branch), "")
+		if err := c.git.DeleteBranch(ctx, m.branch); err != nil {
+			return err
 		}
 	}
 
-	if len(errors) > 0 {
-		c.emit(EventInfo, "", "")
-		c.emit(EventInfo, fmt.Sprintf("Migrated %d/%d PRs successfully", successCount, len(prRefs)), "")
-		return fmt.Errorf("failed to migrate some PRs:\n%s", strings.Join(errors, "\n"))
+	if err := c.createBranch(ctx, owner, repo, pr, m, opts.PathRenames); err != nil {
+		return err
+	}
+	if commits, err := c.git.CountCommits(ctx, pr.BaseBranch+".."+m.branch); err == nil {
+		m.commits = commits
+	}
+	if err := hooks(StagePostCheckout); err != nil {
+		return err
 	}
 
-	c.emit(EventInfo, "", "")
-	c.emit(EventSuccess, fmt.Sprintf("Successfully migrated all %d PRs", len(prRefs)), "")
-	return nil
+	if err := c.recordProvenance(ctx, owner, repo, pr, t, m); err != nil {
+		return err
+	}
+	m.recorded = true
+	c.emit(EventInfo, fmt.Sprintf("Recorded migration in %s", m.record), "")
+
+	if len(m.rejects) > 0 {
+		c.reportRejects(m.branch, m.rejects)
+	}
+	if opts.NoPush {
+		return nil
+	}
+	if len(m.rejects) > 0 {
+		c.emit(EventInfo, fmt.Sprintf("Not pushing %s until the rejected hunks are applied", m.branch), "")
+		return nil
+	}
+	if err := hooks(StagePrePush); err != nil {
+		return err
+	}
+
+	pushed, err := c.pushBranch(ctx, t, m.branch, overwrite)
+	m.pushed = pushed
+	if pushed {
+		if sha, err := c.git.RevParse(ctx, m.branch); err == nil {
+			m.sha = sha
+		}
+	}
+	return err
+}
+
+// MigratePRs migrates each PR in turn, carrying on past failures. It stops
+// early only if ctx is cancelled, recording the PRs it skipped as failed with
+// ctx's error. The returned error is the batch's Err.
+func (c *Client) MigratePRs(ctx context.Context, prRefs []string, opts Options) (*BatchResult, error) {
+	batch := &BatchResult{Started: time.Now()}
+
+	for i, prRef := range prRefs {
+		if ctx.Err() != nil {
+			c.emit(EventInfo, fmt.Sprintf("Interrupted; skipped %d remaining PRs", len(prRefs)-i), "")
+			for _, skipped := range prRefs[i:] {
+				batch.Results = append(batch.Results, MigrationResult{Ref: skipped, DryRun: opts.DryRun, Err: ctx.Err()})
+			}
+			break
+		}
+
+		c.handler(Event{Type: EventStart, Message: prRef})
+		batch.Results = append(batch.Results, *c.migrate(ctx, prRef, opts))
+	}
+	batch.Duration = time.Since(batch.Started)
+
+	if len(prRefs) > 1 {
+		failed := len(batch.Failed())
+		c.emit(EventInfo, "", "")
+		if failed == 0 {
+			c.emit(EventSuccess, fmt.Sprintf("Successfully migrated all %d PRs", len(prRefs)), "")
+		} else {
+			c.emit(EventInfo, fmt.Sprintf("Migrated %d/%d PRs successfully", len(prRefs)-failed, len(prRefs)), "")
+		}
+	}
+	return batch, batch.Err()
 }
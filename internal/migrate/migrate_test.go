@@ -9,6 +9,7 @@ import (
 
 	"github.com/user/git-mfpr/internal/git"
 	"github.com/user/git-mfpr/internal/github"
+	"github.com/user/git-mfpr/internal/runner"
 )
 
 type mockGit struct {
@@ -17,6 +18,15 @@ type mockGit struct {
 	checkoutFunc    func(context.Context, string) error
 	pullFunc        func(context.Context, string, string) error
 	pushFunc        func(context.Context, string, string) error
+	refs            map[string][]byte
+	remoteBranches  map[string]bool
+	// remoteBranchAt, if set, answers HasRemoteBranch by remote.
+	remoteBranchAt func(remote, name string) bool
+	// currentBranch, if set, is the checked-out branch instead of main.
+	currentBranch string
+	deleted       []string
+	remoteDeleted []string
+	forcePushed   []string
 }
 
 func (m *mockGit) CurrentRepo(ctx context.Context) (string, string, error) {
@@ -26,6 +36,20 @@ func (m *mockGit) CurrentRepo(ctx context.Context) (string, string, error) {
 	return "testowner", "testrepo", nil
 }
 
+func (m *mockGit) RevParse(_ context.Context, _ string) (string, error) {
+	return "0123456789abcdef0123456789abcdef01234567", nil
+}
+
+func (m *mockGit) CountCommits(_ context.Context, _ string) (int, error) { return 2, nil }
+
+func (m *mockGit) RemoteURL(ctx context.Context, _ string) (string, error) {
+	owner, repo, err := m.CurrentRepo(ctx)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("git@github.com:%s/%s.git", owner, repo), nil
+}
+
 func (m *mockGit) HasBranch(ctx context.Context, name string) bool {
 	if m.hasBranchFunc != nil {
 		return m.hasBranchFunc(ctx, name)
@@ -54,9 +78,86 @@ func (m *mockGit) Push(ctx context.Context, remote, branch string) error {
 	return nil
 }
 
-func (m *mockGit) CurrentBranch(_ context.Context) (string, error) { return "main", nil }
-func (m *mockGit) DeleteBranch(_ context.Context, _ string) error  { return nil }
-func (m *mockGit) IsInRepo(_ context.Context) bool                 { return true }
+func (m *mockGit) ForcePush(ctx context.Context, remote, branch string) error {
+	m.forcePushed = append(m.forcePushed, branch)
+	if m.pushFunc != nil {
+		return m.pushFunc(ctx, remote, branch)
+	}
+	return nil
+}
+
+func (m *mockGit) DeleteRef(_ context.Context, ref string) error {
+	delete(m.refs, ref)
+	return nil
+}
+
+func (m *mockGit) Config(_ context.Context, _ string) (string, error)      { return "", nil }
+func (m *mockGit) ConfigAll(_ context.Context, _ string) ([]string, error) { return nil, nil }
+
+func (m *mockGit) MergeBase(_ context.Context, a, _ string) (string, error) { return a, nil }
+
+func (m *mockGit) FormatPatch(_ context.Context, _ string) ([]byte, error) { return nil, nil }
+
+func (m *mockGit) ResetBranch(_ context.Context, _, _ string) error { return nil }
+
+func (m *mockGit) ApplyMailbox(_ context.Context, _ []byte) error { return nil }
+
+func (m *mockGit) CurrentPatch(_ context.Context) ([]byte, error) { return nil, nil }
+
+func (m *mockGit) ApplyWithRejects(_ context.Context, _ []byte) ([]git.Reject, error) {
+	return nil, nil
+}
+
+func (m *mockGit) ContinueApply(_ context.Context) error { return nil }
+
+func (m *mockGit) AbortApply(_ context.Context) error { return nil }
+
+func (m *mockGit) HasRemoteBranch(_ context.Context, remote, name string) bool {
+	if m.remoteBranchAt != nil {
+		return m.remoteBranchAt(remote, name)
+	}
+	return m.remoteBranches[name]
+}
+
+func (m *mockGit) CurrentBranch(_ context.Context) (string, error) {
+	if m.currentBranch != "" {
+		return m.currentBranch, nil
+	}
+	return "main", nil
+}
+
+func (m *mockGit) DeleteBranch(_ context.Context, name string) error {
+	m.deleted = append(m.deleted, name)
+	return nil
+}
+
+func (m *mockGit) DeleteRemoteBranch(_ context.Context, remote, name string) error {
+	if remote != "origin" {
+		name = remote + " " + name
+	}
+	m.remoteDeleted = append(m.remoteDeleted, name)
+	return nil
+}
+
+func (m *mockGit) IsInRepo(_ context.Context) bool { return true }
+
+func (m *mockGit) WriteBlobRef(_ context.Context, ref string, data []byte) error {
+	if m.refs == nil {
+		m.refs = make(map[string][]byte)
+	}
+	m.refs[ref] = data
+	return nil
+}
+
+func (m *mockGit) ReadBlobRefs(_ context.Context, prefix string) (map[string][]byte, error) {
+	refs := make(map[string][]byte)
+	for ref, data := range m.refs {
+		if strings.HasPrefix(ref, prefix) {
+			refs[ref] = data
+		}
+	}
+	return refs, nil
+}
 
 func (m *mockGit) CurrentBranchResult(_ context.Context) *git.BranchResult {
 	return &git.BranchResult{Branch: "main"}
@@ -85,6 +186,12 @@ func (m *mockGit) DeleteBranchResult(_ context.Context, _ string) *git.Operation
 type mockGitHub struct {
 	getPRFunc      func(string, string, int) (*github.PRInfo, error)
 	checkoutPRFunc func(int, string) error
+	findPRFunc     func(string, string, string) (*github.PRInfo, error)
+	listPRsFunc    func(string, string, github.ListOptions) ([]github.PRInfo, error)
+	createPRFunc   func(string, string, github.CreatePROptions) (*github.PRInfo, error)
+	getPRPatchFunc func(string, string, int) ([]byte, error)
+	getChecksFunc  func(string, string, string) (*github.Checks, error)
+	closed         []int
 }
 
 func (m *mockGitHub) GetPR(_ context.Context, owner, repo string, number int) (*github.PRInfo, error) {
@@ -110,13 +217,54 @@ func (m *mockGitHub) CheckoutPR(_ context.Context, _, _ string, number int, bran
 	return nil
 }
 
-func (m *mockGitHub) CreatePR(_ context.Context, _, _, _ string) error { return nil }
-func (m *mockGitHub) IsGHInstalled(_ context.Context) error            { return nil }
+func (m *mockGitHub) GetPRPatch(_ context.Context, owner, repo string, number int) ([]byte, error) {
+	if m.getPRPatchFunc != nil {
+		return m.getPRPatchFunc(owner, repo, number)
+	}
+	return nil, errors.New("no patch available")
+}
+
+func (m *mockGitHub) FindPRForBranch(_ context.Context, owner, repo, branch string) (*github.PRInfo, error) {
+	if m.findPRFunc != nil {
+		return m.findPRFunc(owner, repo, branch)
+	}
+	return nil, nil
+}
+
+func (m *mockGitHub) ListPRs(_ context.Context, owner, repo string, opts github.ListOptions) ([]github.PRInfo, error) {
+	if m.listPRsFunc != nil {
+		return m.listPRsFunc(owner, repo, opts)
+	}
+	return nil, nil
+}
+
+func (m *mockGitHub) CreatePR(_ context.Context, owner, repo string, opts github.CreatePROptions) (*github.PRInfo, error) {
+	if m.createPRFunc != nil {
+		return m.createPRFunc(owner, repo, opts)
+	}
+	return &github.PRInfo{Number: 500, URL: "https://github.com/testowner/testrepo/pull/500"}, nil
+}
+
+func (m *mockGitHub) ClosePR(_ context.Context, _, _ string, number int, _ string) error {
+	m.closed = append(m.closed, number)
+	return nil
+}
+
+func (m *mockGitHub) CommentPR(context.Context, string, string, int, string) error { return nil }
+
+func (m *mockGitHub) IsGHInstalled(_ context.Context) error { return nil }
+
+func (m *mockGitHub) GetChecks(_ context.Context, owner, repo, sha string) (*github.Checks, error) {
+	if m.getChecksFunc != nil {
+		return m.getChecksFunc(owner, repo, sha)
+	}
+	return &github.Checks{SHA: sha, State: github.CheckSuccess}, nil
+}
 
 func newTestClient(git git.Git, github github.GitHub) *Client {
 	return &Client{
 		git:     git,
-		github:  github,
+		forge:   github,
 		handler: func(Event) {},
 	}
 }
@@ -152,6 +300,97 @@ func TestParsePRRef(t *testing.T) {
 			wantRepo:   "repo",
 			wantNumber: 789,
 		},
+		{
+			name:       "hash number",
+			prRef:      "#123",
+			wantOwner:  "testowner",
+			wantRepo:   "testrepo",
+			wantNumber: 123,
+		},
+		{
+			name:       "gh-style path",
+			prRef:      "owner/repo/pull/321",
+			wantOwner:  "owner",
+			wantRepo:   "repo",
+			wantNumber: 321,
+		},
+		{
+			name:       "URL without scheme",
+			prRef:      "github.com/owner/repo/pull/654/files",
+			wantOwner:  "owner",
+			wantRepo:   "repo",
+			wantNumber: 654,
+		},
+		{
+			name:       "URL with trailing segments",
+			prRef:      "https://github.com/owner/repo/pull/789/commits/abc123",
+			wantOwner:  "owner",
+			wantRepo:   "repo",
+			wantNumber: 789,
+		},
+		{
+			name:       "URL with query and fragment",
+			prRef:      "https://github.com/owner/repo/pull/789/files?diff=split#diff-abc",
+			wantOwner:  "owner",
+			wantRepo:   "repo",
+			wantNumber: 789,
+		},
+		{
+			name:       "gh-style path with a query",
+			prRef:      "owner/repo/pull/123?w=1",
+			wantOwner:  "owner",
+			wantRepo:   "repo",
+			wantNumber: 123,
+		},
+		{
+			name:       "gh-style path with trailing segments, query and fragment",
+			prRef:      "owner/repo/pull/123/files?diff=split#diff-abc",
+			wantOwner:  "owner",
+			wantRepo:   "repo",
+			wantNumber: 123,
+		},
+		{
+			name:       "URL with a comment fragment",
+			prRef:      "https://github.com/owner/repo/pull/123#discussion_r1",
+			wantOwner:  "owner",
+			wantRepo:   "repo",
+			wantNumber: 123,
+		},
+		{
+			name:       "URL without scheme with a comment fragment",
+			prRef:      "github.com/owner/repo/pull/123#issuecomment-1",
+			wantOwner:  "owner",
+			wantRepo:   "repo",
+			wantNumber: 123,
+		},
+		{
+			name:       "gh-style path with a comment fragment",
+			prRef:      "owner/repo/pull/123#issuecomment-1",
+			wantOwner:  "owner",
+			wantRepo:   "repo",
+			wantNumber: 123,
+		},
+		{
+			name:       "GitLab merge request URL",
+			prRef:      "https://gitlab.com/group/sub/repo/-/merge_requests/12/diffs",
+			wantOwner:  "group/sub",
+			wantRepo:   "repo",
+			wantNumber: 12,
+		},
+		{
+			name:       "Gitea pull URL",
+			prRef:      "https://codeberg.org/owner/repo/pulls/34",
+			wantOwner:  "owner",
+			wantRepo:   "repo",
+			wantNumber: 34,
+		},
+		{
+			name:       "nested group#number",
+			prRef:      "group/sub/repo#56",
+			wantOwner:  "group/sub",
+			wantRepo:   "repo",
+			wantNumber: 56,
+		},
 		{
 			name:        "invalid number",
 			prRef:       "abc",
@@ -168,14 +407,20 @@ func TestParsePRRef(t *testing.T) {
 			name:        "invalid URL format",
 			prRef:       "https://github.com/owner/repo/issues/123",
 			wantErr:     true,
-			errContains: "invalid GitHub PR URL format",
+			errContains: "not a pull or merge request URL",
+		},
+		{
+			name:        "gh-style path without a number",
+			prRef:       "owner/repo/pull/files",
+			wantErr:     true,
+			errContains: "invalid PR number",
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			client := newTestClient(&mockGit{}, &mockGitHub{})
-			owner, repo, number, err := client.parsePRRef(tt.prRef)
+			owner, repo, number, err := client.parsePRRef(context.Background(), tt.prRef)
 
 			if (err != nil) != tt.wantErr {
 				t.Errorf("parsePRRef() error = %v, wantErr %v", err, tt.wantErr)
@@ -211,7 +456,7 @@ func TestParsePRRef_CurrentRepoError(t *testing.T) {
 
 	client := newTestClient(mockGit, &mockGitHub{})
 	err := func() error {
-		_, _, _, err := client.parsePRRef("123")
+		_, _, _, err := client.parsePRRef(context.Background(), "123")
 		return err
 	}()
 
@@ -435,14 +680,24 @@ func TestMigratePR_NotFork(t *testing.T) {
 	}
 
 	client := newTestClient(&mockGit{}, mockGitHub)
+	var events []Event
+	client.SetEventHandler(func(event Event) { events = append(events, event) })
 	err := client.MigratePR(ctx, "123", Options{})
 
 	if err == nil {
-		t.Error("MigratePR() should return error for non-fork PR")
+		t.Fatal("MigratePR() should return error for non-fork PR")
 	}
 	if !strings.Contains(err.Error(), "not from a fork") {
 		t.Errorf("MigratePR() error = %v, want error containing 'not from a fork'", err)
 	}
+	if !errors.Is(err, ErrPrecondition) {
+		t.Errorf("errors.Is(%v, ErrPrecondition) = false", err)
+	}
+	for _, event := range events {
+		if event.Type == EventError {
+			t.Errorf("emitted error event %q; the failure should only be reported through the returned error", event.Message)
+		}
+	}
 }
 
 func TestMigratePR_ClosedPR(t *testing.T) {
@@ -608,10 +863,13 @@ func TestMigratePRs_Success(t *testing.T) {
 	client := newTestClient(mockGit, mockGitHub)
 	client.SetEventHandler(handler)
 
-	err := client.MigratePRs(ctx, []string{"123", "124"}, Options{})
+	batch, err := client.MigratePRs(ctx, []string{"123", "124"}, Options{})
 	if err != nil {
 		t.Errorf("MigratePRs() error = %v", err)
 	}
+	if len(batch.Results) != 2 || batch.Results[0].Ref != "123" || batch.Results[1].Ref != "124" {
+		t.Errorf("MigratePRs() results = %+v, want one per PR in order", batch.Results)
+	}
 
 	hasSuccessMessage := false
 	for _, event := range events {
@@ -657,14 +915,17 @@ func TestMigratePRs_PartialFailure(t *testing.T) {
 	client := newTestClient(mockGit, mockGitHub)
 	client.SetEventHandler(handler)
 
-	err := client.MigratePRs(ctx, []string{"123", "124"}, Options{})
+	batch, err := client.MigratePRs(ctx, []string{"123", "124"}, Options{})
 
 	if err == nil {
-		t.Error("MigratePRs() should return error when some PRs fail")
+		t.Fatal("MigratePRs() should return error when some PRs fail")
 	}
 
-	if !strings.Contains(err.Error(), "failed to migrate some PRs") {
-		t.Errorf("MigratePRs() error = %v, want error containing 'failed to migrate some PRs'", err)
+	if err.Error() != "PR 124: PR not found" {
+		t.Errorf("MigratePRs() error = %v, want the failed PR's error", err)
+	}
+	if failed := batch.Failed(); len(failed) != 1 || failed[0].Ref != "124" {
+		t.Errorf("Failed() = %+v, want PR 124", failed)
 	}
 
 	hasPartialSuccess := false
@@ -742,7 +1003,7 @@ func TestPushAndEmit_Error(t *testing.T) {
 		}
 	})
 
-	err := client.pushAndEmit(ctx, "test-branch")
+	err := client.pushAndEmit(ctx, &target{remote: "origin"}, "test-branch")
 	if err == nil {
 		t.Error("pushAndEmit() should return error when push fails")
 	}
@@ -1010,3 +1271,301 @@ func TestMigratePR_NoCreateOption(t *testing.T) {
 		t.Errorf("MigratePR() should not return error with NoCreate option: %v", err)
 	}
 }
+
+func TestListOpenForkPRs(t *testing.T) {
+	client := newTestClient(&mockGit{}, &mockGitHub{
+		listPRsFunc: func(owner, repo string, opts github.ListOptions) ([]github.PRInfo, error) {
+			if owner != "testowner" || repo != "testrepo" {
+				t.Errorf("ListPRs() repo = %s/%s", owner, repo)
+			}
+			if opts.State != "open" || opts.Base != "release" {
+				t.Errorf("ListPRs() opts = %+v", opts)
+			}
+			return []github.PRInfo{
+				{Number: 1, IsFork: true},
+				{Number: 2, IsFork: false},
+				{Number: 3, IsFork: true},
+			}, nil
+		},
+	})
+
+	prs, err := client.ListOpenForkPRs(context.Background(), "release")
+	if err != nil {
+		t.Fatalf("ListOpenForkPRs() error = %v", err)
+	}
+	if len(prs) != 2 || prs[0].Number != 1 || prs[1].Number != 3 {
+		t.Errorf("ListOpenForkPRs() = %+v, want PRs 1 and 3", prs)
+	}
+}
+
+func TestListOpenForkPRs_CurrentRepoError(t *testing.T) {
+	client := newTestClient(&mockGit{
+		currentRepoFunc: func(_ context.Context) (string, string, error) {
+			return "", "", errors.New("no origin")
+		},
+	}, &mockGitHub{})
+
+	if _, err := client.ListOpenForkPRs(context.Background(), ""); err == nil {
+		t.Error("ListOpenForkPRs() should fail without an origin remote")
+	}
+}
+
+func forkPRGitHub() *mockGitHub {
+	return &mockGitHub{
+		getPRFunc: func(_, _ string, number int) (*github.PRInfo, error) {
+			return &github.PRInfo{
+				Number:     number,
+				Title:      "Test PR",
+				Author:     "testuser",
+				BaseBranch: "main",
+				State:      "open",
+				IsFork:     true,
+			}, nil
+		},
+	}
+}
+
+func TestMigratePR_Confirmations(t *testing.T) {
+	tests := []struct {
+		name        string
+		answers     map[string]bool
+		opts        Options
+		branchTaken bool
+		wantErr     bool
+		wantPush    bool
+		wantForce   bool
+		wantCreate  bool
+		wantClosed  bool
+	}{
+		{
+			name:    "decline push",
+			answers: map[string]bool{"Push": false},
+		},
+		{
+			name:       "accept push and create",
+			answers:    map[string]bool{"Push": true, "Create": true},
+			wantPush:   true,
+			wantCreate: true,
+		},
+		{
+			name:     "decline create",
+			answers:  map[string]bool{"Push": true, "Create": false},
+			wantPush: true,
+		},
+		{
+			name:       "close original",
+			answers:    map[string]bool{"Push": true, "Create": true, "Close": true},
+			opts:       Options{CloseOriginal: true},
+			wantPush:   true,
+			wantCreate: true,
+			wantClosed: true,
+		},
+		{
+			name:        "overwrite existing branch",
+			answers:     map[string]bool{"Branch": true, "Push": true, "Create": false},
+			opts:        Options{Force: true},
+			branchTaken: true,
+			wantForce:   true,
+		},
+		{
+			name:        "decline overwrite",
+			answers:     map[string]bool{"Branch": false},
+			opts:        Options{Force: true},
+			branchTaken: true,
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pushed := false
+			mockGit := &mockGit{
+				hasBranchFunc: func(_ context.Context, _ string) bool { return tt.branchTaken },
+				pushFunc: func(_ context.Context, _, _ string) error {
+					pushed = true
+					return nil
+				},
+			}
+			mockGitHub := forkPRGitHub()
+			created := false
+			mockGitHub.createPRFunc = func(_, _ string, opts github.CreatePROptions) (*github.PRInfo, error) {
+				created = true
+				if opts.Head != "migrated-123" || opts.Base != "main" {
+					t.Errorf("CreatePR() opts = %+v", opts)
+				}
+				return &github.PRInfo{Number: 500}, nil
+			}
+
+			client := newTestClient(mockGit, mockGitHub)
+			client.SetConfirmHandler(func(prompt string) bool {
+				word, _, _ := strings.Cut(prompt, " ")
+				answer, ok := tt.answers[word]
+				if !ok {
+					t.Errorf("unexpected prompt %q", prompt)
+				}
+				return answer
+			})
+
+			err := client.MigratePR(context.Background(), "123", tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("MigratePR() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				var exists *ErrBranchExists
+				if !errors.As(err, &exists) {
+					t.Errorf("MigratePR() error = %v, want ErrBranchExists", err)
+				}
+			}
+
+			if pushed != (tt.wantPush || tt.wantForce) {
+				t.Errorf("pushed = %v, want %v", pushed, tt.wantPush || tt.wantForce)
+			}
+			if got := len(mockGit.forcePushed) > 0; got != tt.wantForce {
+				t.Errorf("force pushed = %v, want %v", got, tt.wantForce)
+			}
+			if tt.wantForce && len(mockGit.deleted) != 1 {
+				t.Errorf("deleted = %v, want the existing branch deleted", mockGit.deleted)
+			}
+			if created != tt.wantCreate {
+				t.Errorf("created = %v, want %v", created, tt.wantCreate)
+			}
+			if got := len(mockGitHub.closed) > 0; got != tt.wantClosed {
+				t.Errorf("closed = %v, want %v", got, tt.wantClosed)
+			}
+		})
+	}
+}
+
+func TestMigratePR_NoConfirmHandler(t *testing.T) {
+	mockGit := &mockGit{}
+	mockGitHub := forkPRGitHub()
+	created := false
+	mockGitHub.createPRFunc = func(_, _ string, _ github.CreatePROptions) (*github.PRInfo, error) {
+		created = true
+		return &github.PRInfo{Number: 500}, nil
+	}
+
+	client := newTestClient(mockGit, mockGitHub)
+	if err := client.MigratePR(context.Background(), "123", Options{}); err != nil {
+		t.Fatalf("MigratePR() error = %v", err)
+	}
+	if created {
+		t.Error("MigratePR() created a PR without a confirm handler")
+	}
+}
+
+func TestTraceCommand(t *testing.T) {
+	result := runner.Result{
+		Cmd:      runner.Cmd{Name: "git", Args: []string{"push", "-u", "origin", "migrated-1"}},
+		Dir:      "/repo",
+		Stdout:   []byte("out"),
+		Stderr:   []byte("err"),
+		ExitCode: 1,
+	}
+
+	tests := []struct {
+		name       string
+		level      TraceLevel
+		wantEvent  bool
+		wantOutput bool
+	}{
+		{"off", TraceOff, false, false},
+		{"commands", TraceCommands, true, false},
+		{"output", TraceOutput, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var events []Event
+			client := &Client{trace: tt.level, handler: func(e Event) { events = append(events, e) }}
+			client.traceCommand(result)
+
+			if (len(events) == 1) != tt.wantEvent {
+				t.Fatalf("got %d events, wantEvent %v", len(events), tt.wantEvent)
+			}
+			if !tt.wantEvent {
+				return
+			}
+			event := events[0]
+			if event.Type != EventExec || event.Message != "git push -u origin migrated-1" {
+				t.Errorf("event = %+v", event)
+			}
+			if event.Exec.ExitCode != 1 || event.Exec.Dir != "/repo" {
+				t.Errorf("event.Exec = %+v", event.Exec)
+			}
+			if hasOutput := event.Exec.Stdout != nil || event.Exec.Stderr != nil; hasOutput != tt.wantOutput {
+				t.Errorf("event carries output = %v, want %v", hasOutput, tt.wantOutput)
+			}
+		})
+	}
+}
+
+func TestNewWithOptions_WithRunner(t *testing.T) {
+	fake := runner.NewFake(runner.Entry{Command: "git rev-parse --abbrev-ref HEAD", Stdout: "main\n"})
+	client := NewWithOptions(WithRunner(fake), WithTrace(TraceCommands)).(*Client)
+
+	var events []Event
+	client.SetEventHandler(func(e Event) { events = append(events, e) })
+
+	branch, err := client.git.CurrentBranch(context.Background())
+	if err != nil || branch != "main" {
+		t.Fatalf("CurrentBranch() = %q, %v; want main from the fake runner", branch, err)
+	}
+	if len(events) != 1 || events[0].Type != EventExec || events[0].Message != "git rev-parse --abbrev-ref HEAD" {
+		t.Errorf("events = %+v, want one traced command", events)
+	}
+}
+
+func TestNewWithOptions_WithGitAndGitHub(t *testing.T) {
+	mockGit, mockGitHub := &mockGit{}, forkPRGitHub()
+	client := NewWithOptions(WithGit(mockGit), WithGitHub(mockGitHub)).(*Client)
+
+	if client.git != mockGit || client.forge != mockGitHub {
+		t.Fatal("NewWithOptions() replaced the injected git and GitHub clients")
+	}
+	pr, err := client.GetPRInfo(context.Background(), "123")
+	if err != nil || pr.Title != "Test PR" {
+		t.Errorf("GetPRInfo() = %+v, %v; want the PR from the injected GitHub client", pr, err)
+	}
+}
+
+func TestNewWithOptions_WithDir(t *testing.T) {
+	fake := runner.NewFake(
+		runner.Entry{Command: "git remote get-url origin", Dir: "/clones/a", Stdout: "git@github.com:owner/a.git\n"},
+		runner.Entry{Command: "git remote get-url origin", Dir: "/clones/b", Stdout: "git@github.com:owner/b.git\n"},
+		runner.Entry{Command: "git config --get mfpr.forge", Dir: "/clones/b", ExitCode: 1},
+		runner.Entry{Command: "gh --version", Dir: "/clones/b"},
+	)
+
+	tests := []struct {
+		dir  string
+		want string
+	}{
+		{"/clones/a", "owner/a"},
+		{"/clones/b", "owner/b"},
+	}
+
+	for _, tt := range tests {
+		client := NewWithOptions(WithRunner(fake), WithDir(tt.dir)).(*Client)
+		owner, repo, _, err := client.parsePRRef(context.Background(), "42")
+		if err != nil {
+			t.Fatalf("parsePRRef() in %s error = %v", tt.dir, err)
+		}
+		if got := owner + "/" + repo; got != tt.want {
+			t.Errorf("parsePRRef() in %s detected %s, want %s", tt.dir, got, tt.want)
+		}
+	}
+
+	client := NewWithOptions(WithRunner(fake), WithDir("/clones/b")).(*Client)
+	f, err := client.forge.(*detectedForge).get(context.Background())
+	if err != nil {
+		t.Fatalf("detecting the forge in /clones/b: %v", err)
+	}
+	gh, ok := f.(github.GitHub)
+	if !ok {
+		t.Fatalf("detected %T, want the GitHub client", f)
+	}
+	if err := gh.IsGHInstalled(context.Background()); err != nil {
+		t.Errorf("IsGHInstalled() error = %v, want gh run in the same directory", err)
+	}
+}
@@ -0,0 +1,90 @@
+package migrate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ProvenanceRefPrefix is the ref namespace holding one migration record per
+// PR, at refs/mfpr/<owner>/<repo>/<number>. Records written before the owner
+// and repository were part of the name are at refs/mfpr/<number>.
+const ProvenanceRefPrefix = "refs/mfpr/"
+
+// Provenance records where a migrated branch came from.
+type Provenance struct {
+	Owner      string    `json:"owner"`
+	Repo       string    `json:"repo"`
+	Number     int       `json:"number"`
+	Branch     string    `json:"branch"`
+	HeadSHA    string    `json:"head_sha"`
+	Author     string    `json:"author"`
+	MigratedAt time.Time `json:"migrated_at"`
+	// Target is the owner/repo the branch was pushed to, when it is not
+	// Owner/Repo.
+	Target string `json:"target,omitempty"`
+	// Strategy is how the PR's commits were brought in.
+	Strategy Strategy `json:"strategy,omitempty"`
+	// RetargetedFrom is the base branch the PR targeted, when it was migrated
+	// onto a different one.
+	RetargetedFrom string `json:"retargeted_from,omitempty"`
+
+	// Ref is where the record was read from.
+	Ref string `json:"-"`
+}
+
+func provenanceRef(owner, repo string, number int) string {
+	return fmt.Sprintf("%s%s/%s/%d", ProvenanceRefPrefix, owner, repo, number)
+}
+
+func (c *Client) recordProvenance(ctx context.Context, owner, repo string, pr *PRInfo, t *target, m *migration) error {
+	record := Provenance{
+		Owner:          owner,
+		Repo:           repo,
+		Number:         pr.Number,
+		Branch:         m.branch,
+		Strategy:       m.strategy,
+		RetargetedFrom: m.fromBase,
+		HeadSHA:        pr.HeadRefOID,
+		Author:         pr.Author,
+		MigratedAt:     time.Now().UTC(),
+	}
+	if t.cross {
+		record.Target = t.String()
+	}
+
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return c.git.WriteBlobRef(ctx, m.record, append(data, '\n'))
+}
+
+func (c *Client) ListMigrations(ctx context.Context) ([]Provenance, error) {
+	refs, err := c.git.ReadBlobRefs(ctx, ProvenanceRefPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]Provenance, 0, len(refs))
+	for ref, data := range refs {
+		var record Provenance
+		if err := json.Unmarshal(data, &record); err != nil {
+			return nil, &ErrInvalidProvenance{Ref: ref, Detail: err.Error()}
+		}
+		record.Ref = ref
+		records = append(records, record)
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].Number != records[j].Number {
+			return records[i].Number < records[j].Number
+		}
+		return records[i].Ref < records[j].Ref
+	})
+
+	return records, nil
+}
@@ -0,0 +1,120 @@
+package migrate
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/user/git-mfpr/internal/github"
+)
+
+func TestMigratePR_RecordsProvenance(t *testing.T) {
+	ctx := context.Background()
+	mockGit := &mockGit{}
+	client := newTestClient(mockGit, &mockGitHub{
+		getPRFunc: func(_, _ string, _ int) (*github.PRInfo, error) {
+			return &github.PRInfo{
+				Number:     123,
+				Title:      "Test PR",
+				Author:     "testuser",
+				BaseBranch: "main",
+				State:      "OPEN",
+				HeadRefOID: "abc123def456",
+				IsFork:     true,
+			}, nil
+		},
+	})
+
+	if err := client.MigratePR(ctx, "123", Options{NoPush: true}); err != nil {
+		t.Fatalf("MigratePR() error = %v", err)
+	}
+
+	data, ok := mockGit.refs["refs/mfpr/testowner/testrepo/123"]
+	if !ok {
+		t.Fatal("expected migration record in refs/mfpr/testowner/testrepo/123")
+	}
+
+	var record Provenance
+	if err := json.Unmarshal(data, &record); err != nil {
+		t.Fatalf("invalid migration record: %v", err)
+	}
+	if record.Owner != "testowner" || record.Repo != "testrepo" {
+		t.Errorf("record repo = %s/%s, want testowner/testrepo", record.Owner, record.Repo)
+	}
+	if record.Number != 123 || record.Branch != "migrated-123" {
+		t.Errorf("record = #%d %s, want #123 migrated-123", record.Number, record.Branch)
+	}
+	if record.HeadSHA != "abc123def456" || record.Author != "testuser" {
+		t.Errorf("record head/author = %s/%s", record.HeadSHA, record.Author)
+	}
+	if record.MigratedAt.IsZero() {
+		t.Error("record timestamp not set")
+	}
+}
+
+func TestMigratePR_DryRunDoesNotRecordProvenance(t *testing.T) {
+	mockGit := &mockGit{}
+	client := newTestClient(mockGit, &mockGitHub{})
+
+	if err := client.MigratePR(context.Background(), "123", Options{DryRun: true}); err != nil {
+		t.Fatalf("MigratePR() error = %v", err)
+	}
+	if len(mockGit.refs) != 0 {
+		t.Errorf("dry run wrote %d migration records", len(mockGit.refs))
+	}
+}
+
+func TestMigratePR_ProvenancePerRepository(t *testing.T) {
+	mockGit := &mockGit{}
+	client := newTestClient(mockGit, forkPRGitHub())
+
+	for _, ref := range []string{"a/x#5", "b/y#5"} {
+		branch := "migrated-" + strings.NewReplacer("/", "-", "#", "-").Replace(ref)
+		if err := client.MigratePR(context.Background(), ref, Options{NoPush: true, BranchName: branch}); err != nil {
+			t.Fatalf("MigratePR(%s) error = %v", ref, err)
+		}
+	}
+
+	for _, ref := range []string{"refs/mfpr/a/x/5", "refs/mfpr/b/y/5"} {
+		if _, ok := mockGit.refs[ref]; !ok {
+			t.Errorf("missing migration record %s, have %v", ref, mockGit.refs)
+		}
+	}
+}
+
+func TestListMigrations(t *testing.T) {
+	mockGit := &mockGit{refs: map[string][]byte{
+		"refs/mfpr/o/r/200": []byte(`{"owner":"o","repo":"r","number":200,"branch":"migrated-200"}`),
+		"refs/mfpr/p/q/100": []byte(`{"owner":"p","repo":"q","number":100,"branch":"migrated-100-q"}`),
+		"refs/mfpr/100":     []byte(`{"owner":"o","repo":"r","number":100,"branch":"migrated-100"}`),
+		"refs/heads/x":      []byte(`not a record`),
+	}}
+	client := newTestClient(mockGit, &mockGitHub{})
+
+	records, err := client.ListMigrations(context.Background())
+	if err != nil {
+		t.Fatalf("ListMigrations() error = %v", err)
+	}
+	var got []string
+	for _, record := range records {
+		got = append(got, record.Ref)
+	}
+	want := []string{"refs/mfpr/100", "refs/mfpr/p/q/100", "refs/mfpr/o/r/200"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ListMigrations() = %v, want %v", got, want)
+	}
+}
+
+func TestListMigrations_InvalidRecord(t *testing.T) {
+	mockGit := &mockGit{refs: map[string][]byte{
+		"refs/mfpr/1": []byte(`{broken`),
+	}}
+	client := newTestClient(mockGit, &mockGitHub{})
+
+	_, err := client.ListMigrations(context.Background())
+	if _, ok := err.(*ErrInvalidProvenance); !ok {
+		t.Errorf("Expected ErrInvalidProvenance, got %T: %v", err, err)
+	}
+}
@@ -0,0 +1,89 @@
+package migrate
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// maxPRRange caps how many PRs a single N-M range may expand to.
+const maxPRRange = 500
+
+var prRange = regexp.MustCompile(`^(\d+)-(\d+)$`)
+
+// ExpandPRRefs expands comma-separated lists and N-M ranges, including
+// owner/repo#N-M and #N-M, into one reference per PR. URLs are passed through
+// untouched and duplicates are dropped.
+func ExpandPRRefs(refs []string) ([]string, error) {
+	var expanded []string
+	seen := map[string]bool{}
+	add := func(ref string) {
+		if !seen[ref] {
+			seen[ref] = true
+			expanded = append(expanded, ref)
+		}
+	}
+
+	for _, ref := range refs {
+		parts := []string{ref}
+		if !strings.Contains(ref, "://") {
+			parts = strings.Split(ref, ",")
+		}
+
+		for _, part := range parts {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+
+			prefix, spec := "", part
+			if i := strings.LastIndex(part, "#"); i >= 0 {
+				prefix, spec = part[:i+1], part[i+1:]
+			}
+			m := prRange.FindStringSubmatch(spec)
+			if m == nil {
+				add(part)
+				continue
+			}
+
+			first, _ := strconv.Atoi(m[1])
+			last, _ := strconv.Atoi(m[2])
+			if first > last {
+				return nil, &ErrInvalidPRRange{Ref: part, Detail: "the range runs backwards"}
+			}
+			if last-first >= maxPRRange {
+				return nil, &ErrInvalidPRRange{Ref: part, Detail: fmt.Sprintf("ranges are limited to %d PRs", maxPRRange)}
+			}
+			for n := first; n <= last; n++ {
+				add(prefix + strconv.Itoa(n))
+			}
+		}
+	}
+	return expanded, nil
+}
+
+// ReadPRRefs reads whitespace-separated PR references, such as a list of
+// numbers or URLs one per line. Lines starting with # are comments, unless
+// the # is followed by a PR number.
+func ReadPRRefs(r io.Reader) ([]string, error) {
+	var refs []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "#") && !startsWithPRNumber(line[1:]) {
+			continue
+		}
+		refs = append(refs, strings.Fields(line)...)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return refs, nil
+}
+
+func startsWithPRNumber(s string) bool {
+	return s != "" && s[0] >= '0' && s[0] <= '9'
+}
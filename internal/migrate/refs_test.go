@@ -0,0 +1,81 @@
+package migrate
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExpandPRRefs(t *testing.T) {
+	tests := []struct {
+		name    string
+		refs    []string
+		want    []string
+		wantErr string
+	}{
+		{
+			name: "plain references",
+			refs: []string{"123", "owner/repo#4", "https://github.com/o/r/pull/5/files"},
+			want: []string{"123", "owner/repo#4", "https://github.com/o/r/pull/5/files"},
+		},
+		{
+			name: "range",
+			refs: []string{"100-103"},
+			want: []string{"100", "101", "102", "103"},
+		},
+		{
+			name: "ranges with a repository or hash",
+			refs: []string{"owner/repo#7-8", "#9-10"},
+			want: []string{"owner/repo#7", "owner/repo#8", "#9", "#10"},
+		},
+		{
+			name: "lists and duplicates",
+			refs: []string{"1,3, 2-4", "3"},
+			want: []string{"1", "3", "2", "4"},
+		},
+		{
+			name: "URL with a comma",
+			refs: []string{"https://github.com/o/r/pull/5?a=1,2"},
+			want: []string{"https://github.com/o/r/pull/5?a=1,2"},
+		},
+		{name: "backwards range", refs: []string{"110-100"}, wantErr: "invalid PR range 110-100: the range runs backwards"},
+		{name: "huge range", refs: []string{"1-100000"}, wantErr: "ranges are limited to 500 PRs"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExpandPRRefs(tt.refs)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ExpandPRRefs() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ExpandPRRefs() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExpandPRRefs() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadPRRefs(t *testing.T) {
+	input := `# PRs to migrate before the release
+123 124
+#125
+
+https://github.com/owner/repo/pull/126/files
+  owner/repo#127-128
+`
+	want := []string{"123", "124", "#125", "https://github.com/owner/repo/pull/126/files", "owner/repo#127-128"}
+
+	got, err := ReadPRRefs(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ReadPRRefs() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadPRRefs() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,206 @@
+package migrate
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/user/git-mfpr/internal/git"
+)
+
+// PathRename moves every path that starts with Old to start with New instead,
+// like git filter-repo --path-rename OLD:NEW.
+type PathRename struct {
+	Old string
+	New string
+}
+
+// ParsePathMap reads path renames, one OLD:NEW per line. Blank lines and lines
+// starting with # are ignored.
+func ParsePathMap(r io.Reader) ([]PathRename, error) {
+	var renames []PathRename
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		oldPath, newPath, ok := strings.Cut(text, ":")
+		if !ok || oldPath == "" {
+			return nil, &ErrInvalidPathMap{Line: line, Text: text}
+		}
+		renames = append(renames, PathRename{Old: oldPath, New: newPath})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return renames, nil
+}
+
+// LoadPathMap reads path renames from a file in the format ParsePathMap
+// accepts.
+func LoadPathMap(path string) ([]PathRename, error) {
+	f, err := os.Open(path) // #nosec G304
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	renames, err := ParsePathMap(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return renames, nil
+}
+
+// renamePath applies the first rename whose Old prefix matches path.
+func renamePath(path string, renames []PathRename) string {
+	for _, rename := range renames {
+		if strings.HasPrefix(path, rename.Old) {
+			return rename.New + path[len(rename.Old):]
+		}
+	}
+	return path
+}
+
+var mboxSeparator = regexp.MustCompile(`(?m)^From [0-9a-f]{40} `)
+
+// remapPatch rewrites the file names in the diff headers of an mbox patch
+// series. Commit messages and hunk bodies are left alone.
+func remapPatch(mbox []byte, renames []PathRename) []byte {
+	var out bytes.Buffer
+	inDiff, inHunk := false, false
+	for _, line := range strings.SplitAfter(string(mbox), "\n") {
+		body := strings.TrimSuffix(line, "\n")
+		switch {
+		case mboxSeparator.MatchString(body):
+			inDiff, inHunk = false, false
+		case strings.HasPrefix(body, "diff --git "):
+			inDiff, inHunk = true, false
+			body = "diff --git " + remapGitDiffNames(body[len("diff --git "):], renames)
+		case !inDiff:
+		case strings.HasPrefix(body, "@@ "):
+			inHunk = true
+		case inHunk:
+		case strings.HasPrefix(body, "--- "), strings.HasPrefix(body, "+++ "):
+			body = body[:4] + remapDiffName(body[4:], renames)
+		default:
+			for _, prefix := range []string{"rename from ", "rename to ", "copy from ", "copy to "} {
+				if strings.HasPrefix(body, prefix) {
+					body = prefix + remapDiffName(body[len(prefix):], renames)
+					break
+				}
+			}
+		}
+
+		out.WriteString(body)
+		if strings.HasSuffix(line, "\n") {
+			out.WriteByte('\n')
+		}
+	}
+	return out.Bytes()
+}
+
+// remapGitDiffNames rewrites the "a/OLD b/NEW" part of a diff --git line.
+// Unquoted names may contain spaces, so the split prefers the middle of the
+// line, where it falls when both names are the same.
+func remapGitDiffNames(names string, renames []PathRename) string {
+	split := strings.Index(names, " b/")
+	if mid := len(names) / 2; mid+3 <= len(names) && names[mid:mid+3] == " b/" {
+		split = mid
+	}
+	if strings.HasPrefix(names, `"`) {
+		split = strings.Index(names, `" `) + 1
+	}
+	if split <= 0 {
+		return names
+	}
+	return remapDiffName(names[:split], renames) + " " + remapDiffName(names[split+1:], renames)
+}
+
+// remapDiffName rewrites one file name from a diff header, keeping its a/ or
+// b/ prefix, git's quoting and any trailing tab.
+func remapDiffName(name string, renames []PathRename) string {
+	suffix := ""
+	if strings.HasSuffix(name, "\t") {
+		name, suffix = strings.TrimSuffix(name, "\t"), "\t"
+	}
+	if name == "/dev/null" {
+		return name + suffix
+	}
+
+	quote := ""
+	if strings.HasPrefix(name, `"`) && strings.HasSuffix(name, `"`) && len(name) > 1 {
+		name, quote = name[1:len(name)-1], `"`
+	}
+	prefix := ""
+	if strings.HasPrefix(name, "a/") || strings.HasPrefix(name, "b/") {
+		name, prefix = name[2:], name[:2]
+	}
+	return quote + prefix + renamePath(name, renames) + quote + suffix
+}
+
+// remapBranch rewrites the paths in the commits on m.branch that are not on
+// the base branch, and replays them onto the base branch.
+func (c *Client) remapBranch(ctx context.Context, pr *PRInfo, m *migration, renames []PathRename) ([]git.Reject, error) {
+	c.emit(EventInfo, "Remapping paths in the PR's commits...", "")
+
+	mergeBase, err := c.git.MergeBase(ctx, pr.BaseBranch, m.branch)
+	if err != nil {
+		return nil, err
+	}
+	mbox, err := c.git.FormatPatch(ctx, mergeBase+".."+m.branch)
+	if err != nil {
+		return nil, err
+	}
+	if len(bytes.TrimSpace(mbox)) == 0 {
+		return nil, nil
+	}
+
+	if err := c.git.ResetBranch(ctx, m.branch, pr.BaseBranch); err != nil {
+		return nil, err
+	}
+	return c.applyPatches(ctx, m, remapPatch(mbox, renames))
+}
+
+// applyPatches applies an mbox patch series to the current branch. When a
+// patch does not apply cleanly, as much of it as possible is applied, the rest
+// is left in .rej files and returned, and the series carries on.
+func (c *Client) applyPatches(ctx context.Context, m *migration, mbox []byte) ([]git.Reject, error) {
+	m.applying = true
+	err := c.git.ApplyMailbox(ctx, mbox)
+
+	var rejects []git.Reject
+	for remaining := len(mboxSeparator.FindAll(mbox, -1)); err != nil; remaining-- {
+		if remaining <= 0 || ctx.Err() != nil {
+			return rejects, err
+		}
+		patch, patchErr := c.git.CurrentPatch(ctx)
+		if patchErr != nil {
+			return rejects, err
+		}
+
+		rejected, applyErr := c.git.ApplyWithRejects(ctx, patch)
+		if applyErr != nil {
+			return rejects, applyErr
+		}
+		rejects = append(rejects, rejected...)
+		err = c.git.ContinueApply(ctx)
+	}
+
+	m.applying = false
+	return rejects, nil
+}
+
+func (c *Client) reportRejects(branch string, rejects []git.Reject) {
+	c.emit(EventInfo, fmt.Sprintf("Hunks that could not be applied to %s:", branch),
+		"Apply them by hand from the .rej files next to each file, then push the branch")
+	for _, reject := range rejects {
+		c.emit(EventError, reject.String(), "")
+	}
+}
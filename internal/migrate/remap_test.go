@@ -0,0 +1,322 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/user/git-mfpr/internal/git"
+)
+
+func TestParsePathMap(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []PathRename
+		wantErr string
+	}{
+		{
+			name:  "renames with comments",
+			input: "# moved in v2\npkg/:internal/\n\ncmd/tool/main.go:cmd/main.go\n",
+			want:  []PathRename{{Old: "pkg/", New: "internal/"}, {Old: "cmd/tool/main.go", New: "cmd/main.go"}},
+		},
+		{
+			name:  "move to root",
+			input: "src/:",
+			want:  []PathRename{{Old: "src/", New: ""}},
+		},
+		{name: "missing separator", input: "pkg/\n", wantErr: `line 1: expected OLD:NEW, got "pkg/"`},
+		{name: "empty old path", input: "# x\n:internal/", wantErr: "line 2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePathMap(strings.NewReader(tt.input))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParsePathMap() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParsePathMap() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParsePathMap() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRemapPatch(t *testing.T) {
+	renames := []PathRename{{Old: "pkg/", New: "internal/"}}
+	mbox := `From 0123456789abcdef0123456789abcdef01234567 Mon Sep 17 00:00:00 2001
+From: Dev <dev@example.com>
+Subject: [PATCH] Move things
+
+Mentions pkg/util.go, which should stay as written.
+--- a/pkg/in-message
+---
+ pkg/util.go | 2 +-
+diff --git a/pkg/util.go b/pkg/util.go
+index 1111111..2222222 100644
+--- a/pkg/util.go
++++ b/pkg/util.go
+@@ -1,2 +1,2 @@
+--- a/pkg/in-hunk
++changed
+diff --git a/pkg/old name.go b/pkg/old name.go
+new file mode 100644
+--- /dev/null
++++ b/pkg/old name.go
+@@ -0,0 +1 @@
++x
+diff --git a/pkg/a.go b/docs/a.go
+rename from pkg/a.go
+rename to docs/a.go
+diff --git "a/pkg/caf\303\251.go" "b/pkg/caf\303\251.go"
+--- "a/pkg/caf\303\251.go"
++++ "b/pkg/caf\303\251.go"
+--
+2.39.5
+`
+	want := `From 0123456789abcdef0123456789abcdef01234567 Mon Sep 17 00:00:00 2001
+From: Dev <dev@example.com>
+Subject: [PATCH] Move things
+
+Mentions pkg/util.go, which should stay as written.
+--- a/pkg/in-message
+---
+ pkg/util.go | 2 +-
+diff --git a/internal/util.go b/internal/util.go
+index 1111111..2222222 100644
+--- a/internal/util.go
++++ b/internal/util.go
+@@ -1,2 +1,2 @@
+--- a/pkg/in-hunk
++changed
+diff --git a/internal/old name.go b/internal/old name.go
+new file mode 100644
+--- /dev/null
++++ b/internal/old name.go
+@@ -0,0 +1 @@
++x
+diff --git a/internal/a.go b/docs/a.go
+rename from internal/a.go
+rename to docs/a.go
+diff --git "a/internal/caf\303\251.go" "b/internal/caf\303\251.go"
+--- "a/internal/caf\303\251.go"
++++ "b/internal/caf\303\251.go"
+--
+2.39.5
+`
+	if got := string(remapPatch([]byte(mbox), renames)); got != want {
+		t.Errorf("remapPatch() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+// patchGit scripts the git am steps of a migration on top of mockGit.
+type patchGit struct {
+	*mockGit
+	mailboxErr error
+	patches    [][]git.Reject
+	continued  int
+	aborted    bool
+	mailbox    []byte
+	resets     []string
+}
+
+func (p *patchGit) ResetBranch(_ context.Context, branch, startPoint string) error {
+	p.resets = append(p.resets, branch+" "+startPoint)
+	return nil
+}
+
+func (p *patchGit) FormatPatch(_ context.Context, _ string) ([]byte, error) {
+	return []byte("From 0123456789abcdef0123456789abcdef01234567 Mon Sep 17 00:00:00 2001\n" +
+		"From 0123456789abcdef0123456789abcdef01234568 Mon Sep 17 00:00:00 2001\n"), nil
+}
+
+func (p *patchGit) ApplyMailbox(_ context.Context, mbox []byte) error {
+	p.mailbox = mbox
+	return p.mailboxErr
+}
+
+func (p *patchGit) CurrentPatch(_ context.Context) ([]byte, error) {
+	if len(p.patches) == 0 {
+		return nil, errors.New("no am in progress")
+	}
+	return []byte("diff"), nil
+}
+
+func (p *patchGit) ApplyWithRejects(_ context.Context, _ []byte) ([]git.Reject, error) {
+	rejects := p.patches[0]
+	p.patches = p.patches[1:]
+	return rejects, nil
+}
+
+func (p *patchGit) ContinueApply(_ context.Context) error {
+	p.continued++
+	if len(p.patches) > 0 {
+		return errors.New("next patch failed")
+	}
+	return nil
+}
+
+func (p *patchGit) AbortApply(_ context.Context) error {
+	p.aborted = true
+	return nil
+}
+
+func TestMigratePR_PathRenames(t *testing.T) {
+	tests := []struct {
+		name       string
+		mailboxErr error
+		patches    [][]git.Reject
+		wantPush   bool
+		wantErr    error
+		wantEvents []string
+	}{
+		{
+			name:       "applies cleanly",
+			wantPush:   true,
+			wantEvents: []string{"Remapping paths in the PR's commits...", "Successfully migrated PR #123"},
+		},
+		{
+			name:       "rejected hunks",
+			mailboxErr: errors.New("patch failed"),
+			patches: [][]git.Reject{
+				{{File: "internal/a.go", Hunk: 2, Header: "@@ -9,3 +9,3 @@"}},
+				{{File: "internal/b.go"}},
+			},
+			wantErr: &ErrHunksRejected{Branch: "migrated-123", Count: 2},
+			wantEvents: []string{
+				"Hunks that could not be applied to migrated-123:",
+				"internal/a.go: hunk #2 @@ -9,3 +9,3 @@",
+				"internal/b.go: whole file",
+				"Not pushing migrated-123 until the rejected hunks are applied",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pushed := false
+			mock := &patchGit{
+				mockGit: &mockGit{pushFunc: func(context.Context, string, string) error {
+					pushed = true
+					return nil
+				}},
+				mailboxErr: tt.mailboxErr,
+				patches:    tt.patches,
+			}
+			client := newTestClient(mock, forkPRGitHub())
+			client.SetConfirmHandler(func(string) bool { return true })
+			var events []Event
+			client.SetEventHandler(func(e Event) { events = append(events, e) })
+
+			opts := Options{NoCreate: true, PathRenames: []PathRename{{Old: "pkg/", New: "internal/"}}}
+			err := client.MigratePR(context.Background(), "123", opts)
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Fatalf("MigratePR() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrGit) {
+				t.Errorf("MigratePR() error = %v, want a git error", err)
+			}
+			if err != nil && len(mock.deleted) != 0 {
+				t.Errorf("deleted %v, want the branch kept for finishing by hand", mock.deleted)
+			}
+
+			if pushed != tt.wantPush {
+				t.Errorf("pushed = %v, want %v", pushed, tt.wantPush)
+			}
+			messages := eventMessages(events)
+			for _, want := range tt.wantEvents {
+				if !strings.Contains(messages, want) {
+					t.Errorf("events missing %q:\n%s", want, messages)
+				}
+			}
+		})
+	}
+}
+
+func TestMigratePR_PathRenamesRollback(t *testing.T) {
+	mock := &patchGit{mockGit: &mockGit{}, mailboxErr: errors.New("corrupt patch")}
+	client := newTestClient(mock, forkPRGitHub())
+
+	opts := Options{PathRenames: []PathRename{{Old: "pkg/", New: "internal/"}}}
+	if err := client.MigratePR(context.Background(), "123", opts); err == nil {
+		t.Fatal("MigratePR() expected an error when git am cannot continue")
+	}
+	if !mock.aborted {
+		t.Error("rollback should abort the git am in progress")
+	}
+}
+
+func TestRemapBranch_RealRepository(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	defer os.Chdir(oldWd)
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+	for _, key := range []string{"GIT_AUTHOR_NAME", "GIT_COMMITTER_NAME"} {
+		t.Setenv(key, "Test")
+	}
+	for _, key := range []string{"GIT_AUTHOR_EMAIL", "GIT_COMMITTER_EMAIL"} {
+		t.Setenv(key, "test@example.com")
+	}
+
+	run := func(args ...string) {
+		t.Helper()
+		if output, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, output)
+		}
+	}
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.MkdirAll(name[:strings.LastIndex(name, "/")], 0o750); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0o600); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	run("init", "-b", "main")
+	write("pkg/util/util.go", "package util\n\nfunc A() {}\n")
+	run("add", ".")
+	run("commit", "-m", "init")
+
+	run("checkout", "-b", "migrated-1")
+	write("pkg/util/util.go", "package util\n\nfunc A() {}\n\nfunc B() {}\n")
+	run("commit", "-am", "Add B")
+
+	run("checkout", "main")
+	run("mv", "pkg", "internal")
+	run("commit", "-m", "Move pkg to internal")
+	run("checkout", "migrated-1")
+
+	client := newTestClient(git.New(), forkPRGitHub())
+	m := &migration{branch: "migrated-1"}
+	rejects, err := client.remapBranch(context.Background(), &PRInfo{BaseBranch: "main"}, m,
+		[]PathRename{{Old: "pkg/", New: "internal/"}})
+	if err != nil {
+		t.Fatalf("remapBranch() error = %v", err)
+	}
+	if len(rejects) != 0 {
+		t.Errorf("remapBranch() rejects = %+v, want none", rejects)
+	}
+
+	content, err := os.ReadFile("internal/util/util.go")
+	if err != nil || !strings.Contains(string(content), "func B()") {
+		t.Errorf("internal/util/util.go = %q, %v; want the PR's change at the new path", content, err)
+	}
+	output, _ := exec.Command("git", "log", "--format=%an %s", "-2").Output()
+	if got := string(output); got != "Test Add B\nTest Move pkg to internal\n" {
+		t.Errorf("history = %q, want the PR commit replayed on main", got)
+	}
+}
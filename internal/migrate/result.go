@@ -0,0 +1,194 @@
+package migrate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Status summarises how a migration ended.
+type Status string
+
+const (
+	StatusMigrated Status = "migrated"
+	// StatusLocal is a branch that was created but not pushed.
+	StatusLocal Status = "local only"
+	// StatusConflicts is a branch left with hunks to apply by hand.
+	StatusConflicts Status = "conflicts"
+	StatusDryRun    Status = "dry run"
+	StatusFailed    Status = "failed"
+)
+
+// MigrationResult describes how one PR's migration went. It is reported
+// through an EventResult when the migration finishes.
+type MigrationResult struct {
+	Ref string
+	// PR is nil if the PR could not be fetched.
+	PR     *PRInfo
+	Branch string
+	// Created is the replacement PR, if one was created.
+	Created  *PRInfo
+	Commits  int
+	Strategy Strategy
+	Rejects  int
+	Pushed   bool
+	// SHA is the commit the branch was pushed at.
+	SHA      string
+	DryRun   bool
+	Err      error
+	Started  time.Time
+	Duration time.Duration
+}
+
+func (r *MigrationResult) Status() Status {
+	var rejected *ErrHunksRejected
+	switch {
+	case errors.As(r.Err, &rejected):
+		return StatusConflicts
+	case r.Err != nil:
+		return StatusFailed
+	case r.DryRun:
+		return StatusDryRun
+	case r.Rejects > 0:
+		return StatusConflicts
+	case !r.Pushed:
+		return StatusLocal
+	default:
+		return StatusMigrated
+	}
+}
+
+// ErrorClass names the kind of error the migration failed with, such as
+// ErrBranchExists, or "" if it did not fail.
+func (r *MigrationResult) ErrorClass() string {
+	if r.Err == nil {
+		return ""
+	}
+	switch {
+	case errors.Is(r.Err, context.Canceled):
+		return "interrupted"
+	case errors.Is(r.Err, context.DeadlineExceeded):
+		return "timeout"
+	}
+	for err := r.Err; err != nil; err = errors.Unwrap(err) {
+		name := fmt.Sprintf("%T", err)
+		name = name[strings.LastIndex(name, ".")+1:]
+		if strings.HasPrefix(name, "Err") {
+			return name
+		}
+	}
+	return "error"
+}
+
+// URL links to the original PR, falling back to the reference it was given
+// as when the PR could not be fetched.
+func (r *MigrationResult) URL() string {
+	if r.PR != nil && r.PR.URL != "" {
+		return r.PR.URL
+	}
+	return r.Ref
+}
+
+// BatchResult describes how each PR in a MigratePRs call went, in the order
+// they were given.
+type BatchResult struct {
+	Results  []MigrationResult
+	Started  time.Time
+	Duration time.Duration
+}
+
+// Failed returns the results of the migrations that failed, including any
+// skipped after an interrupt.
+func (b *BatchResult) Failed() []MigrationResult {
+	var failed []MigrationResult
+	for _, r := range b.Results {
+		if r.Err != nil {
+			failed = append(failed, r)
+		}
+	}
+	return failed
+}
+
+// Err joins the error of every failed migration, each prefixed with its PR
+// reference, or returns nil if none failed. errors.Is and errors.As see
+// through it to the individual errors.
+func (b *BatchResult) Err() error {
+	var errs []error
+	for _, r := range b.Failed() {
+		errs = append(errs, fmt.Errorf("PR %s: %w", r.Ref, r.Err))
+	}
+	return errors.Join(errs...)
+}
+
+// resultJSON is the JSON form of a MigrationResult. Its field names are
+// stable, for scripts and the GitHub Action to rely on.
+type resultJSON struct {
+	Ref        string    `json:"ref"`
+	URL        string    `json:"url"`
+	Number     int       `json:"number,omitempty"`
+	Title      string    `json:"title,omitempty"`
+	Author     string    `json:"author,omitempty"`
+	Status     Status    `json:"status"`
+	Branch     string    `json:"branch,omitempty"`
+	SHA        string    `json:"sha,omitempty"`
+	Pushed     bool      `json:"pushed"`
+	NewPR      int       `json:"new_pr,omitempty"`
+	NewPRURL   string    `json:"new_pr_url,omitempty"`
+	Commits    int       `json:"commits"`
+	Strategy   Strategy  `json:"strategy,omitempty"`
+	Rejects    int       `json:"rejects,omitempty"`
+	DryRun     bool      `json:"dry_run,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	ErrorClass string    `json:"error_class,omitempty"`
+	Started    time.Time `json:"started"`
+	DurationMS int64     `json:"duration_ms"`
+}
+
+// MarshalJSON encodes the result with its status, error message and error
+// class, which the struct alone does not carry.
+func (r MigrationResult) MarshalJSON() ([]byte, error) {
+	out := resultJSON{
+		Ref:        r.Ref,
+		URL:        r.URL(),
+		Status:     r.Status(),
+		Branch:     r.Branch,
+		SHA:        r.SHA,
+		Pushed:     r.Pushed,
+		Commits:    r.Commits,
+		Strategy:   r.Strategy,
+		Rejects:    r.Rejects,
+		DryRun:     r.DryRun,
+		ErrorClass: r.ErrorClass(),
+		Started:    r.Started,
+		DurationMS: r.Duration.Milliseconds(),
+	}
+	if r.PR != nil {
+		out.Number, out.Title, out.Author = r.PR.Number, r.PR.Title, r.PR.Author
+	}
+	if r.Created != nil {
+		out.NewPR, out.NewPRURL = r.Created.Number, r.Created.URL
+	}
+	if r.Err != nil {
+		out.Error = r.Err.Error()
+	}
+	return json.Marshal(out)
+}
+
+// MarshalJSON encodes the batch with counts of migrated and failed PRs.
+func (b BatchResult) MarshalJSON() ([]byte, error) {
+	results := b.Results
+	if results == nil {
+		results = []MigrationResult{}
+	}
+	failed := len(b.Failed())
+	return json.Marshal(struct {
+		Results    []MigrationResult `json:"results"`
+		Migrated   int               `json:"migrated"`
+		Failed     int               `json:"failed"`
+		Started    time.Time         `json:"started"`
+		DurationMS int64             `json:"duration_ms"`
+	}{results, len(results) - failed, failed, b.Started, b.Duration.Milliseconds()})
+}
@@ -0,0 +1,172 @@
+package migrate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/user/git-mfpr/internal/github"
+)
+
+func TestMigrationResult_Status(t *testing.T) {
+	tests := []struct {
+		name   string
+		result MigrationResult
+		want   Status
+	}{
+		{name: "pushed", result: MigrationResult{Pushed: true}, want: StatusMigrated},
+		{name: "not pushed", result: MigrationResult{}, want: StatusLocal},
+		{name: "rejected hunks", result: MigrationResult{Rejects: 2}, want: StatusConflicts},
+		{name: "left with rejected hunks", result: MigrationResult{Rejects: 2, Err: fmt.Errorf("PR 1: %w", &ErrHunksRejected{Branch: "b", Count: 2})}, want: StatusConflicts},
+		{name: "dry run", result: MigrationResult{DryRun: true}, want: StatusDryRun},
+		{name: "failed after push", result: MigrationResult{Pushed: true, Err: errors.New("boom")}, want: StatusFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.result.Status(); got != tt.want {
+				t.Errorf("Status() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMigrationResult_ErrorClass(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{name: "no error"},
+		{name: "typed error", err: &ErrBranchExists{BranchName: "b"}, want: "ErrBranchExists"},
+		{name: "wrapped typed error", err: fmt.Errorf("context: %w", &ErrChecksFailed{Branch: "b"}), want: "ErrChecksFailed"},
+		{name: "interrupted", err: fmt.Errorf("fetching: %w", context.Canceled), want: "interrupted"},
+		{name: "timed out", err: context.DeadlineExceeded, want: "timeout"},
+		{name: "plain error", err: errors.New("boom"), want: "error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := MigrationResult{Err: tt.err}
+			if got := r.ErrorClass(); got != tt.want {
+				t.Errorf("ErrorClass() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMigratePR_Result(t *testing.T) {
+	client, _, _, _ := newRollbackTestClient(nil)
+	client.forge.(*mockGitHub).createPRFunc = func(string, string, github.CreatePROptions) (*github.PRInfo, error) {
+		return &github.PRInfo{Number: 456, URL: "https://github.com/testowner/testrepo/pull/456"}, nil
+	}
+	client.SetConfirmHandler(func(string) bool { return true })
+	var results []*MigrationResult
+	client.SetEventHandler(func(event Event) {
+		if event.Type == EventResult {
+			results = append(results, event.Result)
+		}
+	})
+
+	if err := client.MigratePR(context.Background(), "123", Options{}); err != nil {
+		t.Fatalf("MigratePR() error = %v", err)
+	}
+	err := client.MigratePR(context.Background(), "123", Options{})
+
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want one per MigratePR call", len(results))
+	}
+	got := results[0]
+	if got.Ref != "123" || got.PR == nil || got.PR.Number != 123 || got.Branch != "migrated-123" {
+		t.Errorf("result = %+v, want PR #123 on migrated-123", got)
+	}
+	if got.Created == nil || got.Created.Number != 456 || got.Commits != 2 || got.Strategy != StrategyCheckout || !got.Pushed {
+		t.Errorf("result = %+v, want 2 commits checked out, pushed and replaced by #456", got)
+	}
+	if got.SHA != "0123456789abcdef0123456789abcdef01234567" {
+		t.Errorf("SHA = %q, want the pushed commit", got.SHA)
+	}
+	if got.Status() != StatusMigrated || got.Duration <= 0 {
+		t.Errorf("Status() = %s, Duration = %s, want a timed migration", got.Status(), got.Duration)
+	}
+
+	if failed := results[1]; failed.Err != err || failed.Status() != StatusFailed || failed.ErrorClass() != "ErrBranchExists" {
+		t.Errorf("second result = %+v, want the ErrBranchExists MigratePR returned", failed)
+	}
+}
+
+func TestBatchResult_Err(t *testing.T) {
+	notFound := &ErrPRNotFound{Number: 2}
+	batch := &BatchResult{Results: []MigrationResult{
+		{Ref: "1"},
+		{Ref: "2", Err: notFound},
+		{Ref: "3", Err: context.Canceled},
+	}}
+
+	err := batch.Err()
+	if err == nil || err.Error() != "PR 2: "+notFound.Error()+"\nPR 3: context canceled" {
+		t.Fatalf("Err() = %v, want each failure on its own line", err)
+	}
+	var target *ErrPRNotFound
+	if !errors.As(err, &target) || target != notFound {
+		t.Errorf("errors.As(Err()) did not find the ErrPRNotFound")
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("errors.Is(Err(), context.Canceled) = false")
+	}
+
+	if err := (&BatchResult{Results: []MigrationResult{{Ref: "1"}}}).Err(); err != nil {
+		t.Errorf("Err() = %v, want nil when nothing failed", err)
+	}
+}
+
+func TestBatchResult_MarshalJSON(t *testing.T) {
+	started := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	batch := &BatchResult{
+		Results: []MigrationResult{
+			{
+				Ref:      "acme/widgets#42",
+				PR:       &PRInfo{Number: 42, Title: "Add retry", Author: "alice", URL: "https://github.com/acme/widgets/pull/42"},
+				Branch:   "migrated-42-add-retry",
+				Created:  &PRInfo{Number: 57, URL: "https://github.com/acme/widgets/pull/57"},
+				Commits:  2,
+				Strategy: StrategyCheckout,
+				Pushed:   true,
+				SHA:      "abc123",
+				Started:  started,
+				Duration: 1500 * time.Millisecond,
+			},
+			{Ref: "43", Err: &ErrPRNotFound{Number: 43, Owner: "acme", Repo: "widgets"}, Started: started},
+		},
+		Started:  started,
+		Duration: 2 * time.Second,
+	}
+
+	got, err := json.Marshal(batch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"results":[` +
+		`{"ref":"acme/widgets#42","url":"https://github.com/acme/widgets/pull/42","number":42,"title":"Add retry","author":"alice",` +
+		`"status":"migrated","branch":"migrated-42-add-retry","sha":"abc123","pushed":true,"new_pr":57,` +
+		`"new_pr_url":"https://github.com/acme/widgets/pull/57","commits":2,"strategy":"checkout",` +
+		`"started":"2024-05-01T12:00:00Z","duration_ms":1500},` +
+		`{"ref":"43","url":"43","status":"failed","pushed":false,"commits":0,"error":"PR #43 not found in acme/widgets",` +
+		`"error_class":"ErrPRNotFound","started":"2024-05-01T12:00:00Z","duration_ms":0}],` +
+		`"migrated":1,"failed":1,"started":"2024-05-01T12:00:00Z","duration_ms":2000}`
+	if string(got) != want {
+		t.Errorf("json.Marshal() =\n%s\nwant\n%s", got, want)
+	}
+
+	got, err = json.Marshal(&BatchResult{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(got), `"results":[]`) {
+		t.Errorf("json.Marshal() = %s, want an empty results array", got)
+	}
+}
@@ -0,0 +1,95 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/user/git-mfpr/internal/git"
+)
+
+// rollbackTimeout bounds the clean-up after a failed or interrupted migration.
+const rollbackTimeout = 30 * time.Second
+
+// migration tracks what MigratePR has changed so far, so that a failed or
+// interrupted run can be undone.
+type migration struct {
+	originalBranch string
+	base           string
+	fromBase       string
+	branch         string
+	record         string
+	pushedTo       string
+	applying       bool
+	strategy       Strategy
+	rejects        []git.Reject
+	created        *PRInfo
+	commits        int
+	sha            string
+	createdBranch  bool
+	recorded       bool
+	pushed         bool
+}
+
+// rollback returns the repository to where it was before the migration
+// started and reports the state it was left in. It runs on a context detached
+// from ctx so that Ctrl-C still gets a tidy repository.
+func (c *Client) rollback(ctx context.Context, m *migration, cause error) {
+	if ctx.Err() != nil {
+		c.emit(EventError, "Interrupted, rolling back...", cause.Error())
+	} else {
+		c.emit(EventInfo, "Rolling back...", "")
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), rollbackTimeout)
+	defer cancel()
+
+	if m.applying {
+		if err := c.git.AbortApply(ctx); err != nil {
+			c.emit(EventError, "Could not abort applying patches", err.Error())
+		}
+	}
+
+	removeBranch := m.createdBranch && !m.pushed && c.git.HasBranch(ctx, m.branch)
+
+	restore := m.originalBranch
+	if restore == m.branch && (removeBranch || !c.git.HasBranch(ctx, m.branch)) {
+		restore = m.base
+	}
+	if restore != "" {
+		if err := c.git.Checkout(ctx, restore); err != nil {
+			c.emit(EventError, fmt.Sprintf("Could not switch back to %s", restore), err.Error())
+			removeBranch = false
+		}
+	}
+
+	if removeBranch {
+		if err := c.git.DeleteBranch(ctx, m.branch); err != nil {
+			c.emit(EventError, fmt.Sprintf("Could not delete partially migrated branch %s", m.branch), err.Error())
+		} else {
+			c.emit(EventSuccess, fmt.Sprintf("Deleted partially migrated branch %s", m.branch), "")
+			if m.recorded {
+				if err := c.git.DeleteRef(ctx, m.record); err != nil {
+					c.emit(EventError, fmt.Sprintf("Could not remove %s", m.record), err.Error())
+				}
+			}
+		}
+	}
+
+	c.reportState(ctx, m)
+}
+
+func (c *Client) reportState(ctx context.Context, m *migration) {
+	if current, err := c.git.CurrentBranch(ctx); err == nil {
+		c.emit(EventInfo, fmt.Sprintf("Repository left on branch %s", current), "")
+	}
+
+	switch {
+	case m.pushed:
+		c.emit(EventInfo, fmt.Sprintf("Branch %s was pushed to %s and has been kept", m.branch, m.pushedTo), "")
+	case c.git.HasBranch(ctx, m.branch):
+		c.emit(EventInfo, fmt.Sprintf("Branch %s exists locally and was not pushed", m.branch), "")
+	default:
+		c.emit(EventInfo, fmt.Sprintf("Branch %s does not exist", m.branch), "")
+	}
+}
@@ -0,0 +1,166 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/user/git-mfpr/internal/github"
+)
+
+// newRollbackTestClient returns a client whose git mock tracks which branches
+// exist and which branches were checked out.
+func newRollbackTestClient(checkoutPR func(int, string) error) (*Client, *mockGit, *[]string, map[string]bool) {
+	branches := map[string]bool{"main": true}
+	var checkouts []string
+
+	mockGit := &mockGit{refs: map[string][]byte{}}
+	mockGit.hasBranchFunc = func(_ context.Context, name string) bool {
+		for _, deleted := range mockGit.deleted {
+			if deleted == name {
+				return false
+			}
+		}
+		return branches[name]
+	}
+	mockGit.checkoutFunc = func(_ context.Context, branch string) error {
+		checkouts = append(checkouts, branch)
+		return nil
+	}
+	mockGitHub := forkPRGitHub()
+	mockGitHub.checkoutPRFunc = func(number int, branch string) error {
+		branches[branch] = true
+		if checkoutPR != nil {
+			return checkoutPR(number, branch)
+		}
+		return nil
+	}
+	return newTestClient(mockGit, mockGitHub), mockGit, &checkouts, branches
+}
+
+func eventMessages(events []Event) string {
+	var messages []string
+	for _, event := range events {
+		messages = append(messages, event.Message)
+	}
+	return strings.Join(messages, "\n")
+}
+
+func TestMigratePR_RollbackOnPushFailure(t *testing.T) {
+	client, mockGit, checkouts, _ := newRollbackTestClient(nil)
+	mockGit.pushFunc = func(_ context.Context, _, _ string) error {
+		return errors.New("push rejected")
+	}
+	var events []Event
+	client.SetEventHandler(func(event Event) { events = append(events, event) })
+
+	err := client.MigratePR(context.Background(), "123", Options{NoCreate: true})
+	if err == nil {
+		t.Fatal("MigratePR() expected push error")
+	}
+
+	if got := strings.Join(*checkouts, ","); got != "main,main" {
+		t.Errorf("checkouts = %q, want base then original branch", got)
+	}
+	if len(mockGit.deleted) != 1 || mockGit.deleted[0] != "migrated-123" {
+		t.Errorf("deleted = %v, want [migrated-123]", mockGit.deleted)
+	}
+	if _, ok := mockGit.refs["refs/mfpr/testowner/testrepo/123"]; ok {
+		t.Error("provenance ref was not removed on rollback")
+	}
+
+	messages := eventMessages(events)
+	for _, want := range []string{
+		"Rolling back...",
+		"Deleted partially migrated branch migrated-123",
+		"Repository left on branch main",
+		"Branch migrated-123 does not exist",
+	} {
+		if !strings.Contains(messages, want) {
+			t.Errorf("events missing %q:\n%s", want, messages)
+		}
+	}
+}
+
+func TestMigratePR_RollbackOnInterrupt(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client, mockGit, _, _ := newRollbackTestClient(func(int, string) error {
+		cancel()
+		return context.Canceled
+	})
+	var events []Event
+	client.SetEventHandler(func(event Event) { events = append(events, event) })
+
+	if err := client.MigratePR(ctx, "123", Options{}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("MigratePR() error = %v, want context.Canceled", err)
+	}
+	if len(mockGit.deleted) != 1 {
+		t.Errorf("deleted = %v, want the partial branch removed", mockGit.deleted)
+	}
+	if !strings.Contains(eventMessages(events), "Interrupted, rolling back...") {
+		t.Errorf("events missing interruption notice:\n%s", eventMessages(events))
+	}
+}
+
+func TestMigratePR_RollbackBeforeBranchCreated(t *testing.T) {
+	client, mockGit, checkouts, _ := newRollbackTestClient(nil)
+	mockGit.pullFunc = func(_ context.Context, _, _ string) error {
+		return errors.New("pull failed")
+	}
+
+	if err := client.MigratePR(context.Background(), "123", Options{}); err == nil {
+		t.Fatal("MigratePR() expected pull error")
+	}
+	if len(mockGit.deleted) != 0 {
+		t.Errorf("deleted = %v, want nothing deleted", mockGit.deleted)
+	}
+	if got := strings.Join(*checkouts, ","); got != "main,main" {
+		t.Errorf("checkouts = %q, want the original branch restored", got)
+	}
+}
+
+func TestMigratePR_NoRollbackOncePushed(t *testing.T) {
+	client, mockGit, _, branches := newRollbackTestClient(nil)
+	client.SetConfirmHandler(func(string) bool { return true })
+	client.forge.(*mockGitHub).createPRFunc = func(string, string, github.CreatePROptions) (*PRInfo, error) {
+		return nil, errors.New("create failed")
+	}
+
+	if err := client.MigratePR(context.Background(), "123", Options{}); err == nil {
+		t.Fatal("MigratePR() expected create error")
+	}
+	if len(mockGit.deleted) != 0 || !branches["migrated-123"] {
+		t.Errorf("deleted = %v, want the pushed branch kept", mockGit.deleted)
+	}
+}
+
+func TestMigratePRs_StopsWhenInterrupted(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	migrated := 0
+	mockGitHub := forkPRGitHub()
+	mockGitHub.checkoutPRFunc = func(int, string) error {
+		migrated++
+		cancel()
+		return nil
+	}
+
+	client := newTestClient(&mockGit{}, mockGitHub)
+	var events []Event
+	client.SetEventHandler(func(event Event) { events = append(events, event) })
+	batch, err := client.MigratePRs(ctx, []string{"1", "2", "3"}, Options{NoPush: true})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("MigratePRs() error = %v, want interruption", err)
+	}
+	if migrated != 1 {
+		t.Errorf("migrated %d PRs after interruption, want 1", migrated)
+	}
+	if !strings.Contains(eventMessages(events), "Interrupted; skipped 2 remaining PRs") {
+		t.Errorf("events = %s, want the skipped PRs reported", eventMessages(events))
+	}
+	if len(batch.Results) != 3 || !errors.Is(batch.Results[2].Err, context.Canceled) {
+		t.Errorf("MigratePRs() results = %+v, want the skipped PRs marked as interrupted", batch.Results)
+	}
+}
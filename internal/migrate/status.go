@@ -0,0 +1,89 @@
+package migrate
+
+import (
+	"context"
+	"strings"
+)
+
+// MigrationStatus describes a migrated branch and the upstream state of the
+// PR it came from.
+type MigrationStatus struct {
+	Provenance
+
+	Original    *PRInfo
+	Replacement *PRInfo
+	// Remote is the git remote, or the URL, the branch was pushed to.
+	Remote      string
+	LocalBranch bool
+	Pushed      bool
+	ForkUpdated bool
+	Error       error
+}
+
+func (s *MigrationStatus) OriginalOpen() bool {
+	return s.Original != nil && strings.EqualFold(s.Original.State, "open")
+}
+
+// pushLabel names where the branch was pushed, as target.pushLabel does.
+func (s *MigrationStatus) pushLabel() string {
+	if s.Target != "" && s.Remote != "origin" {
+		return s.Target
+	}
+	return "origin"
+}
+
+func (c *Client) Status(ctx context.Context) ([]MigrationStatus, error) {
+	records, err := c.ListMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	owner, repo, err := c.git.CurrentRepo(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(records))
+	for _, record := range records {
+		statuses = append(statuses, c.migrationStatus(ctx, owner, repo, record))
+	}
+	return statuses, nil
+}
+
+func (c *Client) migrationStatus(ctx context.Context, owner, repo string, record Provenance) MigrationStatus {
+	// A branch migrated with --target was pushed where resolveTarget sent it,
+	// and its replacement PR lives there.
+	remote := "origin"
+	if record.Target != "" {
+		if t, err := c.resolveTarget(ctx, record.Owner, record.Repo, Options{Target: record.Target}); err == nil {
+			owner, repo, remote = t.owner, t.repo, t.remote
+		}
+	}
+
+	status := MigrationStatus{
+		Provenance:  record,
+		Remote:      remote,
+		LocalBranch: c.git.HasBranch(ctx, record.Branch),
+		Pushed:      c.git.HasRemoteBranch(ctx, remote, record.Branch),
+	}
+
+	original, err := c.forge.GetPR(ctx, record.Owner, record.Repo, record.Number)
+	if err != nil {
+		status.Error = err
+		return status
+	}
+	status.Original = original
+	status.ForkUpdated = record.HeadSHA != "" && original.HeadRefOID != record.HeadSHA
+
+	replacement, err := c.forge.FindPRForBranch(ctx, owner, repo, record.Branch)
+	if err != nil {
+		status.Error = err
+		return status
+	}
+	status.Replacement = replacement
+
+	return status
+}
@@ -0,0 +1,148 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/user/git-mfpr/internal/git"
+	"github.com/user/git-mfpr/internal/github"
+)
+
+func TestStatus(t *testing.T) {
+	mockGit := &mockGit{
+		refs: map[string][]byte{
+			"refs/mfpr/123": []byte(`{"owner":"upstream","repo":"project","number":123,"branch":"migrated-123","head_sha":"aaa"}`),
+			"refs/mfpr/124": []byte(`{"owner":"upstream","repo":"project","number":124,"branch":"migrated-124","head_sha":"bbb"}`),
+		},
+		hasBranchFunc: func(_ context.Context, name string) bool {
+			return name == "migrated-123"
+		},
+		remoteBranches: map[string]bool{"migrated-123": true, "migrated-124": true},
+	}
+	mockGitHub := &mockGitHub{
+		getPRFunc: func(_, _ string, number int) (*github.PRInfo, error) {
+			if number == 123 {
+				return &github.PRInfo{Number: 123, State: "OPEN", HeadRefOID: "ccc"}, nil
+			}
+			return &github.PRInfo{Number: 124, State: "CLOSED", HeadRefOID: "bbb"}, nil
+		},
+		findPRFunc: func(owner, repo, branch string) (*github.PRInfo, error) {
+			if owner != "testowner" || repo != "testrepo" {
+				t.Errorf("FindPRForBranch() repo = %s/%s, want testowner/testrepo", owner, repo)
+			}
+			if branch == "migrated-124" {
+				return &github.PRInfo{Number: 200, State: "MERGED"}, nil
+			}
+			return nil, nil
+		},
+	}
+
+	client := newTestClient(mockGit, mockGitHub)
+	statuses, err := client.Status(context.Background())
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	if len(statuses) != 2 {
+		t.Fatalf("Status() returned %d entries, want 2", len(statuses))
+	}
+
+	first := statuses[0]
+	if !first.OriginalOpen() || !first.ForkUpdated || !first.LocalBranch || !first.Pushed || first.Replacement != nil {
+		t.Errorf("unexpected status for #123: %+v", first)
+	}
+
+	second := statuses[1]
+	if second.OriginalOpen() || second.ForkUpdated || second.LocalBranch || !second.Pushed {
+		t.Errorf("unexpected status for #124: %+v", second)
+	}
+	if second.Replacement == nil || second.Replacement.Number != 200 {
+		t.Errorf("expected replacement PR #200 for #124, got %+v", second.Replacement)
+	}
+}
+
+func TestStatus_GetPRError(t *testing.T) {
+	mockGit := &mockGit{
+		refs: map[string][]byte{
+			"refs/mfpr/123": []byte(`{"owner":"upstream","repo":"project","number":123,"branch":"migrated-123"}`),
+		},
+	}
+	mockGitHub := &mockGitHub{
+		getPRFunc: func(_, _ string, number int) (*github.PRInfo, error) {
+			return nil, &github.ErrPRNotFound{Number: number}
+		},
+	}
+
+	client := newTestClient(mockGit, mockGitHub)
+	statuses, err := client.Status(context.Background())
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	if len(statuses) != 1 || statuses[0].Error == nil {
+		t.Fatalf("expected per-entry error, got %+v", statuses)
+	}
+	if statuses[0].OriginalOpen() {
+		t.Error("OriginalOpen() should be false when the original could not be fetched")
+	}
+}
+
+func TestStatus_NoRecords(t *testing.T) {
+	mockGit := &mockGit{
+		currentRepoFunc: func(_ context.Context) (string, string, error) {
+			return "", "", errors.New("should not be called")
+		},
+	}
+
+	client := newTestClient(mockGit, &mockGitHub{})
+	statuses, err := client.Status(context.Background())
+	if err != nil || len(statuses) != 0 {
+		t.Errorf("Status() = %v, %v, want no entries", statuses, err)
+	}
+}
+
+func TestStatus_CurrentRepoError(t *testing.T) {
+	mockGit := &mockGit{
+		refs: map[string][]byte{
+			"refs/mfpr/1": []byte(`{"number":1,"branch":"migrated-1"}`),
+		},
+		currentRepoFunc: func(_ context.Context) (string, string, error) {
+			return "", "", &git.ErrGetRemoteURLFailed{Detail: "no origin"}
+		},
+	}
+
+	client := newTestClient(mockGit, &mockGitHub{})
+	if _, err := client.Status(context.Background()); err == nil {
+		t.Error("Status() should fail without an origin remote")
+	}
+}
+
+func TestStatus_Target(t *testing.T) {
+	const remote = "git@github.com:acme/other.git"
+	mockGit := &mockGit{
+		refs: map[string][]byte{
+			"refs/mfpr/upstream/project/5": []byte(`{"owner":"upstream","repo":"project","number":5,"branch":"migrated-5","target":"acme/other"}`),
+		},
+		remoteBranchAt: func(r, name string) bool {
+			return r == remote && name == "migrated-5"
+		},
+	}
+	mockGitHub := &mockGitHub{
+		getPRFunc: func(_, _ string, number int) (*github.PRInfo, error) {
+			return &github.PRInfo{Number: number, State: "CLOSED"}, nil
+		},
+		findPRFunc: func(owner, repo, _ string) (*github.PRInfo, error) {
+			if owner != "acme" || repo != "other" {
+				t.Errorf("FindPRForBranch() repo = %s/%s, want the target acme/other", owner, repo)
+			}
+			return &github.PRInfo{Number: 9, State: "MERGED"}, nil
+		},
+	}
+
+	statuses, err := newTestClient(mockGit, mockGitHub).Status(context.Background())
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	if len(statuses) != 1 || statuses[0].Remote != remote || !statuses[0].Pushed {
+		t.Errorf("Status() = %+v, want migrated-5 pushed to %s", statuses, remote)
+	}
+}
@@ -0,0 +1,104 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+)
+
+// Strategy is how the PR's commits were brought into the migrated branch.
+type Strategy string
+
+const (
+	// StrategyCheckout checks out the PR branch through the forge.
+	StrategyCheckout Strategy = "checkout"
+	// StrategyPatch applies the PR's patch series from the forge with
+	// git am, for PRs whose fork is deleted or private.
+	StrategyPatch Strategy = "patch"
+)
+
+func (s Strategy) describe() string {
+	if s == StrategyPatch {
+		return "applied the PR's patch series with git am"
+	}
+	return "checked out the PR branch"
+}
+
+// createBranch brings the PR's commits into m.branch, checking out the PR
+// branch and falling back to its patch series when the fork is unreachable.
+// A PR being retargeted is always replayed from its patch series, so that
+// only its own commits land on the new base. Any path renames are applied on
+// the way.
+func (c *Client) createBranch(ctx context.Context, owner, repo string, pr *PRInfo, m *migration, renames []PathRename) error {
+	if m.fromBase != "" {
+		return c.replayOntoBase(ctx, owner, repo, pr, m, renames)
+	}
+
+	c.emit(EventInfo, fmt.Sprintf("Checking out PR #%d...", pr.Number), "")
+	m.createdBranch = true
+	checkoutErr := c.forge.CheckoutPR(ctx, owner, repo, pr.Number, m.branch)
+
+	switch {
+	case checkoutErr == nil:
+		m.strategy = StrategyCheckout
+		if len(renames) > 0 {
+			rejects, err := c.remapBranch(ctx, pr, m, renames)
+			if err != nil {
+				return err
+			}
+			m.rejects = rejects
+		}
+	case ctx.Err() != nil:
+		return checkoutErr
+	default:
+		c.emit(EventInfo, "Could not check out the PR branch; falling back to its patch series", checkoutErr.Error())
+		c.emit(EventInfo, fmt.Sprintf("Downloading patches for PR #%d...", pr.Number), "")
+		mbox, err := c.forge.GetPRPatch(ctx, owner, repo, pr.Number)
+		if err != nil {
+			c.emit(EventError, "Could not download the PR's patches", err.Error())
+			return checkoutErr
+		}
+		m.strategy = StrategyPatch
+		if err := c.applyPRPatches(ctx, pr, m, mbox, renames); err != nil {
+			return err
+		}
+	}
+
+	c.emit(EventInfo, fmt.Sprintf("Strategy: %s", m.strategy.describe()), "")
+	return nil
+}
+
+func (c *Client) replayOntoBase(ctx context.Context, owner, repo string, pr *PRInfo, m *migration, renames []PathRename) error {
+	c.emit(EventInfo, fmt.Sprintf("Downloading patches for PR #%d...", pr.Number), "")
+	m.createdBranch = true
+	mbox, err := c.forge.GetPRPatch(ctx, owner, repo, pr.Number)
+	if err != nil {
+		return err
+	}
+
+	c.emit(EventInfo, fmt.Sprintf("Replaying the PR's commits from %s onto %s...", m.fromBase, pr.BaseBranch), "")
+	m.strategy = StrategyPatch
+	if err := c.applyPRPatches(ctx, pr, m, mbox, renames); err != nil {
+		return err
+	}
+	c.emit(EventInfo, fmt.Sprintf("Strategy: %s", m.strategy.describe()), "")
+	return nil
+}
+
+// applyPRPatches applies the PR's commits, downloaded as an mbox, to a new
+// branch from the base branch, keeping each commit's author and date.
+func (c *Client) applyPRPatches(ctx context.Context, pr *PRInfo, m *migration, mbox []byte, renames []PathRename) error {
+	if len(renames) > 0 {
+		c.emit(EventInfo, "Remapping paths in the PR's commits...", "")
+		mbox = remapPatch(mbox, renames)
+	}
+
+	if err := c.git.ResetBranch(ctx, m.branch, pr.BaseBranch); err != nil {
+		return err
+	}
+	rejects, err := c.applyPatches(ctx, m, mbox)
+	if err != nil {
+		return err
+	}
+	m.rejects = rejects
+	return nil
+}
@@ -0,0 +1,229 @@
+package migrate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/user/git-mfpr/internal/github"
+)
+
+const prPatch = `From 0123456789abcdef0123456789abcdef01234567 Mon Sep 17 00:00:00 2001
+From: Contributor <contributor@example.com>
+Date: Mon, 1 Jan 2024 10:00:00 +0000
+Subject: [PATCH] Fix typo
+
+diff --git a/pkg/a.go b/pkg/a.go
+--- a/pkg/a.go
++++ b/pkg/a.go
+@@ -1 +1 @@
+-old
++new
+`
+
+func TestMigratePR_Strategy(t *testing.T) {
+	checkoutFailed := &github.ErrPRCheckoutFailed{Number: 123, Detail: "could not find fork"}
+
+	tests := []struct {
+		name         string
+		checkoutErr  error
+		patchErr     error
+		renames      []PathRename
+		wantErr      error
+		wantStrategy Strategy
+		wantMailbox  string
+		wantEvents   []string
+	}{
+		{
+			name:         "checkout",
+			wantStrategy: StrategyCheckout,
+			wantEvents:   []string{"Strategy: checked out the PR branch"},
+		},
+		{
+			name:         "fork unreachable",
+			checkoutErr:  checkoutFailed,
+			wantStrategy: StrategyPatch,
+			wantMailbox:  prPatch,
+			wantEvents: []string{
+				"Could not check out the PR branch; falling back to its patch series",
+				"Strategy: applied the PR's patch series with git am",
+			},
+		},
+		{
+			name:         "fork unreachable with path renames",
+			checkoutErr:  checkoutFailed,
+			renames:      []PathRename{{Old: "pkg/", New: "internal/"}},
+			wantStrategy: StrategyPatch,
+			wantMailbox:  strings.ReplaceAll(prPatch, "/pkg/", "/internal/"),
+		},
+		{
+			name:        "patches unavailable",
+			checkoutErr: checkoutFailed,
+			patchErr:    &github.ErrPRPatchFailed{Number: 123, Detail: "HTTP 404"},
+			wantErr:     checkoutFailed,
+			wantEvents:  []string{"Could not download the PR's patches"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &patchGit{mockGit: &mockGit{}}
+			mockGitHub := forkPRGitHub()
+			mockGitHub.checkoutPRFunc = func(int, string) error { return tt.checkoutErr }
+			mockGitHub.getPRPatchFunc = func(_, _ string, _ int) ([]byte, error) {
+				if tt.patchErr != nil {
+					return nil, tt.patchErr
+				}
+				return []byte(prPatch), nil
+			}
+
+			client := newTestClient(mock, mockGitHub)
+			client.SetConfirmHandler(func(string) bool { return true })
+			var events []Event
+			client.SetEventHandler(func(e Event) { events = append(events, e) })
+
+			err := client.MigratePR(context.Background(), "123", Options{NoCreate: true, PathRenames: tt.renames})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("MigratePR() error = %v, want %v", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("MigratePR() error = %v", err)
+			}
+
+			messages := eventMessages(events)
+			for _, want := range tt.wantEvents {
+				if !strings.Contains(messages, want) {
+					t.Errorf("events missing %q:\n%s", want, messages)
+				}
+			}
+
+			if tt.wantMailbox != "" {
+				if string(mock.mailbox) != tt.wantMailbox {
+					t.Errorf("applied mailbox =\n%s\nwant\n%s", mock.mailbox, tt.wantMailbox)
+				}
+				if len(mock.resets) != 1 || mock.resets[0] != "migrated-123 main" {
+					t.Errorf("resets = %v, want the branch started from main", mock.resets)
+				}
+			}
+
+			if tt.wantStrategy == "" {
+				return
+			}
+			var record Provenance
+			if err := json.Unmarshal(mock.refs["refs/mfpr/testowner/testrepo/123"], &record); err != nil {
+				t.Fatalf("provenance record: %v", err)
+			}
+			if record.Strategy != tt.wantStrategy {
+				t.Errorf("provenance strategy = %q, want %q", record.Strategy, tt.wantStrategy)
+			}
+		})
+	}
+}
+
+func TestMigratePR_NoFallbackWhenInterrupted(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mockGitHub := forkPRGitHub()
+	mockGitHub.checkoutPRFunc = func(int, string) error {
+		cancel()
+		return context.Canceled
+	}
+	mockGitHub.getPRPatchFunc = func(string, string, int) ([]byte, error) {
+		t.Error("patches should not be downloaded after an interruption")
+		return nil, nil
+	}
+
+	client := newTestClient(&mockGit{}, mockGitHub)
+	if err := client.MigratePR(ctx, "123", Options{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("MigratePR() error = %v, want context.Canceled", err)
+	}
+}
+
+func TestMigratePR_Retarget(t *testing.T) {
+	mock := &patchGit{mockGit: &mockGit{}}
+	var checkouts []string
+	mock.checkoutFunc = func(_ context.Context, branch string) error {
+		checkouts = append(checkouts, branch)
+		return nil
+	}
+
+	mockGitHub := forkPRGitHub()
+	mockGitHub.getPRFunc = func(_, _ string, number int) (*github.PRInfo, error) {
+		return &github.PRInfo{Number: number, Title: "Fix typo", Author: "contributor", BaseBranch: "master", State: "OPEN", IsFork: true}, nil
+	}
+	mockGitHub.checkoutPRFunc = func(int, string) error {
+		t.Error("a retargeted PR should be replayed from its patches, not checked out")
+		return nil
+	}
+	mockGitHub.getPRPatchFunc = func(string, string, int) ([]byte, error) { return []byte(prPatch), nil }
+	var created github.CreatePROptions
+	mockGitHub.createPRFunc = func(_, _ string, opts github.CreatePROptions) (*github.PRInfo, error) {
+		created = opts
+		return &github.PRInfo{Number: 500}, nil
+	}
+
+	client := newTestClient(mock, mockGitHub)
+	client.SetConfirmHandler(func(string) bool { return true })
+	var events []Event
+	client.SetEventHandler(func(e Event) { events = append(events, e) })
+
+	if err := client.MigratePR(context.Background(), "123", Options{Base: "main"}); err != nil {
+		t.Fatalf("MigratePR() error = %v", err)
+	}
+
+	if len(checkouts) == 0 || checkouts[0] != "main" {
+		t.Errorf("checkouts = %v, want the new base checked out first", checkouts)
+	}
+	if len(mock.resets) != 1 || mock.resets[0] != "migrated-123 main" {
+		t.Errorf("resets = %v, want the branch started from main", mock.resets)
+	}
+	if string(mock.mailbox) != prPatch {
+		t.Errorf("applied mailbox = %q, want the PR's patch series", mock.mailbox)
+	}
+	if created.Base != "main" {
+		t.Errorf("replacement PR base = %q, want main", created.Base)
+	}
+	if messages := eventMessages(events); !strings.Contains(messages, "Retargeting from master to main") {
+		t.Errorf("events missing the retarget notice:\n%s", messages)
+	}
+
+	var record Provenance
+	if err := json.Unmarshal(mock.refs["refs/mfpr/testowner/testrepo/123"], &record); err != nil {
+		t.Fatalf("provenance record: %v", err)
+	}
+	if record.RetargetedFrom != "master" || record.Strategy != StrategyPatch {
+		t.Errorf("provenance = %+v, want retargeted from master with the patch strategy", record)
+	}
+}
+
+func TestMigratePR_RetargetDryRun(t *testing.T) {
+	client := newTestClient(&mockGit{}, forkPRGitHub())
+	var events []Event
+	client.SetEventHandler(func(e Event) { events = append(events, e) })
+
+	if err := client.MigratePR(context.Background(), "123", Options{DryRun: true, Base: "develop"}); err != nil {
+		t.Fatalf("MigratePR() error = %v", err)
+	}
+
+	var commands []string
+	for _, e := range events {
+		commands = append(commands, e.Message+" "+e.Detail)
+	}
+	all := strings.Join(commands, "\n")
+	for _, want := range []string{
+		"Would execute: git checkout develop",
+		"Would replay the PR's commits from main onto develop:",
+		"--base develop",
+	} {
+		if !strings.Contains(all, want) {
+			t.Errorf("dry run missing %q:\n%s", want, all)
+		}
+	}
+	if strings.Contains(all, "gh pr checkout") {
+		t.Errorf("dry run should not check out a retargeted PR:\n%s", all)
+	}
+}
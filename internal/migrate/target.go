@@ -0,0 +1,95 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/user/git-mfpr/internal/forge"
+)
+
+// target is the repository a migrated branch is pushed to and its
+// replacement PR is opened in.
+type target struct {
+	owner string
+	repo  string
+	// remote is the git remote, or the URL, that the branch is pushed to.
+	remote string
+	// cross is set when the target differs from the PR's own repository.
+	cross bool
+}
+
+func (t *target) String() string {
+	return t.owner + "/" + t.repo
+}
+
+// pushLabel names where the branch is pushed in progress messages.
+func (t *target) pushLabel() string {
+	if t.remote == "origin" {
+		return "origin"
+	}
+	return t.String()
+}
+
+// ref links to PR number in the target, qualified with the repository when it
+// lives in a different one from the original PR.
+func (t *target) ref(owner, repo string, number int) string {
+	if t.cross {
+		return fmt.Sprintf("%s/%s#%d", owner, repo, number)
+	}
+	return fmt.Sprintf("#%d", number)
+}
+
+func parseRepo(s string) (owner, repo string, err error) {
+	parts := strings.Split(s, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("invalid target %q, expected owner/repo", s)
+	}
+	return parts[0], parts[1], nil
+}
+
+// resolveTarget works out where a PR from owner/repo should be migrated to.
+// Without opts.Target that is the PR's own repository through origin. A
+// different target is pushed to through origin when origin is that
+// repository, and otherwise by URL, in the same style as origin's URL.
+func (c *Client) resolveTarget(ctx context.Context, owner, repo string, opts Options) (*target, error) {
+	if opts.Target == "" {
+		return &target{owner: owner, repo: repo, remote: "origin"}, nil
+	}
+
+	targetOwner, targetRepo, err := parseRepo(opts.Target)
+	if err != nil {
+		return nil, err
+	}
+	t := &target{
+		owner:  targetOwner,
+		repo:   targetRepo,
+		remote: "origin",
+		cross:  !strings.EqualFold(opts.Target, owner+"/"+repo),
+	}
+	if !t.cross {
+		return t, nil
+	}
+
+	originOwner, originRepo, err := c.git.CurrentRepo(ctx)
+	if err == nil && strings.EqualFold(originOwner+"/"+originRepo, opts.Target) {
+		return t, nil
+	}
+
+	originURL, _ := c.git.RemoteURL(ctx, "origin")
+	t.remote = repoURL(originURL, targetOwner, targetRepo)
+	return t, nil
+}
+
+// repoURL returns the URL of owner/repo on the same forge as the remote URL
+// like, using SSH when like does. It falls back to GitHub.
+func repoURL(like, owner, repo string) string {
+	remote, err := forge.ParseRemoteURL(like)
+	if err != nil {
+		remote = &forge.Remote{Host: "github.com", BaseURL: "https://github.com"}
+	}
+	if strings.HasPrefix(like, "ssh://") || (err == nil && !strings.Contains(like, "://")) {
+		return fmt.Sprintf("git@%s:%s/%s.git", remote.Host, owner, repo)
+	}
+	return fmt.Sprintf("%s/%s/%s.git", remote.BaseURL, owner, repo)
+}
@@ -0,0 +1,160 @@
+package migrate
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/user/git-mfpr/internal/github"
+)
+
+func TestParseRepo(t *testing.T) {
+	tests := []struct {
+		input     string
+		wantOwner string
+		wantRepo  string
+		wantErr   bool
+	}{
+		{input: "owner/repo", wantOwner: "owner", wantRepo: "repo"},
+		{input: "owner", wantErr: true},
+		{input: "owner/", wantErr: true},
+		{input: "a/b/c", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			owner, repo, err := parseRepo(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseRepo() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if owner != tt.wantOwner || repo != tt.wantRepo {
+				t.Errorf("parseRepo() = %s, %s; want %s, %s", owner, repo, tt.wantOwner, tt.wantRepo)
+			}
+		})
+	}
+}
+
+func TestResolveTarget(t *testing.T) {
+	tests := []struct {
+		name       string
+		origin     string
+		target     string
+		wantTarget string
+		wantRemote string
+		wantCross  bool
+		wantErr    bool
+	}{
+		{name: "no target", origin: "src/repo", wantTarget: "src/repo", wantRemote: "origin"},
+		{name: "target is the source", origin: "src/repo", target: "src/repo", wantTarget: "src/repo", wantRemote: "origin"},
+		{name: "origin is the target", origin: "new/repo", target: "new/repo", wantTarget: "new/repo", wantRemote: "origin", wantCross: true},
+		{name: "target pushed by URL", origin: "src/repo", target: "new/repo", wantTarget: "new/repo", wantRemote: "git@github.com:new/repo.git", wantCross: true},
+		{name: "invalid target", origin: "src/repo", target: "new", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			originOwner, originRepo, _ := parseRepo(tt.origin)
+			client := newTestClient(&mockGit{
+				currentRepoFunc: func(context.Context) (string, string, error) { return originOwner, originRepo, nil },
+			}, &mockGitHub{})
+
+			got, err := client.resolveTarget(context.Background(), "src", "repo", Options{Target: tt.target})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveTarget() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.String() != tt.wantTarget || got.remote != tt.wantRemote || got.cross != tt.wantCross {
+				t.Errorf("resolveTarget() = %s via %s (cross %v), want %s via %s (cross %v)",
+					got, got.remote, got.cross, tt.wantTarget, tt.wantRemote, tt.wantCross)
+			}
+		})
+	}
+}
+
+func TestRepoURL(t *testing.T) {
+	tests := []struct {
+		like string
+		want string
+	}{
+		{"git@github.com:src/repo.git", "git@github.com:new/repo.git"},
+		{"ssh://git@github.com/src/repo.git", "git@github.com:new/repo.git"},
+		{"https://github.com/src/repo.git", "https://github.com/new/repo.git"},
+		{"", "https://github.com/new/repo.git"},
+		{"git@gitlab.example.com:group/sub/src.git", "git@gitlab.example.com:new/repo.git"},
+		{"http://localhost:3000/src/repo.git", "http://localhost:3000/new/repo.git"},
+	}
+
+	for _, tt := range tests {
+		if got := repoURL(tt.like, "new", "repo"); got != tt.want {
+			t.Errorf("repoURL(%q) = %q, want %q", tt.like, got, tt.want)
+		}
+	}
+}
+
+func TestMigratePR_CrossRepository(t *testing.T) {
+	var pushedTo string
+	mockGit := &mockGit{
+		currentRepoFunc: func(context.Context) (string, string, error) { return "src", "monorepo", nil },
+		pushFunc: func(_ context.Context, remote, _ string) error {
+			pushedTo = remote
+			return nil
+		},
+		pullFunc: func(context.Context, string, string) error {
+			t.Error("the base branch should not be pulled from a different repository")
+			return nil
+		},
+	}
+
+	var createdIn string
+	var created github.CreatePROptions
+	mockGitHub := forkPRGitHub()
+	mockGitHub.getPRFunc = func(owner, repo string, number int) (*github.PRInfo, error) {
+		if owner+"/"+repo != "src/monorepo" {
+			t.Errorf("GetPR() from %s/%s, want the source repository", owner, repo)
+		}
+		// Same-repository PRs can be moved into another repository.
+		return &github.PRInfo{Number: number, Title: "Split", Author: "dev", BaseBranch: "main", State: "OPEN"}, nil
+	}
+	mockGitHub.createPRFunc = func(owner, repo string, opts github.CreatePROptions) (*github.PRInfo, error) {
+		createdIn = owner + "/" + repo
+		created = opts
+		return &github.PRInfo{Number: 7, URL: "https://github.com/dst/service/pull/7"}, nil
+	}
+
+	client := newTestClient(mockGit, mockGitHub)
+	client.SetConfirmHandler(func(string) bool { return true })
+	var events []Event
+	client.SetEventHandler(func(e Event) { events = append(events, e) })
+
+	err := client.MigratePR(context.Background(), "src/monorepo#42", Options{Target: "dst/service", CloseOriginal: true})
+	if err != nil {
+		t.Fatalf("MigratePR() error = %v", err)
+	}
+
+	if pushedTo != "git@github.com:dst/service.git" {
+		t.Errorf("pushed to %q, want the target repository", pushedTo)
+	}
+	if createdIn != "dst/service" {
+		t.Errorf("replacement PR created in %q, want dst/service", createdIn)
+	}
+	if !strings.Contains(created.Body, "Migrated from src/monorepo#42") {
+		t.Errorf("replacement body = %q, want a cross-repository link", created.Body)
+	}
+	if len(mockGitHub.closed) != 1 || mockGitHub.closed[0] != 42 {
+		t.Errorf("closed = %v, want the original PR", mockGitHub.closed)
+	}
+	if !strings.Contains(eventMessages(events), "Migrating PR #42 from src/monorepo into dst/service") {
+		t.Errorf("events missing cross-repository notice:\n%s", eventMessages(events))
+	}
+
+	var record Provenance
+	if err := json.Unmarshal(mockGit.refs["refs/mfpr/src/monorepo/42"], &record); err != nil {
+		t.Fatalf("provenance record: %v", err)
+	}
+	if record.Owner != "src" || record.Repo != "monorepo" || record.Target != "dst/service" {
+		t.Errorf("provenance = %+v, want source src/monorepo and target dst/service", record)
+	}
+}
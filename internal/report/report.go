@@ -0,0 +1,233 @@
+// Package report writes a summary of a batch of migrations for sharing with a
+// team, as Markdown, CSV or HTML.
+package report
+
+import (
+	"encoding/csv"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/user/git-mfpr/internal/migrate"
+)
+
+// Format is a report file format.
+type Format string
+
+const (
+	Markdown Format = "markdown"
+	CSV      Format = "csv"
+	HTML     Format = "html"
+)
+
+// FormatFor picks the report format from path's extension.
+func FormatFor(path string) (Format, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md", ".markdown":
+		return Markdown, nil
+	case ".csv":
+		return CSV, nil
+	case ".html", ".htm":
+		return HTML, nil
+	}
+	return "", fmt.Errorf("unsupported report format for %s: use .md, .csv or .html", path)
+}
+
+// Write writes a report of results to path, in the format its extension
+// names.
+func Write(path string, results []migrate.MigrationResult) error {
+	format, err := FormatFor(path)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(path) // #nosec G304
+	if err != nil {
+		return err
+	}
+	if err := WriteFormat(f, format, results); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// WriteFormat writes a report of results to w.
+func WriteFormat(w io.Writer, format Format, results []migrate.MigrationResult) error {
+	rows := make([]row, 0, len(results))
+	for i := range results {
+		rows = append(rows, newRow(&results[i]))
+	}
+
+	switch format {
+	case Markdown:
+		return writeMarkdown(w, rows)
+	case CSV:
+		return writeCSV(w, rows)
+	case HTML:
+		return writeHTML(w, rows)
+	}
+	return fmt.Errorf("unsupported report format %q", format)
+}
+
+// row is one PR's line in a report.
+type row struct {
+	PR         string
+	URL        string
+	Title      string
+	Author     string
+	Branch     string
+	NewPR      string
+	NewPRURL   string
+	Commits    int
+	Status     migrate.Status
+	ErrorClass string
+	Error      string
+	Duration   time.Duration
+}
+
+func newRow(r *migrate.MigrationResult) row {
+	out := row{
+		PR:         r.Ref,
+		URL:        r.URL(),
+		Branch:     r.Branch,
+		Commits:    r.Commits,
+		Status:     r.Status(),
+		ErrorClass: r.ErrorClass(),
+		Duration:   r.Duration.Round(100 * time.Millisecond),
+	}
+	if r.PR != nil {
+		out.PR = fmt.Sprintf("#%d", r.PR.Number)
+		out.Title = r.PR.Title
+		out.Author = r.PR.Author
+	}
+	if r.Created != nil {
+		out.NewPR = fmt.Sprintf("#%d", r.Created.Number)
+		out.NewPRURL = r.Created.URL
+	}
+	if r.Err != nil {
+		out.Error = r.Err.Error()
+	}
+	return out
+}
+
+func summary(rows []row) string {
+	migrated := 0
+	for _, r := range rows {
+		if r.Status == migrate.StatusMigrated {
+			migrated++
+		}
+	}
+	return fmt.Sprintf("Migrated %d of %d PRs.", migrated, len(rows))
+}
+
+var markdownEscaper = strings.NewReplacer("|", `\|`, "\n", " ", "[", `\[`, "]", `\]`)
+
+func writeMarkdown(w io.Writer, rows []row) error {
+	var b strings.Builder
+	b.WriteString("# git-mfpr migration report\n\n")
+	b.WriteString(summary(rows) + "\n\n")
+	b.WriteString("| PR | Title | Author | Branch | New PR | Commits | Status | Error | Duration |\n")
+	b.WriteString("|----|-------|--------|--------|--------|--------:|--------|-------|---------:|\n")
+
+	var failures []row
+	for _, r := range rows {
+		link := func(text, url string) string {
+			if url == "" || url == text {
+				return markdownEscaper.Replace(text)
+			}
+			return fmt.Sprintf("[%s](%s)", markdownEscaper.Replace(text), url)
+		}
+		cells := []string{
+			link(r.PR, r.URL),
+			markdownEscaper.Replace(r.Title),
+			"",
+			"",
+			link(r.NewPR, r.NewPRURL),
+			strconv.Itoa(r.Commits),
+			string(r.Status),
+			r.ErrorClass,
+			r.Duration.String(),
+		}
+		if r.Author != "" {
+			cells[2] = "@" + r.Author
+		}
+		if r.Branch != "" {
+			cells[3] = "`" + r.Branch + "`"
+		}
+		b.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+		if r.Error != "" {
+			failures = append(failures, r)
+		}
+	}
+
+	if len(failures) > 0 {
+		b.WriteString("\n## Failures\n\n")
+		for _, r := range failures {
+			fmt.Fprintf(&b, "- %s: %s\n", markdownEscaper.Replace(r.PR), markdownEscaper.Replace(r.Error))
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeCSV(w io.Writer, rows []row) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"pr", "url", "title", "author", "branch", "new_pr", "new_pr_url", "commits", "status", "error_class", "error", "duration_seconds"})
+	for _, r := range rows {
+		cw.Write([]string{
+			r.PR, r.URL, r.Title, r.Author, r.Branch, r.NewPR, r.NewPRURL,
+			strconv.Itoa(r.Commits), string(r.Status), r.ErrorClass, r.Error,
+			strconv.FormatFloat(r.Duration.Seconds(), 'f', 1, 64),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+var htmlReport = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>git-mfpr migration report</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", sans-serif; margin: 2em; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #d0d7de; padding: 4px 8px; text-align: left; }
+td.num { text-align: right; }
+tr.failed { background: #ffebe9; }
+tr.conflicts, tr.local-only { background: #fff8c5; }
+</style>
+</head>
+<body>
+<h1>git-mfpr migration report</h1>
+<p>{{.Summary}}</p>
+<table>
+<tr><th>PR</th><th>Title</th><th>Author</th><th>Branch</th><th>New PR</th><th>Commits</th><th>Status</th><th>Error</th><th>Duration</th></tr>
+{{range .Rows}}<tr class="{{.Class}}"><td>{{if .URL}}<a href="{{.URL}}">{{.PR}}</a>{{else}}{{.PR}}{{end}}</td><td>{{.Title}}</td><td>{{if .Author}}@{{.Author}}{{end}}</td><td><code>{{.Branch}}</code></td><td>{{if .NewPRURL}}<a href="{{.NewPRURL}}">{{.NewPR}}</a>{{end}}</td><td class="num">{{.Commits}}</td><td>{{.Status}}</td><td>{{if .Error}}<span title="{{.Error}}">{{.ErrorClass}}</span>{{end}}</td><td class="num">{{.Duration}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+type htmlRow struct {
+	row
+	Class string
+}
+
+func writeHTML(w io.Writer, rows []row) error {
+	data := struct {
+		Summary string
+		Rows    []htmlRow
+	}{Summary: summary(rows)}
+	for _, r := range rows {
+		data.Rows = append(data.Rows, htmlRow{row: r, Class: strings.ReplaceAll(string(r.Status), " ", "-")})
+	}
+	return htmlReport.Execute(w, data)
+}
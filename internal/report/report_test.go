@@ -0,0 +1,148 @@
+package report
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/user/git-mfpr/internal/migrate"
+)
+
+func testResults() []migrate.MigrationResult {
+	return []migrate.MigrationResult{
+		{
+			Ref:      "123",
+			PR:       &migrate.PRInfo{Number: 123, Title: "Fix | pipes", Author: "alice", URL: "https://github.com/owner/repo/pull/123"},
+			Branch:   "alice/pr-123",
+			Created:  &migrate.PRInfo{Number: 500, URL: "https://github.com/owner/repo/pull/500"},
+			Commits:  3,
+			Pushed:   true,
+			Duration: 2340 * time.Millisecond,
+		},
+		{
+			Ref:      "124",
+			Err:      errors.New("<script>boom</script>"),
+			Duration: 120 * time.Millisecond,
+		},
+	}
+}
+
+func TestFormatFor(t *testing.T) {
+	tests := []struct {
+		path    string
+		want    Format
+		wantErr bool
+	}{
+		{path: "report.md", want: Markdown},
+		{path: "out/REPORT.MARKDOWN", want: Markdown},
+		{path: "report.csv", want: CSV},
+		{path: "report.html", want: HTML},
+		{path: "report.htm", want: HTML},
+		{path: "report.txt", wantErr: true},
+		{path: "report", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := FormatFor(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FormatFor() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("FormatFor() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteFormat_Markdown(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteFormat(&buf, Markdown, testResults()); err != nil {
+		t.Fatalf("WriteFormat() error = %v", err)
+	}
+	got := buf.String()
+
+	for _, want := range []string{
+		"Migrated 1 of 2 PRs.",
+		"| [#123](https://github.com/owner/repo/pull/123) | Fix \\| pipes | @alice | `alice/pr-123` | [#500](https://github.com/owner/repo/pull/500) | 3 | migrated |  | 2.3s |",
+		"| 124 |  |  |  |  | 0 | failed | error | 100ms |",
+		"## Failures\n\n- 124: <script>boom</script>\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("report missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestWriteFormat_CSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteFormat(&buf, CSV, testResults()); err != nil {
+		t.Fatalf("WriteFormat() error = %v", err)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("report is not valid CSV: %v", err)
+	}
+	want := [][]string{
+		{"pr", "url", "title", "author", "branch", "new_pr", "new_pr_url", "commits", "status", "error_class", "error", "duration_seconds"},
+		{"#123", "https://github.com/owner/repo/pull/123", "Fix | pipes", "alice", "alice/pr-123", "#500", "https://github.com/owner/repo/pull/500", "3", "migrated", "", "", "2.3"},
+		{"124", "124", "", "", "", "", "", "0", "failed", "error", "<script>boom</script>", "0.1"},
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("records = %q, want %q", records, want)
+	}
+}
+
+func TestWriteFormat_HTML(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteFormat(&buf, HTML, testResults()); err != nil {
+		t.Fatalf("WriteFormat() error = %v", err)
+	}
+	got := buf.String()
+
+	for _, want := range []string{
+		"<p>Migrated 1 of 2 PRs.</p>",
+		`<a href="https://github.com/owner/repo/pull/123">#123</a>`,
+		`<a href="https://github.com/owner/repo/pull/500">#500</a>`,
+		`<tr class="failed">`,
+		"&lt;script&gt;boom&lt;/script&gt;",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("report missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "<script>") {
+		t.Error("report does not escape error messages")
+	}
+}
+
+func TestWrite(t *testing.T) {
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "report.md")
+	if err := Write(path, testResults()); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("report not written: %v", err)
+	}
+	if !strings.HasPrefix(string(data), "# git-mfpr migration report") {
+		t.Errorf("report = %q, want Markdown", data)
+	}
+
+	path = filepath.Join(dir, "report.pdf")
+	if err := Write(path, testResults()); err == nil {
+		t.Error("Write() with an unsupported extension succeeded")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("Write() created a file for an unsupported format")
+	}
+}
@@ -0,0 +1,159 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// Entry is one recorded command and how it finished.
+type Entry struct {
+	Command  string `json:"command"`
+	Dir      string `json:"dir,omitempty"`
+	Stdout   string `json:"stdout,omitempty"`
+	Stderr   string `json:"stderr,omitempty"`
+	ExitCode int    `json:"exit_code"`
+}
+
+// Recorder runs commands with another Runner and keeps what happened, so the
+// session can be saved and replayed with a Fake.
+type Recorder struct {
+	runner Runner
+
+	mu      sync.Mutex
+	entries []Entry
+}
+
+func NewRecorder(r Runner) *Recorder {
+	return &Recorder{runner: r}
+}
+
+func (r *Recorder) Run(ctx context.Context, cmd Cmd) *Result {
+	result := r.runner.Run(ctx, cmd)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = append(r.entries, Entry{
+		Command:  cmd.String(),
+		Dir:      cmd.Dir,
+		Stdout:   string(result.Stdout),
+		Stderr:   string(result.Stderr),
+		ExitCode: result.ExitCode,
+	})
+	return result
+}
+
+func (r *Recorder) Entries() []Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Entry(nil), r.entries...)
+}
+
+// Save writes the recorded entries to path as JSON.
+func (r *Recorder) Save(path string) error {
+	data, err := json.MarshalIndent(r.Entries(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o600)
+}
+
+// Fake replays recorded entries instead of running anything. Each command is
+// answered by the first unused entry with the same command line (and
+// directory, if the entry has one); once those run out, the last matching
+// entry is reused. Commands with no entry fail.
+type Fake struct {
+	mu      sync.Mutex
+	entries []Entry
+	used    []bool
+	calls   []Cmd
+}
+
+func NewFake(entries ...Entry) *Fake {
+	return &Fake{entries: entries, used: make([]bool, len(entries))}
+}
+
+// LoadFake replays entries saved by Recorder.Save.
+func LoadFake(path string) (*Fake, error) {
+	data, err := os.ReadFile(path) // #nosec G304
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("invalid recording %s: %w", path, err)
+	}
+	return NewFake(entries...), nil
+}
+
+func (f *Fake) Run(ctx context.Context, cmd Cmd) *Result {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls = append(f.calls, cmd)
+	result := &Result{Cmd: cmd, Dir: cmd.Dir}
+
+	if err := ctx.Err(); err != nil {
+		result.ExitCode = -1
+		result.Err = err
+		return result
+	}
+
+	entry, ok := f.match(cmd)
+	if !ok {
+		result.ExitCode = -1
+		result.Err = fmt.Errorf("runner: unexpected command: %s", cmd)
+		return result
+	}
+
+	result.Stdout = []byte(entry.Stdout)
+	result.Stderr = []byte(entry.Stderr)
+	result.ExitCode = entry.ExitCode
+	if entry.ExitCode != 0 {
+		result.Err = fmt.Errorf("exit status %d", entry.ExitCode)
+	}
+	return result
+}
+
+func (f *Fake) match(cmd Cmd) (Entry, bool) {
+	line := cmd.String()
+	last := -1
+	for i, entry := range f.entries {
+		if entry.Command != line || (entry.Dir != "" && entry.Dir != cmd.Dir) {
+			continue
+		}
+		if !f.used[i] {
+			f.used[i] = true
+			return entry, true
+		}
+		last = i
+	}
+	if last < 0 {
+		return Entry{}, false
+	}
+	return f.entries[last], true
+}
+
+// Calls returns every command the fake was asked to run, in order.
+func (f *Fake) Calls() []Cmd {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Cmd(nil), f.calls...)
+}
+
+// Unused returns the entries that no command has matched yet.
+func (f *Fake) Unused() []Entry {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var unused []Entry
+	for i, entry := range f.entries {
+		if !f.used[i] {
+			unused = append(unused, entry)
+		}
+	}
+	return unused
+}
@@ -0,0 +1,107 @@
+package runner
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFake_Run(t *testing.T) {
+	ctx := context.Background()
+	fake := NewFake(
+		Entry{Command: "git rev-parse --abbrev-ref HEAD", Stdout: "main\n"},
+		Entry{Command: "git checkout feature", Stderr: "error: pathspec 'feature' did not match", ExitCode: 1},
+		Entry{Command: "git rev-parse --abbrev-ref HEAD", Stdout: "feature\n"},
+		Entry{Command: "git status", Dir: "/elsewhere", Stdout: "clean\n"},
+	)
+
+	tests := []struct {
+		name       string
+		cmd        Cmd
+		wantStdout string
+		wantExit   int
+		wantErr    string
+	}{
+		{"first match", Cmd{Name: "git", Args: []string{"rev-parse", "--abbrev-ref", "HEAD"}}, "main\n", 0, ""},
+		{"failure", Cmd{Name: "git", Args: []string{"checkout", "feature"}}, "", 1, "exit status 1"},
+		{"next match", Cmd{Name: "git", Args: []string{"rev-parse", "--abbrev-ref", "HEAD"}}, "feature\n", 0, ""},
+		{"last match reused", Cmd{Name: "git", Args: []string{"rev-parse", "--abbrev-ref", "HEAD"}}, "feature\n", 0, ""},
+		{"wrong directory", Cmd{Name: "git", Args: []string{"status"}, Dir: "/repo"}, "", -1, "unexpected command: git status"},
+		{"right directory", Cmd{Name: "git", Args: []string{"status"}, Dir: "/elsewhere"}, "clean\n", 0, ""},
+		{"unexpected", Cmd{Name: "gh", Args: []string{"pr", "view", "1"}}, "", -1, "unexpected command: gh pr view 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := fake.Run(ctx, tt.cmd)
+			if string(result.Stdout) != tt.wantStdout {
+				t.Errorf("Stdout = %q, want %q", result.Stdout, tt.wantStdout)
+			}
+			if result.ExitCode != tt.wantExit {
+				t.Errorf("ExitCode = %d, want %d", result.ExitCode, tt.wantExit)
+			}
+			if tt.wantErr == "" && result.Err != nil {
+				t.Errorf("Err = %v, want nil", result.Err)
+			}
+			if tt.wantErr != "" && (result.Err == nil || !strings.Contains(result.Err.Error(), tt.wantErr)) {
+				t.Errorf("Err = %v, want %q", result.Err, tt.wantErr)
+			}
+		})
+	}
+
+	if got := len(fake.Calls()); got != len(tests) {
+		t.Errorf("Calls() has %d commands, want %d", got, len(tests))
+	}
+	if unused := fake.Unused(); len(unused) != 0 {
+		t.Errorf("Unused() = %v, want none", unused)
+	}
+}
+
+func TestFake_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	fake := NewFake(Entry{Command: "git status"})
+	result := fake.Run(ctx, Cmd{Name: "git", Args: []string{"status"}})
+	if result.Err != context.Canceled {
+		t.Errorf("Err = %v, want context.Canceled", result.Err)
+	}
+	if len(fake.Unused()) != 1 {
+		t.Error("a cancelled command should not consume its entry")
+	}
+}
+
+func TestRecorder_SaveAndReplay(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "session.json")
+
+	recorder := NewRecorder(New())
+	cmds := []Cmd{
+		{Name: "sh", Args: []string{"-c", "echo hello"}, Dir: dir},
+		{Name: "sh", Args: []string{"-c", "echo nope >&2; exit 2"}, Dir: dir},
+	}
+	var live []*Result
+	for _, cmd := range cmds {
+		live = append(live, recorder.Run(ctx, cmd))
+	}
+	if err := recorder.Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	fake, err := LoadFake(path)
+	if err != nil {
+		t.Fatalf("LoadFake() error = %v", err)
+	}
+	for i, cmd := range cmds {
+		replayed := fake.Run(ctx, cmd)
+		if !reflect.DeepEqual(replayed.Stdout, live[i].Stdout) || !reflect.DeepEqual(replayed.Stderr, live[i].Stderr) {
+			t.Errorf("replay of %s = %q/%q, want %q/%q", cmd, replayed.Stdout, replayed.Stderr, live[i].Stdout, live[i].Stderr)
+		}
+		if replayed.ExitCode != live[i].ExitCode || (replayed.Err == nil) != (live[i].Err == nil) {
+			t.Errorf("replay of %s exited %d (%v), want %d (%v)", cmd, replayed.ExitCode, replayed.Err, live[i].ExitCode, live[i].Err)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Cmd is an external command to run. Env holds extra KEY=value pairs added
+// to the current environment.
+type Cmd struct {
+	Name  string
+	Args  []string
+	Dir   string
+	Env   []string
+	Stdin []byte
+}
+
+var shellSafe = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+
+// String renders the command line as it could be typed into a shell.
+func (c Cmd) String() string {
+	parts := []string{c.Name}
+	for _, arg := range c.Args {
+		if !shellSafe.MatchString(arg) {
+			arg = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+		}
+		parts = append(parts, arg)
+	}
+	return strings.Join(parts, " ")
+}
+
+// Result describes a finished command. Err is nil only if it exited 0.
+type Result struct {
+	Cmd      Cmd
+	Dir      string
+	Stdout   []byte
+	Stderr   []byte
+	ExitCode int
+	Duration time.Duration
+	Err      error
+}
+
+// Runner executes external commands.
+type Runner interface {
+	Run(ctx context.Context, cmd Cmd) *Result
+}
+
+// Observer is told about every command after it finishes.
+type Observer func(Result)
+
+// Exec runs commands with os/exec.
+type Exec struct{}
+
+func New() Runner {
+	return &Exec{}
+}
+
+func (e *Exec) Run(ctx context.Context, cmd Cmd) *Result {
+	result := &Result{Cmd: cmd, Dir: cmd.Dir}
+	if result.Dir == "" {
+		result.Dir, _ = os.Getwd()
+	}
+
+	var stdout, stderr bytes.Buffer
+	c := exec.CommandContext(ctx, cmd.Name, cmd.Args...) // #nosec G204
+	c.Dir = cmd.Dir
+	if len(cmd.Env) > 0 {
+		c.Env = append(os.Environ(), cmd.Env...)
+	}
+	c.Stdout = &stdout
+	c.Stderr = &stderr
+	if cmd.Stdin != nil {
+		c.Stdin = bytes.NewReader(cmd.Stdin)
+	}
+
+	start := time.Now()
+	result.Err = c.Run()
+	result.Duration = time.Since(start)
+	result.Stdout = stdout.Bytes()
+	result.Stderr = stderr.Bytes()
+
+	if result.Err != nil {
+		result.ExitCode = -1
+		var exitErr *exec.ExitError
+		if errors.As(result.Err, &exitErr) {
+			result.ExitCode = exitErr.ExitCode()
+		}
+	}
+
+	return result
+}
+
+type observed struct {
+	runner   Runner
+	observer Observer
+}
+
+// Observe returns a Runner that runs commands with r and then reports each
+// one to observer.
+func Observe(r Runner, observer Observer) Runner {
+	return &observed{runner: r, observer: observer}
+}
+
+func (o *observed) Run(ctx context.Context, cmd Cmd) *Result {
+	result := o.runner.Run(ctx, cmd)
+	o.observer(*result)
+	return result
+}
@@ -0,0 +1,86 @@
+package runner
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestCmd_String(t *testing.T) {
+	tests := []struct {
+		name string
+		cmd  Cmd
+		want string
+	}{
+		{"plain", Cmd{Name: "git", Args: []string{"push", "-u", "origin", "migrated-1"}}, "git push -u origin migrated-1"},
+		{"spaces", Cmd{Name: "gh", Args: []string{"pr", "create", "--title", "Fix the bug"}}, "gh pr create --title 'Fix the bug'"},
+		{"quote", Cmd{Name: "gh", Args: []string{"--body", "it's done"}}, `gh --body 'it'\''s done'`},
+		{"format string", Cmd{Name: "git", Args: []string{"for-each-ref", "--format=%(refname)"}}, "git for-each-ref '--format=%(refname)'"},
+		{"empty", Cmd{Name: "git", Args: []string{"commit", "-m", ""}}, "git commit -m ''"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.cmd.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExec_Run(t *testing.T) {
+	var observed []Result
+	r := Observe(New(), func(result Result) {
+		observed = append(observed, result)
+	})
+
+	dir := t.TempDir()
+	result := r.Run(context.Background(), Cmd{
+		Name:  "sh",
+		Args:  []string{"-c", "pwd; cat; echo $MFPR_TEST; echo oops >&2"},
+		Dir:   dir,
+		Env:   []string{"MFPR_TEST=from-env"},
+		Stdin: []byte("input\n"),
+	})
+	if result.Err != nil {
+		t.Fatalf("Run() error = %v", result.Err)
+	}
+	if result.ExitCode != 0 {
+		t.Errorf("ExitCode = %d, want 0", result.ExitCode)
+	}
+	if got := string(result.Stdout); !strings.HasSuffix(got, "input\nfrom-env\n") || !strings.Contains(got, dir) {
+		t.Errorf("Stdout = %q, want the directory, stdin and environment echoed", got)
+	}
+	if string(result.Stderr) != "oops\n" {
+		t.Errorf("Stderr = %q, want %q", result.Stderr, "oops\n")
+	}
+	if result.Dir != dir {
+		t.Errorf("Dir = %q, want %q", result.Dir, dir)
+	}
+
+	result = r.Run(context.Background(), Cmd{Name: "sh", Args: []string{"-c", "exit 3"}})
+	if result.Err == nil || result.ExitCode != 3 {
+		t.Errorf("Run() = exit %d, err %v; want exit 3 with an error", result.ExitCode, result.Err)
+	}
+	if wd, _ := os.Getwd(); result.Dir != wd {
+		t.Errorf("Dir = %q, want the working directory %q", result.Dir, wd)
+	}
+
+	if len(observed) != 2 {
+		t.Fatalf("observer saw %d commands, want 2", len(observed))
+	}
+	if observed[1].ExitCode != 3 {
+		t.Errorf("observed ExitCode = %d, want 3", observed[1].ExitCode)
+	}
+}
+
+func TestExec_RunMissingCommand(t *testing.T) {
+	result := New().Run(context.Background(), Cmd{Name: "definitely-not-a-command-mfpr"})
+	if result.Err == nil {
+		t.Fatal("Run() expected an error for a missing command")
+	}
+	if result.ExitCode != -1 {
+		t.Errorf("ExitCode = %d, want -1", result.ExitCode)
+	}
+}
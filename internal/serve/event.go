@@ -0,0 +1,138 @@
+package serve
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Job is a request to migrate one PR, taken from a webhook.
+type Job struct {
+	Owner  string
+	Repo   string
+	Number int
+	// Trigger says what asked for the migration, such as "/migrate comment".
+	Trigger string
+	// Requester is the login of whoever commented or added the label.
+	Requester string
+}
+
+func (j Job) Ref() string {
+	return fmt.Sprintf("%s/%s#%d", j.Owner, j.Repo, j.Number)
+}
+
+// VerifySignature checks the X-Hub-Signature-256 header GitHub sends with
+// every delivery against an HMAC of body keyed with secret.
+func VerifySignature(secret, body []byte, signature string) bool {
+	hexSum, ok := strings.CutPrefix(signature, "sha256=")
+	if !ok {
+		return false
+	}
+	got, err := hex.DecodeString(hexSum)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return hmac.Equal(got, mac.Sum(nil))
+}
+
+// Sign returns the X-Hub-Signature-256 header value for body, for sending
+// recorded payloads to a local server.
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// trustedAssociations are the author associations allowed to request a
+// migration by comment. Anyone who can add a label already has triage access.
+var trustedAssociations = map[string]bool{
+	"OWNER":        true,
+	"MEMBER":       true,
+	"COLLABORATOR": true,
+}
+
+type payload struct {
+	Action string `json:"action"`
+	Issue  *struct {
+		Number      int       `json:"number"`
+		PullRequest *struct{} `json:"pull_request"`
+	} `json:"issue"`
+	PullRequest *struct {
+		Number int `json:"number"`
+	} `json:"pull_request"`
+	Comment *struct {
+		Body              string `json:"body"`
+		AuthorAssociation string `json:"author_association"`
+	} `json:"comment"`
+	Label *struct {
+		Name string `json:"name"`
+	} `json:"label"`
+	Repository struct {
+		Name  string `json:"name"`
+		Owner struct {
+			Login string `json:"login"`
+		} `json:"owner"`
+	} `json:"repository"`
+	Sender struct {
+		Login string `json:"login"`
+	} `json:"sender"`
+}
+
+// ParseEvent turns a webhook delivery of type event (the X-GitHub-Event
+// header) into a Job. It returns nil without an error for deliveries that
+// don't ask for a migration: other events, comments without command, labels
+// other than label, and commands from people without write access.
+func ParseEvent(event string, body []byte, command, label string) (*Job, error) {
+	var p payload
+	if err := json.Unmarshal(body, &p); err != nil {
+		return nil, fmt.Errorf("invalid %s payload: %w", event, err)
+	}
+
+	job := &Job{
+		Owner:     p.Repository.Owner.Login,
+		Repo:      p.Repository.Name,
+		Requester: p.Sender.Login,
+	}
+
+	switch event {
+	case "issue_comment":
+		if p.Action != "created" || p.Issue == nil || p.Issue.PullRequest == nil || p.Comment == nil {
+			return nil, nil
+		}
+		if !hasCommand(p.Comment.Body, command) || !trustedAssociations[p.Comment.AuthorAssociation] {
+			return nil, nil
+		}
+		job.Number = p.Issue.Number
+		job.Trigger = command + " comment"
+	case "pull_request":
+		if p.Action != "labeled" || p.PullRequest == nil || p.Label == nil || p.Label.Name != label {
+			return nil, nil
+		}
+		job.Number = p.PullRequest.Number
+		job.Trigger = fmt.Sprintf("%s label", label)
+	default:
+		return nil, nil
+	}
+
+	if job.Owner == "" || job.Repo == "" || job.Number == 0 {
+		return nil, fmt.Errorf("invalid %s payload: missing repository or PR number", event)
+	}
+	return job, nil
+}
+
+// hasCommand reports whether a line of body is command, alone or followed by
+// other words.
+func hasCommand(body, command string) bool {
+	for _, line := range strings.Split(body, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) > 0 && fields[0] == command {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,125 @@
+package serve
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func loadPayload(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestVerifySignature(t *testing.T) {
+	secret := []byte("s3cret")
+	body := []byte(`{"action":"created"}`)
+	signature := Sign(secret, body)
+
+	tests := []struct {
+		name      string
+		secret    []byte
+		body      []byte
+		signature string
+		want      bool
+	}{
+		{name: "valid", secret: secret, body: body, signature: signature, want: true},
+		{name: "wrong secret", secret: []byte("other"), body: body, signature: signature},
+		{name: "tampered body", secret: secret, body: []byte(`{"action":"deleted"}`), signature: signature},
+		{name: "missing", secret: secret, body: body},
+		{name: "sha1", secret: secret, body: body, signature: "sha1=" + signature[len("sha256="):]},
+		{name: "not hex", secret: secret, body: body, signature: "sha256=zz"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := VerifySignature(tt.secret, tt.body, tt.signature); got != tt.want {
+				t.Errorf("VerifySignature() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseEvent(t *testing.T) {
+	tests := []struct {
+		name    string
+		event   string
+		body    string
+		want    *Job
+		wantErr bool
+	}{
+		{
+			name:  "recorded comment",
+			event: "issue_comment",
+			body:  string(loadPayload(t, "issue_comment.json")),
+			want:  &Job{Owner: "acme", Repo: "widgets", Number: 42, Trigger: "/migrate comment", Requester: "octocat"},
+		},
+		{
+			name:  "recorded label",
+			event: "pull_request",
+			body:  string(loadPayload(t, "pull_request_labeled.json")),
+			want:  &Job{Owner: "acme", Repo: "widgets", Number: 42, Trigger: "migrate label", Requester: "octocat"},
+		},
+		{
+			name:  "comment without command",
+			event: "issue_comment",
+			body:  `{"action":"created","issue":{"number":1,"pull_request":{}},"comment":{"body":"please /migrate","author_association":"OWNER"},"repository":{"name":"r","owner":{"login":"o"}}}`,
+		},
+		{
+			name:  "command from outsider",
+			event: "issue_comment",
+			body:  `{"action":"created","issue":{"number":1,"pull_request":{}},"comment":{"body":"/migrate","author_association":"CONTRIBUTOR"},"repository":{"name":"r","owner":{"login":"o"}}}`,
+		},
+		{
+			name:  "command on issue",
+			event: "issue_comment",
+			body:  `{"action":"created","issue":{"number":1},"comment":{"body":"/migrate","author_association":"OWNER"},"repository":{"name":"r","owner":{"login":"o"}}}`,
+		},
+		{
+			name:  "edited comment",
+			event: "issue_comment",
+			body:  `{"action":"edited","issue":{"number":1,"pull_request":{}},"comment":{"body":"/migrate","author_association":"OWNER"},"repository":{"name":"r","owner":{"login":"o"}}}`,
+		},
+		{
+			name:  "other label",
+			event: "pull_request",
+			body:  `{"action":"labeled","pull_request":{"number":1},"label":{"name":"bug"},"repository":{"name":"r","owner":{"login":"o"}}}`,
+		},
+		{
+			name:  "other event",
+			event: "push",
+			body:  `{"ref":"refs/heads/main"}`,
+		},
+		{
+			name:    "invalid JSON",
+			event:   "issue_comment",
+			body:    `{`,
+			wantErr: true,
+		},
+		{
+			name:    "missing repository",
+			event:   "pull_request",
+			body:    `{"action":"labeled","pull_request":{"number":1},"label":{"name":"migrate"}}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseEvent(tt.event, []byte(tt.body), DefaultCommand, DefaultLabel)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseEvent() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			switch {
+			case tt.want == nil && got != nil:
+				t.Errorf("ParseEvent() = %+v, want nil", got)
+			case tt.want != nil && (got == nil || *got != *tt.want):
+				t.Errorf("ParseEvent() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,295 @@
+// Package serve runs git-mfpr as a webhook service: maintainers comment a
+// command or add a label on a fork PR, and the service migrates it in a
+// fresh clone and reports back on the PR.
+package serve
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/user/git-mfpr/internal/github"
+	"github.com/user/git-mfpr/internal/migrate"
+	"github.com/user/git-mfpr/internal/runner"
+)
+
+const (
+	DefaultCommand = "/migrate"
+	DefaultLabel   = "migrate"
+
+	maxPayload = 25 << 20
+	queueSize  = 100
+)
+
+type Config struct {
+	// Secret is the webhook secret deliveries are signed with.
+	Secret []byte
+	// Command is the comment that asks for a migration (default "/migrate").
+	Command string
+	// Label is the label that asks for a migration (default "migrate").
+	Label string
+	// Workers is how many migrations run at once (default 1).
+	Workers int
+	// WorkDir holds a fresh clone for each job (default a temporary
+	// directory).
+	WorkDir string
+	// Options are passed to every MigratePR.
+	Options migrate.Options
+	Logger  *log.Logger
+
+	// Runner clones repositories; GitHub posts the result comments.
+	Runner runner.Runner
+	GitHub github.GitHub
+	// NewMigrator returns the migrator for a clone in dir.
+	NewMigrator func(dir string) migrate.Migrator
+}
+
+type Server struct {
+	config Config
+	queue  chan Job
+
+	mu      sync.Mutex
+	pending map[string]bool
+}
+
+func New(config Config) *Server {
+	if config.Command == "" {
+		config.Command = DefaultCommand
+	}
+	if config.Label == "" {
+		config.Label = DefaultLabel
+	}
+	if config.Workers < 1 {
+		config.Workers = 1
+	}
+	if config.WorkDir == "" {
+		config.WorkDir = filepath.Join(os.TempDir(), "git-mfpr-serve")
+	}
+	if config.Logger == nil {
+		config.Logger = log.New(os.Stderr, "", log.LstdFlags)
+	}
+	if config.Runner == nil {
+		config.Runner = runner.New()
+	}
+	if config.GitHub == nil {
+		config.GitHub = github.New()
+	}
+	if config.NewMigrator == nil {
+		config.NewMigrator = func(dir string) migrate.Migrator {
+			return migrate.NewWithOptions(migrate.WithDir(dir))
+		}
+	}
+
+	return &Server{
+		config:  config,
+		queue:   make(chan Job, queueSize),
+		pending: map[string]bool{},
+	}
+}
+
+// ServeHTTP accepts webhook deliveries, queueing a job for each one that asks
+// for a migration.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxPayload))
+	if err != nil {
+		http.Error(w, "cannot read payload", http.StatusBadRequest)
+		return
+	}
+	if !VerifySignature(s.config.Secret, body, r.Header.Get("X-Hub-Signature-256")) {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	event := r.Header.Get("X-GitHub-Event")
+	if event == "ping" {
+		fmt.Fprintln(w, "pong")
+		return
+	}
+	job, err := ParseEvent(event, body, s.config.Command, s.config.Label)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if job == nil {
+		fmt.Fprintln(w, "ignored")
+		return
+	}
+
+	switch s.Enqueue(*job) {
+	case nil:
+		w.WriteHeader(http.StatusAccepted)
+		fmt.Fprintf(w, "queued %s\n", job.Ref())
+	case errAlreadyQueued:
+		fmt.Fprintf(w, "%s is already queued\n", job.Ref())
+	default:
+		http.Error(w, "queue full", http.StatusServiceUnavailable)
+	}
+}
+
+var (
+	errAlreadyQueued = errors.New("already queued")
+	errQueueFull     = errors.New("queue full")
+)
+
+// Enqueue adds job to the queue unless the same PR is already waiting or
+// being migrated.
+func (s *Server) Enqueue(job Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.pending[job.Ref()] {
+		return errAlreadyQueued
+	}
+	select {
+	case s.queue <- job:
+		s.pending[job.Ref()] = true
+		s.config.Logger.Printf("queued %s (%s by @%s)", job.Ref(), job.Trigger, job.Requester)
+		return nil
+	default:
+		return errQueueFull
+	}
+}
+
+// Run works through the queue with Config.Workers workers until ctx is
+// done, then waits for the jobs in progress to finish and abandons the rest.
+func (s *Server) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < s.config.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				select {
+				case <-ctx.Done():
+					return
+				case job := <-s.queue:
+					s.Process(ctx, job)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	s.abandonQueued()
+}
+
+// abandonQueued empties the queue on shutdown. The jobs are logged rather
+// than started, as their migrations could not finish; asking again on the PR
+// queues them on the next run.
+func (s *Server) abandonQueued() {
+	for {
+		select {
+		case job := <-s.queue:
+			s.mu.Lock()
+			delete(s.pending, job.Ref())
+			s.mu.Unlock()
+			s.config.Logger.Printf("abandoned %s: shutting down before it started", job.Ref())
+		default:
+			return
+		}
+	}
+}
+
+// Process migrates job's PR in a fresh clone and comments the outcome on the
+// PR.
+func (s *Server) Process(ctx context.Context, job Job) *migrate.MigrationResult {
+	defer func() {
+		s.mu.Lock()
+		delete(s.pending, job.Ref())
+		s.mu.Unlock()
+	}()
+
+	s.config.Logger.Printf("migrating %s", job.Ref())
+	result := s.migrate(ctx, job)
+	if result.Err != nil {
+		s.config.Logger.Printf("%s failed: %v", job.Ref(), result.Err)
+	} else {
+		s.config.Logger.Printf("%s: %s", job.Ref(), result.Status())
+	}
+
+	if err := s.config.GitHub.CommentPR(ctx, job.Owner, job.Repo, job.Number, Comment(job, result)); err != nil {
+		s.config.Logger.Printf("commenting on %s: %v", job.Ref(), err)
+	}
+	return result
+}
+
+func (s *Server) migrate(ctx context.Context, job Job) *migrate.MigrationResult {
+	result := &migrate.MigrationResult{Ref: job.Ref()}
+
+	if err := os.MkdirAll(s.config.WorkDir, 0o750); err != nil {
+		result.Err = err
+		return result
+	}
+	dir, err := os.MkdirTemp(s.config.WorkDir, fmt.Sprintf("%s-%s-%d-", job.Owner, job.Repo, job.Number))
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	defer os.RemoveAll(dir)
+
+	clone := s.config.Runner.Run(ctx, runner.Cmd{
+		Name: "gh",
+		Args: []string{"repo", "clone", job.Owner + "/" + job.Repo, dir, "--", "--quiet"},
+	})
+	if clone.Err != nil {
+		result.Err = fmt.Errorf("cloning %s/%s: %s", job.Owner, job.Repo, cloneDetail(clone))
+		return result
+	}
+
+	migrator := s.config.NewMigrator(dir)
+	migrator.SetConfirmHandler(func(string) bool { return true })
+	migrator.SetEventHandler(func(event migrate.Event) {
+		if event.Type == migrate.EventResult {
+			result = event.Result
+		}
+	})
+
+	if err := migrator.MigratePR(ctx, job.Ref(), s.config.Options); err != nil && result.Err == nil {
+		result.Err = err
+	}
+	return result
+}
+
+func cloneDetail(result *runner.Result) string {
+	if detail := strings.TrimSpace(string(result.Stderr)); detail != "" {
+		return detail
+	}
+	return result.Err.Error()
+}
+
+// Comment is the PR comment reporting how job went.
+func Comment(job Job, result *migrate.MigrationResult) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Migration requested by @%s (%s):\n\n", job.Requester, job.Trigger)
+
+	switch result.Status() {
+	case migrate.StatusFailed:
+		fmt.Fprintf(&b, "❌ Could not migrate this PR: %v\n", result.Err)
+		return b.String()
+	case migrate.StatusConflicts:
+		fmt.Fprintf(&b, "⚠️ %d hunks could not be applied, so `%s` was not pushed. Migrate it locally with `git mfpr %s`.\n",
+			result.Rejects, result.Branch, job.Ref())
+		return b.String()
+	}
+
+	fmt.Fprintf(&b, "✅ Migrated %d commits to `%s`", result.Commits, result.Branch)
+	if result.SHA != "" {
+		fmt.Fprintf(&b, " at %.7s", result.SHA)
+	}
+	b.WriteString(".\n")
+	if result.Created != nil {
+		fmt.Fprintf(&b, "\nContinued in #%d.\n", result.Created.Number)
+	}
+	return b.String()
+}
@@ -0,0 +1,322 @@
+package serve
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/user/git-mfpr/internal/github"
+	"github.com/user/git-mfpr/internal/migrate"
+	"github.com/user/git-mfpr/internal/runner"
+)
+
+var testSecret = []byte("s3cret")
+
+type mockRunner struct {
+	mu    sync.Mutex
+	calls []runner.Cmd
+	err   error
+}
+
+func (m *mockRunner) Run(_ context.Context, cmd runner.Cmd) *runner.Result {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, cmd)
+	result := &runner.Result{Cmd: cmd, Err: m.err}
+	if m.err != nil {
+		result.ExitCode = 1
+		result.Stderr = []byte("GraphQL: Could not resolve to a Repository\n")
+	}
+	return result
+}
+
+type comment struct {
+	owner, repo string
+	number      int
+	body        string
+}
+
+type mockGitHub struct {
+	github.GitHub
+
+	mu       sync.Mutex
+	comments []comment
+}
+
+func (m *mockGitHub) CommentPR(_ context.Context, owner, repo string, number int, body string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.comments = append(m.comments, comment{owner, repo, number, body})
+	return nil
+}
+
+type mockMigrator struct {
+	migrate.Migrator
+
+	dir     string
+	result  *migrate.MigrationResult
+	handler migrate.EventHandler
+	confirm migrate.ConfirmHandler
+}
+
+func (m *mockMigrator) SetEventHandler(handler migrate.EventHandler) { m.handler = handler }
+
+func (m *mockMigrator) SetConfirmHandler(handler migrate.ConfirmHandler) { m.confirm = handler }
+
+func (m *mockMigrator) MigratePR(_ context.Context, prRef string, _ migrate.Options) error {
+	if !m.confirm("Continue?") {
+		return errors.New("not confirmed")
+	}
+	result := *m.result
+	result.Ref = prRef
+	m.handler(migrate.Event{Type: migrate.EventResult, Message: prRef, Result: &result})
+	return result.Err
+}
+
+func newTestServer(t *testing.T, result *migrate.MigrationResult) (*Server, *mockRunner, *mockGitHub, *mockMigrator) {
+	t.Helper()
+	r := &mockRunner{}
+	gh := &mockGitHub{}
+	m := &mockMigrator{result: result}
+	s := New(Config{
+		Secret:  testSecret,
+		WorkDir: t.TempDir(),
+		Logger:  log.New(io.Discard, "", 0),
+		Runner:  r,
+		GitHub:  gh,
+		NewMigrator: func(dir string) migrate.Migrator {
+			m.dir = dir
+			return m
+		},
+	})
+	return s, r, gh, m
+}
+
+func TestServer_ServeHTTP(t *testing.T) {
+	comment := loadPayload(t, "issue_comment.json")
+
+	tests := []struct {
+		name       string
+		method     string
+		event      string
+		body       []byte
+		signature  string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "queued", event: "issue_comment", body: comment, wantStatus: http.StatusAccepted, wantBody: "queued acme/widgets#42"},
+		{name: "bad signature", event: "issue_comment", body: comment, signature: "sha256=00", wantStatus: http.StatusUnauthorized},
+		{name: "ping", event: "ping", body: []byte(`{}`), wantStatus: http.StatusOK, wantBody: "pong"},
+		{name: "ignored", event: "push", body: []byte(`{}`), wantStatus: http.StatusOK, wantBody: "ignored"},
+		{name: "bad payload", event: "issue_comment", body: []byte(`{`), wantStatus: http.StatusBadRequest},
+		{name: "GET", method: http.MethodGet, wantStatus: http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, _, _ := newTestServer(t, &migrate.MigrationResult{})
+
+			method := tt.method
+			if method == "" {
+				method = http.MethodPost
+			}
+			signature := tt.signature
+			if signature == "" {
+				signature = Sign(testSecret, tt.body)
+			}
+			req := httptest.NewRequest(method, "/", bytes.NewReader(tt.body))
+			req.Header.Set("X-GitHub-Event", tt.event)
+			req.Header.Set("X-Hub-Signature-256", signature)
+			rec := httptest.NewRecorder()
+
+			s.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if !strings.Contains(rec.Body.String(), tt.wantBody) {
+				t.Errorf("body = %q, want it to contain %q", rec.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestServer_Enqueue(t *testing.T) {
+	s, _, _, _ := newTestServer(t, &migrate.MigrationResult{})
+	job := Job{Owner: "acme", Repo: "widgets", Number: 42}
+
+	if err := s.Enqueue(job); err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+	if err := s.Enqueue(job); !errors.Is(err, errAlreadyQueued) {
+		t.Errorf("Enqueue() of a queued PR error = %v, want %v", err, errAlreadyQueued)
+	}
+
+	job.Number = 43
+	for i := 1; i < queueSize; i++ {
+		job.Number++
+		if err := s.Enqueue(job); err != nil {
+			t.Fatalf("Enqueue() #%d error = %v", i, err)
+		}
+	}
+	job.Number++
+	if err := s.Enqueue(job); !errors.Is(err, errQueueFull) {
+		t.Errorf("Enqueue() on a full queue error = %v, want %v", err, errQueueFull)
+	}
+}
+
+func TestServer_Process(t *testing.T) {
+	job := Job{Owner: "acme", Repo: "widgets", Number: 42, Trigger: "/migrate comment", Requester: "octocat"}
+
+	t.Run("migrated", func(t *testing.T) {
+		s, r, gh, m := newTestServer(t, &migrate.MigrationResult{
+			Branch:  "migrated-42-retry",
+			Commits: 3,
+			Pushed:  true,
+			SHA:     "0123456789abcdef",
+			Created: &migrate.PRInfo{Number: 57},
+		})
+		if err := s.Enqueue(job); err != nil {
+			t.Fatal(err)
+		}
+
+		result := s.Process(context.Background(), job)
+
+		if result.Err != nil {
+			t.Fatalf("Process() error = %v", result.Err)
+		}
+		if len(r.calls) != 1 {
+			t.Fatalf("ran %d commands, want 1", len(r.calls))
+		}
+		want := "gh repo clone acme/widgets " + m.dir + " -- --quiet"
+		if got := r.calls[0].String(); got != want {
+			t.Errorf("clone = %q, want %q", got, want)
+		}
+		if len(gh.comments) != 1 || gh.comments[0].number != 42 {
+			t.Fatalf("comments = %+v, want one on #42", gh.comments)
+		}
+		for _, want := range []string{"@octocat", "migrated-42-retry", "0123456", "#57"} {
+			if !strings.Contains(gh.comments[0].body, want) {
+				t.Errorf("comment %q does not mention %q", gh.comments[0].body, want)
+			}
+		}
+		if err := s.Enqueue(job); err != nil {
+			t.Errorf("Enqueue() after Process error = %v", err)
+		}
+	})
+
+	t.Run("clone failed", func(t *testing.T) {
+		s, r, gh, _ := newTestServer(t, &migrate.MigrationResult{})
+		r.err = errors.New("exit status 1")
+
+		result := s.Process(context.Background(), job)
+
+		if result.Err == nil || !strings.Contains(result.Err.Error(), "Could not resolve") {
+			t.Errorf("Process() error = %v, want the clone error", result.Err)
+		}
+		if len(gh.comments) != 1 || !strings.Contains(gh.comments[0].body, "Could not migrate") {
+			t.Errorf("comments = %+v, want one reporting the failure", gh.comments)
+		}
+	})
+}
+
+func TestServer_Run(t *testing.T) {
+	s, _, gh, _ := newTestServer(t, &migrate.MigrationResult{Branch: "b", Pushed: true})
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx)
+		close(done)
+	}()
+
+	if err := s.Enqueue(Job{Owner: "acme", Repo: "widgets", Number: 42}); err != nil {
+		t.Fatal(err)
+	}
+	for {
+		gh.mu.Lock()
+		n := len(gh.comments)
+		gh.mu.Unlock()
+		if n == 1 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	<-done
+}
+
+func TestServer_Run_AbandonsQueuedJobs(t *testing.T) {
+	s, _, gh, _ := newTestServer(t, &migrate.MigrationResult{Branch: "b", Pushed: true})
+	var logs strings.Builder
+	s.config.Logger = log.New(&logs, "", 0)
+
+	jobs := []Job{{Owner: "acme", Repo: "widgets", Number: 42}, {Owner: "acme", Repo: "widgets", Number: 43}}
+	for _, job := range jobs {
+		if err := s.Enqueue(job); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	s.Run(ctx)
+
+	if len(gh.comments) != 0 {
+		t.Errorf("comments = %+v, want none after shutdown", gh.comments)
+	}
+	for _, job := range jobs {
+		if want := "abandoned " + job.Ref(); !strings.Contains(logs.String(), want) {
+			t.Errorf("log %q does not mention %q", logs.String(), want)
+		}
+		if err := s.Enqueue(job); err != nil {
+			t.Errorf("Enqueue() of an abandoned job error = %v", err)
+		}
+	}
+}
+
+func TestComment(t *testing.T) {
+	job := Job{Owner: "acme", Repo: "widgets", Number: 42, Trigger: "migrate label", Requester: "octocat"}
+
+	tests := []struct {
+		name   string
+		result *migrate.MigrationResult
+		want   string
+	}{
+		{
+			name:   "failed",
+			result: &migrate.MigrationResult{Err: errors.New("PR #42 is not from a fork")},
+			want:   "❌ Could not migrate this PR: PR #42 is not from a fork",
+		},
+		{
+			name:   "conflicts",
+			result: &migrate.MigrationResult{Branch: "b", Rejects: 2},
+			want:   "⚠️ 2 hunks could not be applied, so `b` was not pushed. Migrate it locally with `git mfpr acme/widgets#42`.",
+		},
+		{
+			name:   "migrated",
+			result: &migrate.MigrationResult{Branch: "b", Commits: 1, Pushed: true, SHA: "abcdef0123"},
+			want:   "✅ Migrated 1 commits to `b` at abcdef0.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Comment(job, tt.result)
+			if !strings.HasPrefix(got, "Migration requested by @octocat (migrate label):") {
+				t.Errorf("Comment() = %q, want it to name the requester", got)
+			}
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("Comment() = %q, want it to contain %q", got, tt.want)
+			}
+		})
+	}
+}
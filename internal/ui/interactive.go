@@ -0,0 +1,298 @@
+package ui
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/user/git-mfpr/internal/migrate"
+)
+
+const pickerHelp = "Toggle with numbers or ranges (1 3 5-7), /text to filter, a=all, n=none, enter=migrate, q=quit"
+
+type prProgress struct {
+	ref string
+	err error
+}
+
+// InteractiveUI lets the user pick PRs from a filterable numbered list, by
+// typing list numbers at a line prompt, and then shows per-PR progress while
+// they migrate.
+type InteractiveUI struct {
+	in        *bufio.Reader
+	out       io.Writer
+	dryRun    bool
+	assumeYes bool
+	verbose   bool
+	now       func() time.Time
+
+	total    int
+	progress []*prProgress
+}
+
+func NewInteractive(in io.Reader, out io.Writer, dryRun bool) *InteractiveUI {
+	return &InteractiveUI{
+		in:     bufio.NewReader(in),
+		out:    out,
+		dryRun: dryRun,
+		now:    time.Now,
+	}
+}
+
+// Pick shows prs and returns the ones the user selected, in list order. It
+// returns nil if the user quits or input ends before confirming.
+func (ui *InteractiveUI) Pick(prs []migrate.PRInfo) ([]migrate.PRInfo, error) {
+	selected := make(map[int]bool)
+	filter := ""
+
+	for {
+		visible := filterPRs(prs, filter)
+		ui.renderPicker(visible, selected, filter, len(prs))
+
+		fmt.Fprint(ui.out, "> ")
+		line, err := ui.in.ReadString('\n')
+		if err != nil && line == "" {
+			if err == io.EOF {
+				return nil, nil
+			}
+			return nil, err
+		}
+		line = strings.TrimSpace(line)
+
+		switch {
+		case line == "":
+			return selectedPRs(prs, selected), nil
+		case line == "q":
+			return nil, nil
+		case line == "a":
+			for _, pr := range visible {
+				selected[pr.Number] = true
+			}
+		case line == "n":
+			selected = make(map[int]bool)
+		case strings.HasPrefix(line, "/"):
+			filter = strings.TrimSpace(strings.TrimPrefix(line, "/"))
+		default:
+			indexes, err := parseSelection(line, len(visible))
+			if err != nil {
+				fmt.Fprintf(ui.out, "⚠️  %v\n", err)
+				continue
+			}
+			for _, i := range indexes {
+				number := visible[i].Number
+				if selected[number] {
+					delete(selected, number)
+				} else {
+					selected[number] = true
+				}
+			}
+		}
+	}
+}
+
+func (ui *InteractiveUI) renderPicker(visible []migrate.PRInfo, selected map[int]bool, filter string, total int) {
+	fmt.Fprintln(ui.out)
+	if filter != "" {
+		fmt.Fprintf(ui.out, "Open fork PRs matching %q (%d of %d):\n", filter, len(visible), total)
+	} else {
+		fmt.Fprintf(ui.out, "Open fork PRs (%d):\n", total)
+	}
+
+	for i := range visible {
+		pr := &visible[i]
+		mark := " "
+		if selected[pr.Number] {
+			mark = "x"
+		}
+		line := fmt.Sprintf("[%s] %3d. #%-5d %-50s @%-15s %4s %s",
+			mark, i+1, pr.Number, truncate(pr.Title, 50), pr.Author, formatAge(ui.now().Sub(pr.CreatedAt)), formatCheckStatus(pr.CheckStatus))
+		if len(pr.Labels) > 0 {
+			line += " [" + strings.Join(pr.Labels, ", ") + "]"
+		}
+		fmt.Fprintln(ui.out, strings.TrimRight(line, " "))
+	}
+
+	fmt.Fprintf(ui.out, "%d selected. %s\n", len(selected), pickerHelp)
+}
+
+func filterPRs(prs []migrate.PRInfo, filter string) []migrate.PRInfo {
+	if filter == "" {
+		return prs
+	}
+
+	filter = strings.ToLower(filter)
+	var visible []migrate.PRInfo
+	for _, pr := range prs {
+		haystack := strings.ToLower(fmt.Sprintf("#%d %s @%s %s", pr.Number, pr.Title, pr.Author, strings.Join(pr.Labels, " ")))
+		if strings.Contains(haystack, filter) {
+			visible = append(visible, pr)
+		}
+	}
+	return visible
+}
+
+func selectedPRs(prs []migrate.PRInfo, selected map[int]bool) []migrate.PRInfo {
+	var picked []migrate.PRInfo
+	for _, pr := range prs {
+		if selected[pr.Number] {
+			picked = append(picked, pr)
+		}
+	}
+	return picked
+}
+
+// parseSelection turns "1 3 5-7" into zero-based indexes into a list of n items.
+func parseSelection(input string, n int) ([]int, error) {
+	var indexes []int
+	for _, field := range strings.FieldsFunc(input, func(r rune) bool { return r == ' ' || r == ',' }) {
+		first, last, isRange := strings.Cut(field, "-")
+		start, err := strconv.Atoi(first)
+		if err != nil {
+			return nil, fmt.Errorf("invalid selection %q", field)
+		}
+		end := start
+		if isRange {
+			if end, err = strconv.Atoi(last); err != nil {
+				return nil, fmt.Errorf("invalid selection %q", field)
+			}
+		}
+		if start < 1 || end > n || start > end {
+			return nil, fmt.Errorf("selection %q is out of range 1-%d", field, n)
+		}
+		for i := start; i <= end; i++ {
+			indexes = append(indexes, i-1)
+		}
+	}
+	sort.Ints(indexes)
+	return indexes, nil
+}
+
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
+
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
+
+func formatCheckStatus(status string) string {
+	switch status {
+	case "SUCCESS":
+		return "✅"
+	case "FAILURE":
+		return "❌"
+	case "PENDING":
+		return "⏳"
+	default:
+		return "–"
+	}
+}
+
+// SetAssumeYes answers every confirmation with yes without asking.
+func (ui *InteractiveUI) SetAssumeYes(yes bool) {
+	ui.assumeYes = yes
+}
+
+// SetVerbose shows the full output of failed git commands alongside errors.
+func (ui *InteractiveUI) SetVerbose(verbose bool) {
+	ui.verbose = verbose
+}
+
+func (ui *InteractiveUI) Confirm(prompt string) bool {
+	if ui.assumeYes {
+		fmt.Fprintf(ui.out, "  │ ❓ %s yes (--yes)\n", prompt)
+		return true
+	}
+
+	fmt.Fprintf(ui.out, "  │ ❓ %s [y/N] ", prompt)
+	return readYes(ui.in)
+}
+
+// SetTotal sets the number of PRs the progress view counts towards.
+func (ui *InteractiveUI) SetTotal(total int) {
+	ui.total = total
+}
+
+func (ui *InteractiveUI) StartPR(prRef string) {
+	ui.progress = append(ui.progress, &prProgress{ref: prRef})
+	fmt.Fprintf(ui.out, "\n[%d/%d] 🔄 Migrating PR %s...\n", len(ui.progress), ui.total, prRef)
+}
+
+func (ui *InteractiveUI) HandleEvent(event migrate.Event) {
+	switch event.Type {
+	case migrate.EventInfo:
+		if event.Message != "" {
+			ui.Info(event.Message)
+		}
+	case migrate.EventSuccess:
+		ui.Success(event.Message)
+	case migrate.EventError:
+		fmt.Fprintf(ui.out, "  │ ❌ %s\n", errorMessage(event))
+	case migrate.EventCommand:
+		ui.Command(event.Detail)
+	case migrate.EventExec:
+		fmt.Fprint(ui.out, FormatExec(event.Exec, "  │ "))
+	default:
+		// handle unknown event types if needed
+	}
+}
+
+func (ui *InteractiveUI) Error(err error) {
+	if len(ui.progress) > 0 {
+		ui.progress[len(ui.progress)-1].err = err
+	}
+	fmt.Fprintf(ui.out, "  │ ❌ Error: %v\n", err)
+	if ui.verbose {
+		fmt.Fprint(ui.out, FormatCommandOutput(err, "  │    "))
+	}
+}
+
+func (ui *InteractiveUI) Success(message string) {
+	fmt.Fprintf(ui.out, "  │ ✅ %s\n", message)
+}
+
+func (ui *InteractiveUI) Info(message string) {
+	fmt.Fprintf(ui.out, "  │ %s\n", message)
+}
+
+func (ui *InteractiveUI) Command(cmd string) {
+	if ui.dryRun {
+		fmt.Fprintf(ui.out, "  │ $ %s (dry-run)\n", cmd)
+	} else {
+		fmt.Fprintf(ui.out, "  │ $ %s\n", cmd)
+	}
+}
+
+// Finish prints a one-line outcome for every PR started so far.
+func (ui *InteractiveUI) Finish() {
+	if len(ui.progress) == 0 {
+		return
+	}
+
+	failed := 0
+	fmt.Fprintln(ui.out, "\nSummary:")
+	for _, p := range ui.progress {
+		if p.err != nil {
+			failed++
+			fmt.Fprintf(ui.out, "  ❌ %s: %v\n", p.ref, p.err)
+		} else {
+			fmt.Fprintf(ui.out, "  ✅ %s\n", p.ref)
+		}
+	}
+	fmt.Fprintf(ui.out, "%d of %d PRs migrated\n", len(ui.progress)-failed, len(ui.progress))
+}
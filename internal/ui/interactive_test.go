@@ -0,0 +1,213 @@
+package ui
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/user/git-mfpr/internal/git"
+	"github.com/user/git-mfpr/internal/migrate"
+)
+
+func testPRs() []migrate.PRInfo {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	return []migrate.PRInfo{
+		{Number: 101, Title: "Fix memory leak", Author: "alice", CreatedAt: now.Add(-3 * 24 * time.Hour), Labels: []string{"bug"}, CheckStatus: "SUCCESS"},
+		{Number: 102, Title: "Add docs", Author: "bob", CreatedAt: now.Add(-5 * time.Hour), CheckStatus: "FAILURE"},
+		{Number: 103, Title: "Refactor parser", Author: "carol", CreatedAt: now.Add(-20 * time.Minute), Labels: []string{"refactor", "bug"}},
+	}
+}
+
+func newTestInteractive(input string) (*InteractiveUI, *bytes.Buffer) {
+	var out bytes.Buffer
+	ui := NewInteractive(strings.NewReader(input), &out, false)
+	ui.now = func() time.Time { return time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC) }
+	return ui, &out
+}
+
+func numbersOf(prs []migrate.PRInfo) []int {
+	var numbers []int
+	for _, pr := range prs {
+		numbers = append(numbers, pr.Number)
+	}
+	return numbers
+}
+
+func TestInteractiveUI_Pick(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []int
+	}{
+		{name: "toggle and confirm", input: "1 3\n\n", want: []int{101, 103}},
+		{name: "range", input: "1-2\n\n", want: []int{101, 102}},
+		{name: "toggle off", input: "1 2\n2\n\n", want: []int{101}},
+		{name: "filter then select all", input: "/bug\na\n/\n\n", want: []int{101, 103}},
+		{name: "filter indexes are relative", input: "/docs\n1\n\n", want: []int{102}},
+		{name: "select none", input: "a\nn\n\n", want: nil},
+		{name: "invalid selection is ignored", input: "9\nx\n2\n\n", want: []int{102}},
+		{name: "quit", input: "1\nq\n", want: nil},
+		{name: "EOF before confirm", input: "1\n", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ui, _ := newTestInteractive(tt.input)
+			picked, err := ui.Pick(testPRs())
+			if err != nil {
+				t.Fatalf("Pick() error = %v", err)
+			}
+			if got := numbersOf(picked); !equalInts(got, tt.want) {
+				t.Errorf("Pick() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestInteractiveUI_PickRendersList(t *testing.T) {
+	ui, out := newTestInteractive("1\n\n")
+	if _, err := ui.Pick(testPRs()); err != nil {
+		t.Fatalf("Pick() error = %v", err)
+	}
+
+	output := out.String()
+	for _, want := range []string{
+		"Open fork PRs (3):",
+		"[ ]   1. #101   Fix memory leak",
+		"@alice",
+		"3d ✅ [bug]",
+		"5h ❌",
+		"20m –",
+		"[x]   1. #101",
+		"1 selected.",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("picker output missing %q:\n%s", want, output)
+		}
+	}
+}
+
+func TestParseSelection(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    []int
+		wantErr bool
+	}{
+		{input: "1", want: []int{0}},
+		{input: "3 1", want: []int{0, 2}},
+		{input: "1,2", want: []int{0, 1}},
+		{input: "2-4", want: []int{1, 2, 3}},
+		{input: "0", wantErr: true},
+		{input: "6", wantErr: true},
+		{input: "4-2", wantErr: true},
+		{input: "a-b", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseSelection(tt.input, 5)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSelection(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if !equalInts(got, tt.want) {
+				t.Errorf("parseSelection(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	if got := truncate("short", 10); got != "short" {
+		t.Errorf("truncate() = %q", got)
+	}
+	if got := truncate("a much longer title", 8); got != "a much …" {
+		t.Errorf("truncate() = %q", got)
+	}
+}
+
+func TestInteractiveUI_Progress(t *testing.T) {
+	ui, out := newTestInteractive("")
+	ui.SetTotal(2)
+
+	ui.StartPR("101")
+	ui.HandleEvent(migrate.Event{Type: migrate.EventInfo, Message: "Fetching PR information..."})
+	ui.HandleEvent(migrate.Event{Type: migrate.EventInfo, Message: ""})
+	ui.HandleEvent(migrate.Event{Type: migrate.EventCommand, Detail: "git push -u origin migrated-101"})
+	ui.HandleEvent(migrate.Event{Type: migrate.EventError, Message: "Could not download the PR's patches", Detail: "HTTP 404"})
+	ui.HandleEvent(migrate.Event{Type: migrate.EventSuccess, Message: "Successfully migrated PR #101"})
+	ui.StartPR("102")
+	ui.Error(errors.New("PR #102 is closed"))
+	ui.Finish()
+
+	expected := "\n[1/2] 🔄 Migrating PR 101...\n" +
+		"  │ Fetching PR information...\n" +
+		"  │ $ git push -u origin migrated-101\n" +
+		"  │ ❌ Could not download the PR's patches: HTTP 404\n" +
+		"  │ ✅ Successfully migrated PR #101\n" +
+		"\n[2/2] 🔄 Migrating PR 102...\n" +
+		"  │ ❌ Error: PR #102 is closed\n" +
+		"\nSummary:\n" +
+		"  ✅ 101\n" +
+		"  ❌ 102: PR #102 is closed\n" +
+		"1 of 2 PRs migrated\n"
+	if out.String() != expected {
+		t.Errorf("progress output = %q, want %q", out.String(), expected)
+	}
+}
+
+func TestInteractiveUI_FinishWithoutPRs(t *testing.T) {
+	ui, out := newTestInteractive("")
+	ui.Finish()
+	if out.Len() != 0 {
+		t.Errorf("Finish() wrote %q with no PRs started", out.String())
+	}
+}
+
+func TestInteractiveUI_Confirm(t *testing.T) {
+	ui, out := newTestInteractive("y\nn\n")
+	if !ui.Confirm("Push migrated-1 to origin?") {
+		t.Error("Confirm() = false after answering y")
+	}
+	if ui.Confirm("Push migrated-2 to origin?") {
+		t.Error("Confirm() = true after answering n")
+	}
+
+	ui.SetAssumeYes(true)
+	if !ui.Confirm("Push migrated-3 to origin?") {
+		t.Error("Confirm() = false with assume yes")
+	}
+	if !strings.Contains(out.String(), "  │ ❓ Push migrated-3 to origin? yes (--yes)\n") {
+		t.Errorf("output = %q, want the auto-answered prompt", out.String())
+	}
+}
+
+func TestInteractiveUI_VerboseError(t *testing.T) {
+	ui, out := newTestInteractive("")
+	ui.SetVerbose(true)
+
+	ui.Error(&git.ErrCheckoutFailed{Branch: "main", Detail: "boom", Err: &git.ErrCommand{
+		Args:   []string{"checkout", "main"},
+		Output: "error: boom",
+	}})
+
+	expected := "  │ ❌ Error: failed to checkout branch main: boom\n" +
+		"  │    $ git checkout main\n" +
+		"  │    error: boom\n"
+	if out.String() != expected {
+		t.Errorf("Error() output = %q, want %q", out.String(), expected)
+	}
+}
@@ -1,11 +1,17 @@
 package ui
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strings"
+	"time"
 
+	"github.com/user/git-mfpr/internal/git"
 	"github.com/user/git-mfpr/internal/migrate"
+	"github.com/user/git-mfpr/internal/runner"
 )
 
 type UI interface {
@@ -15,18 +21,63 @@ type UI interface {
 	Success(message string)
 	Info(message string)
 	Command(cmd string)
+	Confirm(prompt string) bool
 }
 
 type ConsoleUI struct {
-	dryRun bool
+	dryRun      bool
+	assumeYes   bool
+	interactive bool
+	verbose     bool
+	in          *bufio.Reader
+}
+
+type Option func(*ConsoleUI)
+
+// WithAssumeYes answers every confirmation with yes without asking.
+func WithAssumeYes(yes bool) Option {
+	return func(ui *ConsoleUI) {
+		ui.assumeYes = yes
+	}
+}
+
+// WithVerbose shows the full output of failed git commands alongside errors.
+func WithVerbose(verbose bool) Option {
+	return func(ui *ConsoleUI) {
+		ui.verbose = verbose
+	}
+}
+
+// WithInput reads confirmation answers from in. When interactive is false,
+// confirmations are declined without reading.
+func WithInput(in io.Reader, interactive bool) Option {
+	return func(ui *ConsoleUI) {
+		ui.in = bufio.NewReader(in)
+		ui.interactive = interactive
+	}
 }
 
 func New() UI {
-	return &ConsoleUI{}
+	return NewWithOptions(false)
 }
 
-func NewWithOptions(dryRun bool) UI {
-	return &ConsoleUI{dryRun: dryRun}
+func NewWithOptions(dryRun bool, opts ...Option) UI {
+	ui := &ConsoleUI{
+		dryRun:      dryRun,
+		interactive: IsTerminal(os.Stdin),
+		in:          bufio.NewReader(os.Stdin),
+	}
+
+	for _, opt := range opts {
+		opt(ui)
+	}
+
+	return ui
+}
+
+func IsTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
 }
 
 func (ui *ConsoleUI) StartPR(prRef string) {
@@ -40,16 +91,77 @@ func (ui *ConsoleUI) HandleEvent(event migrate.Event) {
 	case migrate.EventSuccess:
 		ui.Success(event.Message)
 	case migrate.EventError:
-		ui.Error(fmt.Errorf("%s", event.Message))
+		ui.Error(errors.New(errorMessage(event)))
 	case migrate.EventCommand:
 		ui.Command(event.Detail)
+	case migrate.EventExec:
+		fmt.Print(FormatExec(event.Exec, ""))
 	default:
 		// handle unknown event types if needed
 	}
 }
 
+// errorMessage is an error event's message followed by its detail, such as
+// the error that caused it, when there is one.
+func errorMessage(event migrate.Event) string {
+	if event.Detail == "" {
+		return event.Message
+	}
+	return event.Message + ": " + event.Detail
+}
+
 func (ui *ConsoleUI) Error(err error) {
 	fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
+	if ui.verbose {
+		if output := FormatCommandOutput(err, "   "); output != "" {
+			fmt.Fprint(os.Stderr, output)
+		}
+	}
+}
+
+// FormatExec describes a command that ran: its command line, exit code,
+// duration and directory (API requests have none), followed by anything it
+// printed. Every line is
+// prefixed with indent.
+func FormatExec(result *runner.Result, indent string) string {
+	if result == nil {
+		return ""
+	}
+
+	var b strings.Builder
+	where := ""
+	if result.Dir != "" {
+		where = ", in " + result.Dir
+	}
+	fmt.Fprintf(&b, "%s⚙️  %s  [exit %d, %s%s]\n",
+		indent, result.Cmd.String(), result.ExitCode, result.Duration.Round(time.Millisecond), where)
+	for _, output := range [][]byte{result.Stdout, result.Stderr} {
+		for _, line := range strings.Split(strings.TrimRight(string(output), "\n"), "\n") {
+			if line != "" {
+				fmt.Fprintf(&b, "%s   │ %s\n", indent, line)
+			}
+		}
+	}
+	return b.String()
+}
+
+// FormatCommandOutput returns the command line and everything printed by the
+// failed git command behind err, each line prefixed with indent, or "" if err
+// did not come from git.
+func FormatCommandOutput(err error, indent string) string {
+	var cmdErr *git.ErrCommand
+	if !errors.As(err, &cmdErr) {
+		return ""
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s$ git %s\n", indent, strings.Join(cmdErr.Args, " "))
+	for _, line := range strings.Split(cmdErr.Output, "\n") {
+		if line != "" {
+			fmt.Fprintf(&b, "%s%s\n", indent, line)
+		}
+	}
+	return b.String()
 }
 
 func (ui *ConsoleUI) Success(message string) {
@@ -68,6 +180,33 @@ func (ui *ConsoleUI) Command(cmd string) {
 	}
 }
 
+func (ui *ConsoleUI) Confirm(prompt string) bool {
+	if ui.assumeYes {
+		fmt.Printf("❓ %s yes (--yes)\n", prompt)
+		return true
+	}
+	if !ui.interactive {
+		fmt.Printf("❓ %s no (stdin is not a terminal; pass --yes to proceed)\n", prompt)
+		return false
+	}
+
+	fmt.Printf("❓ %s [y/N] ", prompt)
+	return readYes(ui.in)
+}
+
+func readYes(in *bufio.Reader) bool {
+	answer, err := in.ReadString('\n')
+	if err != nil && answer == "" {
+		return false
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	default:
+		return false
+	}
+}
+
 func FormatPRInfo(pr *migrate.PRInfo) string {
 	var lines []string
 	lines = append(lines, fmt.Sprintf("📋 Title: %s", pr.Title))
@@ -85,3 +224,50 @@ func FormatCreatePRCommand(pr *migrate.PRInfo, _ string) string {
   --body "Migrated from #%d\nOriginal author: @%s" \
   --base %s`, pr.Title, pr.Number, pr.Author, pr.BaseBranch)
 }
+
+func FormatProvenance(p *migrate.Provenance) string {
+	head := p.HeadSHA
+	if len(head) > 7 {
+		head = head[:7]
+	}
+	return fmt.Sprintf("#%d %s/%s → %s (author: @%s, head: %s, migrated: %s)",
+		p.Number, p.Owner, p.Repo, p.Branch, p.Author, head, p.MigratedAt.Format("2006-01-02 15:04 MST"))
+}
+
+func FormatMigrationStatus(s *migrate.MigrationStatus) string {
+	var lines []string
+	lines = append(lines, fmt.Sprintf("#%d %s/%s → %s", s.Number, s.Owner, s.Repo, s.Branch))
+
+	if s.Original != nil {
+		original := fmt.Sprintf("   original: %s", strings.ToLower(s.Original.State))
+		if s.ForkUpdated {
+			original += ", fork has new commits since migration"
+		}
+		lines = append(lines, original)
+	}
+
+	branch := "   branch: "
+	switch {
+	case s.LocalBranch && s.Pushed:
+		branch += "local, pushed"
+	case s.LocalBranch:
+		branch += "local only, not pushed"
+	case s.Pushed:
+		branch += "pushed, no local branch"
+	default:
+		branch += "deleted"
+	}
+	lines = append(lines, branch)
+
+	if s.Replacement != nil {
+		lines = append(lines, fmt.Sprintf("   replacement: #%d (%s) %s",
+			s.Replacement.Number, strings.ToLower(s.Replacement.State), s.Replacement.URL))
+	} else if s.Error == nil {
+		lines = append(lines, "   replacement: none")
+	}
+
+	if s.Error != nil {
+		lines = append(lines, fmt.Sprintf("   error: %v", s.Error))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -7,8 +7,11 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/user/git-mfpr/internal/git"
 	"github.com/user/git-mfpr/internal/migrate"
+	"github.com/user/git-mfpr/internal/runner"
 )
 
 func TestNew(t *testing.T) {
@@ -241,6 +244,16 @@ func TestConsoleUI_HandleEvent(t *testing.T) {
 			expectedErr:  "❌ Error: Failed to fetch PR\n",
 			expectStdout: false,
 		},
+		{
+			name: "error event with detail",
+			event: migrate.Event{
+				Type:    migrate.EventError,
+				Message: "Failed to clean up migrated-1",
+				Detail:  "failed to delete branch migrated-1",
+			},
+			expectedErr:  "❌ Error: Failed to clean up migrated-1: failed to delete branch migrated-1\n",
+			expectStdout: false,
+		},
 		{
 			name: "command event",
 			event: migrate.Event{
@@ -432,3 +445,177 @@ func ExampleFormatPRInfo() {
 	// 🔢 Number: #100
 	// 🌿 Base Branch: main
 }
+
+func TestFormatProvenance(t *testing.T) {
+	record := &migrate.Provenance{
+		Owner:      "owner",
+		Repo:       "repo",
+		Number:     123,
+		Branch:     "migrated-123",
+		HeadSHA:    "abc123def4567890",
+		Author:     "johndoe",
+		MigratedAt: time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC),
+	}
+
+	expected := "#123 owner/repo → migrated-123 (author: @johndoe, head: abc123d, migrated: 2024-05-01 12:30 UTC)"
+	if got := FormatProvenance(record); got != expected {
+		t.Errorf("FormatProvenance() = %q, want %q", got, expected)
+	}
+}
+
+func TestFormatMigrationStatus(t *testing.T) {
+	base := migrate.Provenance{Owner: "owner", Repo: "repo", Number: 123, Branch: "migrated-123"}
+
+	tests := []struct {
+		name     string
+		status   migrate.MigrationStatus
+		expected string
+	}{
+		{
+			name: "open original with replacement",
+			status: migrate.MigrationStatus{
+				Provenance:  base,
+				Original:    &migrate.PRInfo{State: "OPEN"},
+				Replacement: &migrate.PRInfo{Number: 130, State: "OPEN", URL: "https://github.com/owner/repo/pull/130"},
+				LocalBranch: true,
+				Pushed:      true,
+				ForkUpdated: true,
+			},
+			expected: "#123 owner/repo → migrated-123\n" +
+				"   original: open, fork has new commits since migration\n" +
+				"   branch: local, pushed\n" +
+				"   replacement: #130 (open) https://github.com/owner/repo/pull/130",
+		},
+		{
+			name: "local only without replacement",
+			status: migrate.MigrationStatus{
+				Provenance:  base,
+				Original:    &migrate.PRInfo{State: "CLOSED"},
+				LocalBranch: true,
+			},
+			expected: "#123 owner/repo → migrated-123\n" +
+				"   original: closed\n" +
+				"   branch: local only, not pushed\n" +
+				"   replacement: none",
+		},
+		{
+			name: "lookup error",
+			status: migrate.MigrationStatus{
+				Provenance: base,
+				Error:      errors.New("gh failed"),
+			},
+			expected: "#123 owner/repo → migrated-123\n" +
+				"   branch: deleted\n" +
+				"   error: gh failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatMigrationStatus(&tt.status); got != tt.expected {
+				t.Errorf("FormatMigrationStatus() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestConsoleUI_Confirm(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		interactive bool
+		assumeYes   bool
+		want        bool
+		wantOutput  string
+	}{
+		{"yes", "y\n", true, false, true, "❓ Push? [y/N] "},
+		{"full yes", "Yes\n", true, false, true, "❓ Push? [y/N] "},
+		{"no", "n\n", true, false, false, "❓ Push? [y/N] "},
+		{"default", "\n", true, false, false, "❓ Push? [y/N] "},
+		{"eof", "", true, false, false, "❓ Push? [y/N] "},
+		{"assume yes", "", false, true, true, "❓ Push? yes (--yes)\n"},
+		{"not a terminal", "y\n", false, false, false, "❓ Push? no (stdin is not a terminal; pass --yes to proceed)\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ui := NewWithOptions(false, WithInput(strings.NewReader(tt.input), tt.interactive), WithAssumeYes(tt.assumeYes))
+
+			oldStdout := os.Stdout
+			r, w, _ := os.Pipe()
+			os.Stdout = w
+
+			got := ui.Confirm("Push?")
+
+			if err := w.Close(); err != nil {
+				t.Fatalf("Failed to close pipe: %v", err)
+			}
+			os.Stdout = oldStdout
+
+			var buf bytes.Buffer
+			if _, err := buf.ReadFrom(r); err != nil {
+				t.Fatalf("Failed to read from pipe: %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("Confirm() = %v, want %v", got, tt.want)
+			}
+			if buf.String() != tt.wantOutput {
+				t.Errorf("Confirm() output = %q, want %q", buf.String(), tt.wantOutput)
+			}
+		})
+	}
+}
+
+func TestFormatCommandOutput(t *testing.T) {
+	cmdErr := &git.ErrCommand{
+		Args:    []string{"push", "-u", "origin", "migrated-1"},
+		Summary: "rejected",
+		Output:  " ! [rejected] migrated-1 -> migrated-1 (fetch first)\n\nerror: failed to push some refs",
+	}
+	err := fmt.Errorf("PR 1: %w", &git.ErrPushFailed{Remote: "origin", Branch: "migrated-1", Detail: "rejected", Err: cmdErr})
+
+	expected := "   $ git push -u origin migrated-1\n" +
+		"    ! [rejected] migrated-1 -> migrated-1 (fetch first)\n" +
+		"   error: failed to push some refs\n"
+	if got := FormatCommandOutput(err, "   "); got != expected {
+		t.Errorf("FormatCommandOutput() = %q, want %q", got, expected)
+	}
+
+	if got := FormatCommandOutput(errors.New("plain"), "   "); got != "" {
+		t.Errorf("FormatCommandOutput() = %q for a non-git error, want empty", got)
+	}
+}
+
+func TestFormatExec(t *testing.T) {
+	result := &runner.Result{
+		Cmd:      runner.Cmd{Name: "git", Args: []string{"pull", "origin", "main"}},
+		Dir:      "/repo",
+		Stdout:   []byte("Already up to date.\n"),
+		Stderr:   []byte("From github.com:owner/repo\n"),
+		Duration: 1234567 * time.Microsecond,
+	}
+
+	expected := "⚙️  git pull origin main  [exit 0, 1.235s, in /repo]\n" +
+		"   │ Already up to date.\n" +
+		"   │ From github.com:owner/repo\n"
+	if got := FormatExec(result, ""); got != expected {
+		t.Errorf("FormatExec() = %q, want %q", got, expected)
+	}
+
+	result.Stdout, result.Stderr = nil, nil
+	expected = "  │ ⚙️  git pull origin main  [exit 0, 1.235s, in /repo]\n"
+	if got := FormatExec(result, "  │ "); got != expected {
+		t.Errorf("FormatExec() without output = %q, want %q", got, expected)
+	}
+
+	request := &runner.Result{Cmd: runner.Cmd{Name: "GET", Args: []string{"https://gitlab.com/api/v4/x"}}, ExitCode: 404}
+	expected = "⚙️  GET https://gitlab.com/api/v4/x  [exit 404, 0s]\n"
+	if got := FormatExec(request, ""); got != expected {
+		t.Errorf("FormatExec() of an API request = %q, want %q", got, expected)
+	}
+
+	if got := FormatExec(nil, ""); got != "" {
+		t.Errorf("FormatExec(nil) = %q, want empty", got)
+	}
+}
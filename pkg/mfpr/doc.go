@@ -0,0 +1,43 @@
+// Package mfpr is the Go API of git-mfpr, for programs that migrate fork PRs
+// to branches themselves, such as bots.
+//
+// A Migrator runs git and gh (or the GitLab or Gitea API) in a local clone,
+// exactly as the git-mfpr command does:
+//
+//	m := mfpr.New(mfpr.WithDir("/srv/clones/app"))
+//	m.SetEventHandler(func(e mfpr.Event) { log.Println(e.Message) })
+//	batch, err := m.MigratePRs(ctx, []string{"123", "124"}, mfpr.Options{})
+//
+// Without a handler set by SetConfirmHandler every step proceeds, except that
+// the replacement PR is not created: the command to create it is reported as
+// an EventCommand instead. Pass a handler that returns true to create it.
+//
+// WithGit and WithForge (or WithGitHub) replace the git and forge clients
+// with your own implementations, for example to run against a test double.
+//
+// Errors can be matched by category with errors.Is (ErrNotFound, ErrGit,
+// ...) and by type with errors.As (ErrBranchExists, ...).
+//
+// # Compatibility
+//
+// Package mfpr follows semantic versioning of the git-mfpr module's vX.Y.Z
+// tags. Within a major version:
+//
+//   - No exported name is removed or renamed, no function or method signature
+//     changes, and no struct field is removed or changes type.
+//   - Errors keep their categories and types, so code using errors.Is and
+//     errors.As keeps working. Event types, Status values and option defaults
+//     keep their meaning.
+//   - Minor releases may add functions, options, struct fields, constants,
+//     error types and event types, and may add methods to the Git, Forge,
+//     GitHub and Migrator interfaces. Implementations of Git, Forge and GitHub
+//     should embed the interface they implement so that they keep compiling.
+//   - Patch releases only fix bugs.
+//
+// Anything else is a breaking change and comes with a new major version and
+// module path. Error and event messages are for people and may change in any
+// release; match errors with errors.Is and errors.As instead. Many types here
+// are aliases of internal ones, which are kept compatible on this package's
+// behalf. The git-mfpr command and the packages under internal/ are not
+// covered otherwise.
+package mfpr
@@ -0,0 +1,84 @@
+package mfpr_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/user/git-mfpr/pkg/mfpr"
+)
+
+// repo is a clone of example/app with a main branch. Embedding mfpr.Git
+// keeps it compiling as the interface grows; only the methods a dry run
+// needs are implemented.
+type repo struct {
+	mfpr.Git
+}
+
+func (repo) CurrentRepo(context.Context) (string, string, error) { return "example", "app", nil }
+func (repo) CurrentBranch(context.Context) (string, error)       { return "main", nil }
+func (repo) HasBranch(_ context.Context, name string) bool       { return name == "main" }
+
+// pullRequests serves PR #42 from a fork.
+type pullRequests struct {
+	mfpr.GitHub
+}
+
+func (pullRequests) GetPR(_ context.Context, _, _ string, number int) (*mfpr.PRInfo, error) {
+	if number != 42 {
+		return nil, &mfpr.ErrPRNotFound{Number: number, Owner: "example", Repo: "app"}
+	}
+	return &mfpr.PRInfo{
+		Number:     42,
+		Title:      "Fix the frobnicator",
+		Author:     "octocat",
+		State:      "OPEN",
+		BaseBranch: "main",
+		IsFork:     true,
+		URL:        "https://github.com/example/app/pull/42",
+	}, nil
+}
+
+func Example() {
+	m := mfpr.New(mfpr.WithGit(repo{}), mfpr.WithGitHub(pullRequests{}))
+	m.SetEventHandler(func(e mfpr.Event) {
+		if e.Type == mfpr.EventResult {
+			fmt.Printf("PR %s: %s\n", e.Result.Ref, e.Result.Status())
+		}
+	})
+
+	batch, err := m.MigratePRs(context.Background(), []string{"42", "43"}, mfpr.Options{DryRun: true})
+	fmt.Println(len(batch.Failed()), "failed:", err)
+
+	var notFound *mfpr.ErrPRNotFound
+	if errors.As(err, &notFound) && errors.Is(err, mfpr.ErrNotFound) {
+		fmt.Println("missing PR:", notFound.Number)
+	}
+	// Output:
+	// PR 42: dry run
+	// PR 43: failed
+	// 1 failed: PR 43: PR #43 not found in example/app
+	// missing PR: 43
+}
+
+func ExampleNew() {
+	m := mfpr.New(mfpr.WithDir("/srv/clones/app"))
+	m.SetConfirmHandler(func(prompt string) bool {
+		// Push and create the replacement PR, but leave the original open.
+		return !strings.HasPrefix(prompt, "Close")
+	})
+
+	refs, err := mfpr.ExpandPRRefs([]string{"100-110"})
+	if err != nil {
+		log.Fatal(err)
+	}
+	batch, err := m.MigratePRs(context.Background(), refs, mfpr.Options{CloseOriginal: true})
+	if err != nil {
+		log.Print(err)
+	}
+	for _, r := range batch.Results {
+		fmt.Println(r.URL(), r.Status())
+	}
+}
@@ -0,0 +1,225 @@
+package mfpr
+
+import (
+	"time"
+
+	"github.com/user/git-mfpr/internal/forge"
+	"github.com/user/git-mfpr/internal/git"
+	"github.com/user/git-mfpr/internal/github"
+	"github.com/user/git-mfpr/internal/migrate"
+	"github.com/user/git-mfpr/internal/runner"
+)
+
+type (
+	// Migrator migrates fork PRs to branches. See New.
+	Migrator = migrate.Migrator
+	// Options controls a migration, like the flags of git-mfpr.
+	Options        = migrate.Options
+	CleanupOptions = migrate.CleanupOptions
+	PathRename     = migrate.PathRename
+	Hooks          = migrate.Hooks
+	Stage          = migrate.Stage
+	Strategy       = migrate.Strategy
+	TraceLevel     = migrate.TraceLevel
+
+	PRInfo          = migrate.PRInfo
+	MigrationResult = migrate.MigrationResult
+	BatchResult     = migrate.BatchResult
+	Status          = migrate.Status
+	Provenance      = migrate.Provenance
+	MigrationStatus = migrate.MigrationStatus
+
+	Event          = migrate.Event
+	EventType      = migrate.EventType
+	EventHandler   = migrate.EventHandler
+	ConfirmHandler = migrate.ConfirmHandler
+
+	// Option configures New.
+	Option = migrate.Option
+)
+
+// Event types.
+const (
+	EventInfo    = migrate.EventInfo
+	EventSuccess = migrate.EventSuccess
+	EventError   = migrate.EventError
+	EventCommand = migrate.EventCommand
+	EventExec    = migrate.EventExec
+	EventStart   = migrate.EventStart
+	EventResult  = migrate.EventResult
+)
+
+// Hook stages, in the order a migration reaches them.
+const (
+	StagePreCheckout  = migrate.StagePreCheckout
+	StagePostCheckout = migrate.StagePostCheckout
+	StagePrePush      = migrate.StagePrePush
+	StagePostCreate   = migrate.StagePostCreate
+)
+
+// Migration outcomes reported by MigrationResult.Status.
+const (
+	StatusMigrated  = migrate.StatusMigrated
+	StatusLocal     = migrate.StatusLocal
+	StatusConflicts = migrate.StatusConflicts
+	StatusDryRun    = migrate.StatusDryRun
+	StatusFailed    = migrate.StatusFailed
+)
+
+const (
+	TraceOff      = migrate.TraceOff
+	TraceCommands = migrate.TraceCommands
+	TraceOutput   = migrate.TraceOutput
+)
+
+const (
+	DefaultTimeout       = migrate.DefaultTimeout
+	DefaultChecksTimeout = migrate.DefaultChecksTimeout
+)
+
+// Error categories, for errors.Is.
+var (
+	ErrUsage        = migrate.ErrUsage
+	ErrNotFound     = migrate.ErrNotFound
+	ErrPrecondition = migrate.ErrPrecondition
+	ErrGit          = migrate.ErrGit
+	ErrGitHub       = migrate.ErrGitHub
+)
+
+// Errors a migration can fail with, for errors.As.
+type (
+	ErrPRNotFound        = migrate.ErrPRNotFound
+	ErrPRNotFork         = migrate.ErrPRNotFork
+	ErrPRClosed          = migrate.ErrPRClosed
+	ErrBranchExists      = migrate.ErrBranchExists
+	ErrInvalidPRRef      = migrate.ErrInvalidPRRef
+	ErrInvalidPRRange    = migrate.ErrInvalidPRRange
+	ErrInvalidPathMap    = migrate.ErrInvalidPathMap
+	ErrInvalidProvenance = migrate.ErrInvalidProvenance
+	ErrHunksRejected     = migrate.ErrHunksRejected
+	ErrHookFailed        = migrate.ErrHookFailed
+	ErrChecksFailed      = migrate.ErrChecksFailed
+	ErrChecksTimedOut    = migrate.ErrChecksTimedOut
+)
+
+// Errors from git. ErrCommand carries the command line and output of a
+// failed git command; the others may wrap it.
+type (
+	ErrNotInRepo                = git.ErrNotInRepo
+	ErrBranchNotFound           = git.ErrBranchNotFound
+	ErrCheckoutFailed           = git.ErrCheckoutFailed
+	ErrPullFailed               = git.ErrPullFailed
+	ErrPushFailed               = git.ErrPushFailed
+	ErrDeleteBranchFailed       = git.ErrDeleteBranchFailed
+	ErrDeleteRemoteBranchFailed = git.ErrDeleteRemoteBranchFailed
+	ErrWriteRefFailed           = git.ErrWriteRefFailed
+	ErrReadRefFailed            = git.ErrReadRefFailed
+	ErrPatchFailed              = git.ErrPatchFailed
+	ErrCommand                  = git.ErrCommand
+	ErrNonFastForward           = git.ErrNonFastForward
+	ErrAuthDenied               = git.ErrAuthDenied
+	ErrProtectedBranch          = git.ErrProtectedBranch
+	ErrLocalChanges             = git.ErrLocalChanges
+	ErrUnknownRevision          = git.ErrUnknownRevision
+)
+
+// Errors from the forge: gh for GitHub, or the GitLab or Gitea API.
+type (
+	ErrPRCheckoutFailed = forge.ErrPRCheckoutFailed
+	ErrPRPatchFailed    = forge.ErrPRPatchFailed
+	ErrRequestFailed    = forge.ErrRequestFailed
+	ErrInvalidURL       = forge.ErrInvalidURL
+	ErrUnknownForge     = forge.ErrUnknownForge
+	ErrGHNotInstalled   = github.ErrGHNotInstalled
+	ErrPRFetchFailed    = github.ErrPRFetchFailed
+	ErrPRParseFailed    = github.ErrPRParseFailed
+	ErrPRCreateFailed   = github.ErrPRCreateFailed
+	ErrPRCloseFailed    = github.ErrPRCloseFailed
+	ErrPRCommentFailed  = github.ErrPRCommentFailed
+	ErrPRListFailed     = github.ErrPRListFailed
+	ErrAPIRequestFailed = github.ErrAPIRequestFailed
+)
+
+// Git is the set of git operations a Migrator uses. See WithGit.
+type (
+	Git             = git.Git
+	Reject          = git.Reject
+	BranchResult    = git.BranchResult
+	RepoResult      = git.RepoResult
+	OperationResult = git.OperationResult
+)
+
+// Forge is the set of pull or merge request operations a Migrator uses, on
+// GitHub, GitLab or Gitea. See WithForge.
+type Forge = forge.Forge
+
+// Describer is implemented by a Forge that can describe its calls, as a
+// command or an API request, for dry runs. Other forges are described as
+// GitHub.
+type Describer = forge.Describer
+
+// GitHub is Forge plus a check that gh is installed. See WithGitHub.
+type (
+	GitHub          = github.GitHub
+	CreatePROptions = github.CreatePROptions
+	ListOptions     = github.ListOptions
+	Checks          = github.Checks
+	Check           = github.Check
+)
+
+// Runner runs the git and gh commands of the default clients. See
+// WithRunner.
+type (
+	Runner        = runner.Runner
+	Cmd           = runner.Cmd
+	CommandResult = runner.Result
+)
+
+// New returns a Migrator for the repository in the working directory, or the
+// one given with WithDir.
+func New(opts ...Option) Migrator {
+	return migrate.NewWithOptions(opts...)
+}
+
+// WithDir runs in the repository at dir instead of the working directory.
+func WithDir(dir string) Option {
+	return migrate.WithDir(dir)
+}
+
+// WithTimeout bounds each git and gh command (default DefaultTimeout). Zero
+// disables it.
+func WithTimeout(timeout time.Duration) Option {
+	return migrate.WithTimeout(timeout)
+}
+
+// WithTrace reports the git and gh commands run, and GitLab and Gitea API
+// requests, as EventExec events.
+func WithTrace(level TraceLevel) Option {
+	return migrate.WithTrace(level)
+}
+
+// WithRunner runs the git and gh commands of the default clients through r.
+func WithRunner(r Runner) Option {
+	return migrate.WithRunner(r)
+}
+
+// WithGit uses g instead of running git.
+func WithGit(g Git) Option {
+	return migrate.WithGit(g)
+}
+
+// WithGitHub uses g instead of running gh.
+func WithGitHub(g GitHub) Option {
+	return migrate.WithGitHub(g)
+}
+
+// WithForge uses f instead of the forge detected from origin.
+func WithForge(f Forge) Option {
+	return migrate.WithForge(f)
+}
+
+// ExpandPRRefs expands ranges such as 100-110 and comma-separated lists in
+// refs, dropping duplicates.
+func ExpandPRRefs(refs []string) ([]string, error) {
+	return migrate.ExpandPRRefs(refs)
+}